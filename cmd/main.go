@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/rutishh0/testingquant/internal/adapters/coinbase"
 	"github.com/rutishh0/testingquant/internal/adapters/mesh"
@@ -14,8 +20,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/rutishh0/mesh-server/services"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish
+// on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Load environment variables from .env if present (development use)
 	if err := godotenv.Load(); err != nil {
@@ -58,19 +69,31 @@ func main() {
     // we will host the Mesh API in-process at /mesh and call it over the
     // internal loopback.
     meshBaseURL := cfg.MeshAPIURL
+    inProcess := false
     if meshBaseURL == "" || meshBaseURL == "http://localhost:8080/mesh" || meshBaseURL == "http://127.0.0.1:8080/mesh" {
         // Defer exact port to runtime; router will listen on cfg.ServerAddress.
         // Build a base URL that targets the same process.
         meshBaseURL = "http://127.0.0.1" + cfg.ServerAddress + "/mesh"
+        inProcess = true
     }
 
-    var meshAPI clients.MeshAPI
+    // The in-process construction, mempool and call endpoints require the
+    // API key; it is never sent to an external Mesh server.
+    var meshAPI clients.MeshAPIV2
     if cfg.MeshUseSDK {
         log.Printf("Mesh client: SDK mode enabled (MESH_USE_SDK=true), baseURL=%s", meshBaseURL)
-        meshAPI = clients.NewMeshSDKClient(meshBaseURL)
+        sdkClient := clients.NewMeshSDKClient(meshBaseURL)
+        if inProcess && cfg.APIKey != "" {
+            sdkClient.SetAPIKey(cfg.APIKey)
+        }
+        meshAPI = sdkClient
     } else {
         log.Printf("Mesh client: HTTP mode (default), baseURL=%s", meshBaseURL)
-        meshAPI = clients.NewMeshClient(meshBaseURL)
+        httpClient := clients.NewMeshClient(meshBaseURL)
+        if inProcess {
+            httpClient.APIKey = cfg.APIKey
+        }
+        meshAPI = httpClient
     }
 
     // Initialize adapters
@@ -90,8 +113,26 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Mesh networks and their RPC endpoints come from MESH_NETWORKS_FILE or
+	// the built-in defaults. They are loaded once and served at /mesh.
+	meshNetworks, err := services.LoadNetworksFromEnv()
+	if err != nil {
+		log.Printf("Failed to initialize Mesh Rosetta API: %v", err)
+		meshNetworks = nil
+	}
+	// The pollers and indexers run until SIGINT or SIGTERM, which also
+	// starts the shutdown below
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if meshNetworks != nil {
+		// Head pollers feed /mesh/events/blocks for as long as the server runs
+		meshNetworks.StartEventPollers(ctx)
+		// Indexers follow their chains for /mesh/search/transactions
+		meshNetworks.StartIndexers(ctx)
+	}
+
 	// Setup router
-	router := api.SetupRouter(connectorService, cfg)
+	router := api.SetupRouter(connectorService, cfg, meshNetworks)
 
 	// Start server
 	log.Printf("🚀 Starting Quant Connector Service on %s", cfg.ServerAddress)
@@ -109,7 +150,33 @@ func main() {
 		log.Printf("❌ Overledger integration: DISABLED (missing credentials)")
 	}
 	
-	if err := router.Run(cfg.ServerAddress); err != nil {
+	srv := &http.Server{Addr: cfg.ServerAddress, Handler: router}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		err = nil
+	}
+
+	// Shut down explicitly, as deferred calls would not run after log.Fatal;
+	// in-flight requests finish first, then the pollers and indexers stop
+	// and the indexes close
+	log.Printf("Shutting down Quant Connector Service...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+		log.Printf("⚠️  Server shutdown: %v", shutdownErr)
+	}
+	stop()
+	if meshNetworks != nil {
+		if closeErr := meshNetworks.Close(); closeErr != nil {
+			log.Printf("⚠️  Closing Mesh indexes: %v", closeErr)
+		}
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal("❌ Failed to start server:", err)
 	}
 }
\ No newline at end of file
@@ -1,50 +1,60 @@
 package mesh
 
 import (
-	"encoding/json"
+	"context"
+
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/rutishh0/testingquant/internal/clients"
 	"github.com/rutishh0/testingquant/internal/models"
 )
 
-// Adapter defines the interface for mesh client operations
+// Adapter defines the interface for mesh client operations. Results are the Rosetta response
+// types, except ListNetworks, which adds display currency metadata.
 type Adapter interface {
-	ListNetworks() (*models.MeshNetworksResponse, error)
-	AccountBalance(networkIdentifier, accountIdentifier interface{}) (*models.MeshBalanceResponse, error)
-	// New: block and transaction retrieval
-	Block(networkIdentifier, blockIdentifier interface{}) (map[string]interface{}, error)
-	BlockTransaction(networkIdentifier, blockIdentifier, transactionIdentifier interface{}) (map[string]interface{}, error)
-	Health() bool
+	ListNetworks(ctx context.Context) (*models.MeshNetworksResponse, error)
+	NetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error)
+	NetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error)
+	AccountBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error)
+	AccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error)
+	// Block and transaction retrieval
+	Block(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error)
+	BlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error)
+	// Call runs a read-only network call
+	Call(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error)
+	// SearchTransactions searches a network's transactions with the Rosetta filters
+	SearchTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error)
+	// Mempool and the construction flow
+	Mempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error)
+	MempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error)
+	ConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error)
+	ConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error)
+	ConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error)
+	ConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error)
+	ConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error)
+	ConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error)
+	ConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error)
+	ConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error)
+	Health(ctx context.Context) bool
 }
 
 type meshAdapter struct {
-    client clients.MeshAPI
+    client clients.MeshAPIV2
 }
 
 // NewAdapter creates a new mesh adapter
-func NewAdapter(client clients.MeshAPI) Adapter {
+func NewAdapter(client clients.MeshAPIV2) Adapter {
     return &meshAdapter{client: client}
 }
 
-func (a *meshAdapter) ListNetworks() (*models.MeshNetworksResponse, error) {
-	resp, err := a.client.ListNetworks()
+func (a *meshAdapter) ListNetworks(ctx context.Context) (*models.MeshNetworksResponse, error) {
+	resp, err := a.client.NetworkList(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	// Decode Rosetta NetworkListResponse and map to internal model with currency metadata
-	var rosettaResp struct {
-		NetworkIdentifiers []struct {
-			Blockchain string `json:"blockchain"`
-			Network    string `json:"network"`
-		} `json:"network_identifiers"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&rosettaResp); err != nil {
-		return nil, err
-	}
 
-	networks := make([]models.MeshNetwork, 0, len(rosettaResp.NetworkIdentifiers))
-	for _, ni := range rosettaResp.NetworkIdentifiers {
+	// Map the Rosetta NetworkListResponse to the internal model with currency metadata
+	networks := make([]models.MeshNetwork, 0, len(resp.NetworkIdentifiers))
+	for _, ni := range resp.NetworkIdentifiers {
 		var mn models.MeshNetwork
 		mn.NetworkIdentifier.Blockchain = ni.Blockchain
 		mn.NetworkIdentifier.Network = ni.Network
@@ -57,50 +67,78 @@ func (a *meshAdapter) ListNetworks() (*models.MeshNetworksResponse, error) {
 	return &models.MeshNetworksResponse{Networks: networks}, nil
 }
 
-func (a *meshAdapter) AccountBalance(networkIdentifier, accountIdentifier interface{}) (*models.MeshBalanceResponse, error) {
-	resp, err := a.client.AccountBalance(networkIdentifier, accountIdentifier)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+func (a *meshAdapter) NetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error) {
+	return a.client.NetworkStatus(ctx, request)
+}
 
-	var balance models.MeshBalanceResponse
-	if err := json.NewDecoder(resp.Body).Decode(&balance); err != nil {
-		return nil, err
-	}
-	return &balance, nil
+func (a *meshAdapter) NetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error) {
+	return a.client.NetworkOptions(ctx, request)
 }
 
-// Block returns the Rosetta BlockResponse as a generic map to keep adapter layer decoupled from SDK types
-func (a *meshAdapter) Block(networkIdentifier, blockIdentifier interface{}) (map[string]interface{}, error) {
-	resp, err := a.client.Block(networkIdentifier, blockIdentifier)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+func (a *meshAdapter) AccountBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error) {
+	return a.client.AccountBalance(ctx, request)
+}
 
-	var out map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, err
-	}
-	return out, nil
+func (a *meshAdapter) AccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error) {
+	return a.client.AccountCoins(ctx, request)
 }
 
-// BlockTransaction returns the Rosetta BlockTransactionResponse as a generic map
-func (a *meshAdapter) BlockTransaction(networkIdentifier, blockIdentifier, transactionIdentifier interface{}) (map[string]interface{}, error) {
-	resp, err := a.client.BlockTransaction(networkIdentifier, blockIdentifier, transactionIdentifier)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+func (a *meshAdapter) Block(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error) {
+	return a.client.Block(ctx, request)
+}
 
-	var out map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, err
-	}
-	return out, nil
+func (a *meshAdapter) BlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error) {
+	return a.client.BlockTransaction(ctx, request)
+}
+
+func (a *meshAdapter) Call(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error) {
+	return a.client.Call(ctx, request)
 }
 
-func (a *meshAdapter) Health() bool {
-	return a.client.Health()
-}
\ No newline at end of file
+func (a *meshAdapter) SearchTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error) {
+	return a.client.SearchTransactions(ctx, request)
+}
+
+func (a *meshAdapter) Mempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error) {
+	return a.client.Mempool(ctx, request)
+}
+
+func (a *meshAdapter) MempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error) {
+	return a.client.MempoolTransaction(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error) {
+	return a.client.ConstructionDerive(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error) {
+	return a.client.ConstructionPreprocess(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error) {
+	return a.client.ConstructionMetadata(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error) {
+	return a.client.ConstructionPayloads(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error) {
+	return a.client.ConstructionCombine(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error) {
+	return a.client.ConstructionParse(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error) {
+	return a.client.ConstructionHash(ctx, request)
+}
+
+func (a *meshAdapter) ConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error) {
+	return a.client.ConstructionSubmit(ctx, request)
+}
+
+func (a *meshAdapter) Health(ctx context.Context) bool {
+	return a.client.Health(ctx)
+}
@@ -1,62 +1,102 @@
 package mesh
 
 import (
-    "bytes"
+    "context"
     "errors"
-    "io"
-    "net/http"
     "testing"
 
+    rotypes "github.com/coinbase/rosetta-sdk-go/types"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/require"
 )
 
-// mockMeshAPI implements clients.MeshAPI for testing the adapter mapping
+// mockMeshAPI implements clients.MeshAPIV2 for testing the adapter mapping
 type mockMeshAPI struct {
-    listResp       *http.Response
-    listErr        error
-    balanceResp    *http.Response
-    balanceErr     error
-    health         bool
+    listResp    *rotypes.NetworkListResponse
+    listErr     error
+    balanceReq  *rotypes.AccountBalanceRequest
+    balanceResp *rotypes.AccountBalanceResponse
+    balanceErr  error
+    health      bool
 }
 
-func (m *mockMeshAPI) ListNetworks() (*http.Response, error) { return m.listResp, m.listErr }
-func (m *mockMeshAPI) NetworkStatus(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error) {
+func (m *mockMeshAPI) NetworkList(ctx context.Context) (*rotypes.NetworkListResponse, error) {
+    return m.listResp, m.listErr
+}
+func (m *mockMeshAPI) NetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) NetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error) {
     return nil, nil
 }
-func (m *mockMeshAPI) NetworkOptions(networkIdentifier interface{}) (*http.Response, error) { return nil, nil }
-func (m *mockMeshAPI) AccountBalance(networkIdentifier, accountIdentifier interface{}) (*http.Response, error) {
+func (m *mockMeshAPI) AccountBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error) {
+    m.balanceReq = request
     return m.balanceResp, m.balanceErr
 }
-// Added to satisfy clients.MeshAPI interface after Block methods were introduced
-func (m *mockMeshAPI) Block(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error) {
+func (m *mockMeshAPI) Block(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error) {
     return nil, nil
 }
-func (m *mockMeshAPI) BlockTransaction(networkIdentifier interface{}, blockIdentifier interface{}, transactionIdentifier interface{}) (*http.Response, error) {
+func (m *mockMeshAPI) BlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error) {
     return nil, nil
 }
-func (m *mockMeshAPI) Health() bool { return m.health }
-
-func newHTTPResponse(status int, body string) *http.Response {
-    return &http.Response{
-        StatusCode: status,
-        Body:       io.NopCloser(bytes.NewBufferString(body)),
-        Header:     http.Header{"Content-Type": []string{"application/json"}},
-    }
+func (m *mockMeshAPI) Call(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) EventsBlocks(ctx context.Context, request *rotypes.EventsBlocksRequest) (*rotypes.EventsBlocksResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) SearchTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) AccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) Mempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) MempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error) {
+    return nil, nil
+}
+func (m *mockMeshAPI) ConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error) {
+    return nil, nil
 }
+func (m *mockMeshAPI) Health(ctx context.Context) bool { return m.health }
+
+var sepolia = &rotypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"}
 
 func TestAdapter_ListNetworks_MapsCurrencyDefaults(t *testing.T) {
-    rosettaList := `{
-        "network_identifiers": [
-            {"blockchain": "Ethereum", "network": "Sepolia"},
-            {"blockchain": "Bitcoin", "network": "Testnet3"}
-        ]
-    }`
-
-    mockClient := &mockMeshAPI{listResp: newHTTPResponse(200, rosettaList), health: true}
+    rosettaList := &rotypes.NetworkListResponse{
+        NetworkIdentifiers: []*rotypes.NetworkIdentifier{
+            sepolia,
+            {Blockchain: "Bitcoin", Network: "Testnet3"},
+        },
+    }
+
+    mockClient := &mockMeshAPI{listResp: rosettaList, health: true}
     a := NewAdapter(mockClient)
 
-    resp, err := a.ListNetworks()
+    resp, err := a.ListNetworks(context.Background())
     require.NoError(t, err)
     require.NotNil(t, resp)
     require.Len(t, resp.Networks, 2)
@@ -78,61 +118,40 @@ func TestAdapter_ListNetworks_MapsCurrencyDefaults(t *testing.T) {
     assert.Equal(t, 18, n1.Currency.Decimals)
 
     // Health should reflect underlying client
-    assert.True(t, a.Health())
+    assert.True(t, a.Health(context.Background()))
 }
 
 func TestAdapter_ListNetworks_Error(t *testing.T) {
     mockClient := &mockMeshAPI{listErr: errors.New("boom")}
     a := NewAdapter(mockClient)
 
-    resp, err := a.ListNetworks()
+    resp, err := a.ListNetworks(context.Background())
     require.Error(t, err)
     assert.Nil(t, resp)
 }
 
-func TestAdapter_ListNetworks_BadJSON(t *testing.T) {
-    mockClient := &mockMeshAPI{listResp: newHTTPResponse(200, "not json")}
-    a := NewAdapter(mockClient)
-
-    resp, err := a.ListNetworks()
-    require.Error(t, err)
-    assert.Nil(t, resp)
-}
-
-func TestAdapter_AccountBalance_PassThroughDecode(t *testing.T) {
-    balanceJSON := `{
-        "balances": [
-            {"value": "1234567890000000000", "currency": {"symbol": "ETH", "decimals": 18}}
-        ]
-    }`
-    mockClient := &mockMeshAPI{balanceResp: newHTTPResponse(200, balanceJSON)}
+func TestAdapter_AccountBalance_PassesThrough(t *testing.T) {
+    balance := &rotypes.AccountBalanceResponse{
+        BlockIdentifier: &rotypes.BlockIdentifier{Index: 7, Hash: "0x7"},
+        Balances: []*rotypes.Amount{
+            {Value: "1234567890000000000", Currency: &rotypes.Currency{Symbol: "ETH", Decimals: 18}},
+        },
+    }
+    mockClient := &mockMeshAPI{balanceResp: balance}
     a := NewAdapter(mockClient)
 
-    resp, err := a.AccountBalance(map[string]string{"blockchain": "Ethereum", "network": "Sepolia"}, map[string]string{"address": "0xabc"})
+    req := &rotypes.AccountBalanceRequest{NetworkIdentifier: sepolia, AccountIdentifier: &rotypes.AccountIdentifier{Address: "0xabc"}}
+    resp, err := a.AccountBalance(context.Background(), req)
     require.NoError(t, err)
-    require.NotNil(t, resp)
-
-    require.Len(t, resp.Balances, 1)
-    b := resp.Balances[0]
-    assert.Equal(t, "ETH", b.Currency.Symbol)
-    assert.Equal(t, 18, b.Currency.Decimals)
-    assert.Equal(t, "1234567890000000000", b.Value)
+    assert.Same(t, req, mockClient.balanceReq)
+    assert.Equal(t, balance, resp)
 }
 
 func TestAdapter_AccountBalance_Error(t *testing.T) {
     mockClient := &mockMeshAPI{balanceErr: errors.New("downstream error")}
     a := NewAdapter(mockClient)
 
-    resp, err := a.AccountBalance(map[string]string{"blockchain": "Ethereum", "network": "Sepolia"}, map[string]string{"address": "0xabc"})
-    require.Error(t, err)
-    assert.Nil(t, resp)
-}
-
-func TestAdapter_AccountBalance_BadJSON(t *testing.T) {
-    mockClient := &mockMeshAPI{balanceResp: newHTTPResponse(200, "{" )}
-    a := NewAdapter(mockClient)
-
-    resp, err := a.AccountBalance(map[string]string{"blockchain": "Ethereum", "network": "Sepolia"}, map[string]string{"address": "0xabc"})
+    resp, err := a.AccountBalance(context.Background(), &rotypes.AccountBalanceRequest{NetworkIdentifier: sepolia, AccountIdentifier: &rotypes.AccountIdentifier{Address: "0xabc"}})
     require.Error(t, err)
     assert.Nil(t, resp)
 }
@@ -140,6 +159,6 @@ func TestAdapter_AccountBalance_BadJSON(t *testing.T) {
 func TestAdapter_Health_Delegates(t *testing.T) {
     aTrue := NewAdapter(&mockMeshAPI{health: true})
     aFalse := NewAdapter(&mockMeshAPI{health: false})
-    assert.True(t, aTrue.Health())
-    assert.False(t, aFalse.Health())
+    assert.True(t, aTrue.Health(context.Background()))
+    assert.False(t, aFalse.Health(context.Background()))
 }
\ No newline at end of file
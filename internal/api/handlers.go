@@ -2,6 +2,8 @@ package api
 
 import (
     "bytes"
+    "context"
+    "errors"
     "net/http"
     "os"
     "os/exec"
@@ -15,6 +17,7 @@ import (
     "github.com/rutishh0/testingquant/internal/clients"
     "github.com/rutishh0/testingquant/internal/tests"
 
+    rotypes "github.com/coinbase/rosetta-sdk-go/types"
     "github.com/gin-gonic/gin"
 )
 
@@ -662,16 +665,51 @@ func (h *Handlers) CreateOverledgerTransaction(c *gin.Context) {
 
     transaction, err := h.connectorService.CreateOverledgerTransaction(&req)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, connector.ErrorResponse{
+        // Without a node for the network the request must carry its own fees
+        status := http.StatusInternalServerError
+        if errors.Is(err, overledger.ErrNoRPC) {
+            status = http.StatusBadRequest
+        }
+        c.JSON(status, connector.ErrorResponse{
             Error:   "overledger_transaction_failed",
             Message: err.Error(),
-            Code:    500,
+            Code:    status,
         })
         return
     }
     c.JSON(http.StatusCreated, transaction)
 }
 
+// EstimateOverledgerFees handles POST /v1/overledger/fees/estimate. It quotes the
+// gas fees of a payment at each urgency level before it is submitted.
+func (h *Handlers) EstimateOverledgerFees(c *gin.Context) {
+    var req overledger.FeeEstimateRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, connector.ErrorResponse{
+            Error:   "invalid_request",
+            Message: err.Error(),
+            Code:    400,
+        })
+        return
+    }
+
+    estimate, err := h.connectorService.EstimateOverledgerFees(&req)
+    if err != nil {
+        // Without a node for the network there is nothing to estimate from
+        status := http.StatusInternalServerError
+        if errors.Is(err, overledger.ErrNoRPC) {
+            status = http.StatusServiceUnavailable
+        }
+        c.JSON(status, connector.ErrorResponse{
+            Error:   "overledger_fee_estimate_failed",
+            Message: err.Error(),
+            Code:    status,
+        })
+        return
+    }
+    c.JSON(http.StatusOK, estimate)
+}
+
 // GetOverledgerTransactionStatus handles GET /v1/overledger/networks/:networkId/transactions/:txHash/status
 func (h *Handlers) GetOverledgerTransactionStatus(c *gin.Context) {
     networkID := c.Param("networkId")
@@ -720,80 +758,381 @@ func (h *Handlers) TestOverledgerConnection(c *gin.Context) {
 
 // GetMeshNetworks handles GET /v1/mesh/networks
 func (h *Handlers) GetMeshNetworks(c *gin.Context) {
-    networks, err := h.connectorService.GetMeshNetworks()
+    networks, err := h.connectorService.GetMeshNetworks(c.Request.Context())
     if err != nil {
-        c.JSON(http.StatusInternalServerError, connector.ErrorResponse{
-            Error:   "mesh_networks_failed",
-            Message: err.Error(),
-            Code:    500,
-        })
+        respondMeshError(c, "mesh_networks_failed", err)
         return
     }
     c.JSON(http.StatusOK, networks)
 }
 
-// GetMeshAccountBalance handles POST /v1/mesh/account/balance
-func (h *Handlers) GetMeshAccountBalance(c *gin.Context) {
-    var req struct {
-        NetworkIdentifier interface{} `json:"network_identifier"`
-        AccountIdentifier interface{} `json:"account_identifier"`
+// bindMeshRequest decodes the body into request, a Rosetta request type, and
+// validates it. On failure it answers 400 and returns false.
+func bindMeshRequest(c *gin.Context, request interface{}) bool {
+    err := c.ShouldBindJSON(request)
+    if err == nil {
+        err = validateMeshRequest(request)
     }
-    if err := c.ShouldBindJSON(&req); err != nil {
+    if err != nil {
         c.JSON(http.StatusBadRequest, connector.ErrorResponse{
             Error:   "invalid_request",
             Message: err.Error(),
             Code:    400,
         })
+        return false
+    }
+    return true
+}
+
+// respondMeshError answers a failed Mesh operation. errorKey names the operation; the status
+// depends on the failure:
+//   - 503 when Mesh is not configured or returned a retriable Rosetta error
+//   - 422 when Mesh rejected the request with a non-retriable Rosetta error
+//   - 504 when the request timed out
+//   - 502 for any other upstream failure
+// Rosetta errors are included as mesh_error.
+func respondMeshError(c *gin.Context, errorKey string, err error) {
+    status := http.StatusBadGateway
+    var rosettaErr *rotypes.Error
+    var meshErr *clients.MeshError
+    switch {
+    case errors.Is(err, connector.ErrMeshNotInitialized):
+        status = http.StatusServiceUnavailable
+    case errors.Is(err, context.DeadlineExceeded):
+        status = http.StatusGatewayTimeout
+    case errors.As(err, &meshErr) && meshErr.Err != nil:
+        rosettaErr = meshErr.Err
+        status = http.StatusUnprocessableEntity
+        if rosettaErr.Retriable {
+            status = http.StatusServiceUnavailable
+        }
+    }
+    c.JSON(status, connector.ErrorResponse{
+        Error:     errorKey,
+        Message:   err.Error(),
+        Code:      status,
+        MeshError: rosettaErr,
+    })
+}
+
+// GetMeshAccountBalance handles POST /v1/mesh/account/balance. The body is a
+// Rosetta AccountBalanceRequest.
+func (h *Handlers) GetMeshAccountBalance(c *gin.Context) {
+    var req rotypes.AccountBalanceRequest
+    if !bindMeshRequest(c, &req) {
         return
     }
 
-    balance, err := h.connectorService.GetMeshNetworkBalance(req.NetworkIdentifier, req.AccountIdentifier)
+    balance, err := h.connectorService.GetMeshNetworkBalance(c.Request.Context(), &req)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, connector.ErrorResponse{
-            Error:   "mesh_balance_failed",
-            Message: err.Error(),
-            Code:    500,
-        })
+        respondMeshError(c, "mesh_balance_failed", err)
         return
     }
     c.JSON(http.StatusOK, balance)
 }
 
-// GetMeshBlock handles POST /v1/mesh/block
+// GetMeshBlock handles POST /v1/mesh/block. The body is a Rosetta
+// BlockRequest; without a block_identifier the latest block is returned.
 func (h *Handlers) GetMeshBlock(c *gin.Context) {
-    var req struct {
-        NetworkIdentifier interface{} `json:"network_identifier"`
-        BlockIdentifier   interface{} `json:"block_identifier"`
-    }
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, connector.ErrorResponse{
-            Error:   "invalid_request",
-            Message: err.Error(),
-            Code:    400,
-        })
+    req := rotypes.BlockRequest{BlockIdentifier: &rotypes.PartialBlockIdentifier{}}
+    if !bindMeshRequest(c, &req) {
         return
     }
 
-    block, err := h.connectorService.GetMeshBlock(req.NetworkIdentifier, req.BlockIdentifier)
+    block, err := h.connectorService.GetMeshBlock(c.Request.Context(), &req)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, connector.ErrorResponse{
-            Error:   "mesh_block_failed",
-            Message: err.Error(),
-            Code:    500,
-        })
+        respondMeshError(c, "mesh_block_failed", err)
         return
     }
     c.JSON(http.StatusOK, block)
 }
 
-// GetMeshBlockTransaction handles POST /v1/mesh/block/transaction
+// GetMeshBlockTransaction handles POST /v1/mesh/block/transaction. The body
+// is a Rosetta BlockTransactionRequest.
 func (h *Handlers) GetMeshBlockTransaction(c *gin.Context) {
-    var req struct {
-        NetworkIdentifier     interface{} `json:"network_identifier"`
-        BlockIdentifier       interface{} `json:"block_identifier"`
-        TransactionIdentifier interface{} `json:"transaction_identifier"`
+    var req rotypes.BlockTransactionRequest
+    if !bindMeshRequest(c, &req) {
+        return
     }
-    if err := c.ShouldBindJSON(&req); err != nil {
+
+    tx, err := h.connectorService.GetMeshBlockTransaction(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_block_transaction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, tx)
+}
+
+// CallMesh handles POST /v1/mesh/call. The body is a Rosetta CallRequest.
+func (h *Handlers) CallMesh(c *gin.Context) {
+    req := rotypes.CallRequest{Parameters: map[string]interface{}{}}
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.CallMesh(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_call_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// SearchMeshTransactions handles POST /v1/mesh/search/transactions. The body
+// is a Rosetta SearchTransactionsRequest.
+func (h *Handlers) SearchMeshTransactions(c *gin.Context) {
+    var req rotypes.SearchTransactionsRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.SearchMeshTransactions(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_search_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// GetMeshNetworkStatus handles POST /v1/mesh/network/status. The body is a Rosetta
+// NetworkRequest; the response includes sync status and peers.
+func (h *Handlers) GetMeshNetworkStatus(c *gin.Context) {
+    var req rotypes.NetworkRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    status, err := h.connectorService.GetMeshNetworkStatus(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_network_status_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, status)
+}
+
+// GetMeshNetworkOptions handles POST /v1/mesh/network/options. The body is a Rosetta
+// NetworkRequest.
+func (h *Handlers) GetMeshNetworkOptions(c *gin.Context) {
+    var req rotypes.NetworkRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    options, err := h.connectorService.GetMeshNetworkOptions(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_network_options_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, options)
+}
+
+// GetMeshAccountCoins handles POST /v1/mesh/account/coins. The body is a Rosetta
+// AccountCoinsRequest. Account-based networks answer 422.
+func (h *Handlers) GetMeshAccountCoins(c *gin.Context) {
+    var req rotypes.AccountCoinsRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    coins, err := h.connectorService.GetMeshAccountCoins(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_coins_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, coins)
+}
+
+// GetMeshMempool handles POST /v1/mesh/mempool. The body is a Rosetta NetworkRequest.
+func (h *Handlers) GetMeshMempool(c *gin.Context) {
+    var req rotypes.NetworkRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    mempool, err := h.connectorService.GetMeshMempool(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_mempool_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, mempool)
+}
+
+// GetMeshMempoolTransaction handles POST /v1/mesh/mempool/transaction. The body is a Rosetta
+// MempoolTransactionRequest.
+func (h *Handlers) GetMeshMempoolTransaction(c *gin.Context) {
+    var req rotypes.MempoolTransactionRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    tx, err := h.connectorService.GetMeshMempoolTransaction(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_mempool_transaction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, tx)
+}
+
+// MeshConstructionDerive handles POST /v1/mesh/construction/derive. The body is a Rosetta
+// ConstructionDeriveRequest.
+func (h *Handlers) MeshConstructionDerive(c *gin.Context) {
+    var req rotypes.ConstructionDeriveRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionDerive(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// MeshConstructionPreprocess handles POST /v1/mesh/construction/preprocess. The body is a Rosetta
+// ConstructionPreprocessRequest.
+func (h *Handlers) MeshConstructionPreprocess(c *gin.Context) {
+    var req rotypes.ConstructionPreprocessRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionPreprocess(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// MeshConstructionMetadata handles POST /v1/mesh/construction/metadata. The body is a Rosetta
+// ConstructionMetadataRequest.
+func (h *Handlers) MeshConstructionMetadata(c *gin.Context) {
+    var req rotypes.ConstructionMetadataRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionMetadata(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// MeshConstructionPayloads handles POST /v1/mesh/construction/payloads. The body is a Rosetta
+// ConstructionPayloadsRequest.
+func (h *Handlers) MeshConstructionPayloads(c *gin.Context) {
+    var req rotypes.ConstructionPayloadsRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionPayloads(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// MeshConstructionCombine handles POST /v1/mesh/construction/combine. The body is a Rosetta
+// ConstructionCombineRequest.
+func (h *Handlers) MeshConstructionCombine(c *gin.Context) {
+    var req rotypes.ConstructionCombineRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionCombine(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// MeshConstructionParse handles POST /v1/mesh/construction/parse. The body is a Rosetta
+// ConstructionParseRequest.
+func (h *Handlers) MeshConstructionParse(c *gin.Context) {
+    var req rotypes.ConstructionParseRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionParse(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// MeshConstructionHash handles POST /v1/mesh/construction/hash. The body is a Rosetta
+// ConstructionHashRequest.
+func (h *Handlers) MeshConstructionHash(c *gin.Context) {
+    var req rotypes.ConstructionHashRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionHash(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// MeshConstructionSubmit handles POST /v1/mesh/construction/submit. The body is a Rosetta
+// ConstructionSubmitRequest.
+func (h *Handlers) MeshConstructionSubmit(c *gin.Context) {
+    var req rotypes.ConstructionSubmitRequest
+    if !bindMeshRequest(c, &req) {
+        return
+    }
+
+    result, err := h.connectorService.MeshConstructionSubmit(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_construction_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+// meshNetworkFromPath reads the :blockchain and :network path parameters.
+func meshNetworkFromPath(c *gin.Context) *rotypes.NetworkIdentifier {
+    return &rotypes.NetworkIdentifier{
+        Blockchain: c.Param("blockchain"),
+        Network:    c.Param("network"),
+    }
+}
+
+// GetMeshLatestBlock handles GET /v1/mesh/networks/:blockchain/:network/block/latest
+func (h *Handlers) GetMeshLatestBlock(c *gin.Context) {
+    req := rotypes.BlockRequest{
+        NetworkIdentifier: meshNetworkFromPath(c),
+        BlockIdentifier:   &rotypes.PartialBlockIdentifier{},
+    }
+
+    block, err := h.connectorService.GetMeshBlock(c.Request.Context(), &req)
+    if err != nil {
+        respondMeshError(c, "mesh_block_failed", err)
+        return
+    }
+    c.JSON(http.StatusOK, block)
+}
+
+// GetMeshBalanceByAddress handles GET /v1/mesh/networks/:blockchain/:network/accounts/:address/balance.
+// It returns the balances at the latest block of the network's native currency and of every
+// ERC-20 token registered for the network.
+func (h *Handlers) GetMeshBalanceByAddress(c *gin.Context) {
+    req := rotypes.AccountBalanceRequest{
+        NetworkIdentifier: meshNetworkFromPath(c),
+        AccountIdentifier: &rotypes.AccountIdentifier{Address: c.Param("address")},
+    }
+    if err := validateMeshRequest(&req); err != nil {
         c.JSON(http.StatusBadRequest, connector.ErrorResponse{
             Error:   "invalid_request",
             Message: err.Error(),
@@ -802,14 +1141,10 @@ func (h *Handlers) GetMeshBlockTransaction(c *gin.Context) {
         return
     }
 
-    tx, err := h.connectorService.GetMeshBlockTransaction(req.NetworkIdentifier, req.BlockIdentifier, req.TransactionIdentifier)
+    balance, err := h.connectorService.GetMeshNetworkBalance(c.Request.Context(), &req)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, connector.ErrorResponse{
-            Error:   "mesh_block_transaction_failed",
-            Message: err.Error(),
-            Code:    500,
-        })
+        respondMeshError(c, "mesh_balance_failed", err)
         return
     }
-    c.JSON(http.StatusOK, tx)
-}
\ No newline at end of file
+    c.JSON(http.StatusOK, balance)
+}
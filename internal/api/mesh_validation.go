@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// validateMeshRequest checks the format of a /v1/mesh request with the Rosetta
+// asserter before it is forwarded. Whether the Mesh server serves the network
+// is left to the server.
+func validateMeshRequest(request interface{}) error {
+	switch r := request.(type) {
+	case *rotypes.NetworkRequest:
+		return asserter.NetworkIdentifier(r.NetworkIdentifier)
+	case *rotypes.AccountBalanceRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if err := asserter.AccountIdentifier(r.AccountIdentifier); err != nil {
+			return fmt.Errorf("account_identifier: %w", err)
+		}
+		if r.BlockIdentifier != nil {
+			if err := asserter.PartialBlockIdentifier(r.BlockIdentifier); err != nil {
+				return fmt.Errorf("block_identifier: %w", err)
+			}
+		}
+		return validateCurrencies(r.Currencies)
+	case *rotypes.BlockRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if err := asserter.PartialBlockIdentifier(r.BlockIdentifier); err != nil {
+			return fmt.Errorf("block_identifier: %w", err)
+		}
+	case *rotypes.BlockTransactionRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if err := asserter.BlockIdentifier(r.BlockIdentifier); err != nil {
+			return fmt.Errorf("block_identifier: %w", err)
+		}
+		if err := asserter.TransactionIdentifier(r.TransactionIdentifier); err != nil {
+			return fmt.Errorf("transaction_identifier: %w", err)
+		}
+	case *rotypes.CallRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if r.Method == "" {
+			return errors.New("method is required")
+		}
+	case *rotypes.SearchTransactionsRequest:
+		return validateSearchRequest(r)
+	case *rotypes.AccountCoinsRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if err := asserter.AccountIdentifier(r.AccountIdentifier); err != nil {
+			return fmt.Errorf("account_identifier: %w", err)
+		}
+		return validateCurrencies(r.Currencies)
+	case *rotypes.MempoolTransactionRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if err := asserter.TransactionIdentifier(r.TransactionIdentifier); err != nil {
+			return fmt.Errorf("transaction_identifier: %w", err)
+		}
+	default:
+		return validateConstructionRequest(request)
+	}
+	return nil
+}
+
+// validateConstructionRequest checks the fields each /construction step needs.
+// Operations are checked against the network's options by the Mesh server.
+func validateConstructionRequest(request interface{}) error {
+	switch r := request.(type) {
+	case *rotypes.ConstructionDeriveRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if err := asserter.PublicKey(r.PublicKey); err != nil {
+			return fmt.Errorf("public_key: %w", err)
+		}
+	case *rotypes.ConstructionPreprocessRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if len(r.Operations) == 0 {
+			return errors.New("operations are required")
+		}
+	case *rotypes.ConstructionMetadataRequest:
+		return asserter.NetworkIdentifier(r.NetworkIdentifier)
+	case *rotypes.ConstructionPayloadsRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if len(r.Operations) == 0 {
+			return errors.New("operations are required")
+		}
+		for _, key := range r.PublicKeys {
+			if err := asserter.PublicKey(key); err != nil {
+				return fmt.Errorf("public_keys: %w", err)
+			}
+		}
+	case *rotypes.ConstructionCombineRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if r.UnsignedTransaction == "" {
+			return errors.New("unsigned_transaction is required")
+		}
+		if err := asserter.Signatures(r.Signatures); err != nil {
+			return fmt.Errorf("signatures: %w", err)
+		}
+	case *rotypes.ConstructionParseRequest:
+		if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+			return err
+		}
+		if r.Transaction == "" {
+			return errors.New("transaction is required")
+		}
+	case *rotypes.ConstructionHashRequest:
+		return validateSignedTransaction(r.NetworkIdentifier, r.SignedTransaction)
+	case *rotypes.ConstructionSubmitRequest:
+		return validateSignedTransaction(r.NetworkIdentifier, r.SignedTransaction)
+	}
+	return nil
+}
+
+func validateSignedTransaction(network *rotypes.NetworkIdentifier, signed string) error {
+	if err := asserter.NetworkIdentifier(network); err != nil {
+		return err
+	}
+	if signed == "" {
+		return errors.New("signed_transaction is required")
+	}
+	return nil
+}
+
+func validateSearchRequest(r *rotypes.SearchTransactionsRequest) error {
+	if err := asserter.NetworkIdentifier(r.NetworkIdentifier); err != nil {
+		return err
+	}
+	if r.Operator != nil && *r.Operator != rotypes.AND && *r.Operator != rotypes.OR {
+		return asserter.ErrOperatorInvalid
+	}
+	for _, f := range []struct {
+		name  string
+		value *int64
+	}{{"max_block", r.MaxBlock}, {"offset", r.Offset}, {"limit", r.Limit}} {
+		if f.value != nil && *f.value < 0 {
+			return fmt.Errorf("%s must not be negative", f.name)
+		}
+	}
+	if r.TransactionIdentifier != nil {
+		if err := asserter.TransactionIdentifier(r.TransactionIdentifier); err != nil {
+			return fmt.Errorf("transaction_identifier: %w", err)
+		}
+	}
+	if r.AccountIdentifier != nil {
+		if err := asserter.AccountIdentifier(r.AccountIdentifier); err != nil {
+			return fmt.Errorf("account_identifier: %w", err)
+		}
+	}
+	if r.CoinIdentifier != nil {
+		if err := asserter.CoinIdentifier(r.CoinIdentifier); err != nil {
+			return fmt.Errorf("coin_identifier: %w", err)
+		}
+	}
+	if r.Currency != nil {
+		return validateCurrencies([]*rotypes.Currency{r.Currency})
+	}
+	return nil
+}
+
+func validateCurrencies(currencies []*rotypes.Currency) error {
+	for _, currency := range currencies {
+		if err := asserter.Currency(currency); err != nil {
+			return fmt.Errorf("currency: %w", err)
+		}
+	}
+	return nil
+}
@@ -14,12 +14,14 @@ import (
 	// Rosetta + Mesh services
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/server"
-	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/rutishh0/mesh-server/services"
 )
 
-// SetupRouter configures the Gin router and routes
-func SetupRouter(connectorService connector.Service, cfg *config.Config) *gin.Engine {
+// SetupRouter configures the Gin router and routes. meshNetworks are served
+// under /mesh; the caller owns them, starts their event pollers and indexers
+// and closes them. When nil
+// the Mesh API is not mounted.
+func SetupRouter(connectorService connector.Service, cfg *config.Config, meshNetworks *services.Networks) *gin.Engine {
 	router := gin.Default()
 
 	// Configure CORS to allow frontend to access APIs
@@ -53,38 +55,48 @@ func SetupRouter(connectorService connector.Service, cfg *config.Config) *gin.En
 
 	// Mount Rosetta-compliant Mesh API under /mesh using services from mesh-server module
 	// This enables validation tests to call /mesh/network/*, /mesh/account/*, /mesh/block*, etc.
-	{
-		network := &types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"}
+	if networks := meshNetworks; networks != nil {
 		assr, err := asserter.NewServer(
-			[]string{"Transfer", "Reward", "Fee"},
-			false,
-			[]*types.NetworkIdentifier{network},
-			nil,
+			services.OperationTypes,
+			// /account/balance serves past blocks; check:data reconciles at them
+			true,
+			networks.Identifiers(),
+			services.CallMethods,
 			false,
 			"",
 		)
 		if err != nil {
-			log.Printf("Failed to initialize Mesh Rosetta asserter: %v", err)
+			log.Printf("Failed to initialize Mesh Rosetta API: %v", err)
 		} else {
-            // Initialize RPC client from environment
-            rpc, rpcErr := services.NewEthRPCFromEnv()
-            if rpcErr != nil {
-                log.Printf("Mesh RPC not configured or unreachable, using mock fallback: %v", rpcErr)
-                rpc = nil
-            }
-            networkAPIService := services.NewNetworkAPIService(network, rpc)
+            networkAPIService := services.NewNetworkAPIService(networks)
             networkAPIController := server.NewNetworkAPIController(networkAPIService, assr)
 
-            blockAPIService := services.NewBlockAPIService(network, rpc)
+            blockAPIService := services.NewBlockAPIService(networks)
             blockAPIController := server.NewBlockAPIController(blockAPIService, assr)
 
-            accountAPIService := services.NewAccountAPIService(network, rpc)
+            accountAPIService := services.NewAccountAPIService(networks)
             accountAPIController := server.NewAccountAPIController(accountAPIService, assr)
 
-            rosettaRouter := server.NewRouter(networkAPIController, blockAPIController, accountAPIController)
+            constructionAPIService := services.NewConstructionAPIService(networks)
+            constructionAPIController := server.NewConstructionAPIController(constructionAPIService, assr)
+
+            mempoolAPIService := services.NewMempoolAPIService(networks)
+            mempoolAPIController := server.NewMempoolAPIController(mempoolAPIService, assr)
+
+            callAPIService := services.NewCallAPIService(networks)
+            callAPIController := server.NewCallAPIController(callAPIService, assr)
+
+            eventsAPIService := services.NewEventsAPIService(networks)
+            eventsAPIController := server.NewEventsAPIController(eventsAPIService, assr)
 
-            // Path rewrite so wrapped router sees /network/list (no /mesh prefix)
-            router.Any("/mesh/*path", func(c *gin.Context) {
+            searchAPIService := services.NewSearchAPIService(networks)
+            searchAPIController := server.NewSearchAPIController(searchAPIService, assr)
+
+            rosettaRouter := services.WithSourceHeader(server.NewRouter(networkAPIController, blockAPIController, accountAPIController, constructionAPIController, mempoolAPIController, callAPIController, eventsAPIController, searchAPIController))
+
+            // Path rewrite so wrapped router sees /network/list (no /mesh prefix).
+            // Registered before router.Use below, so the API key check is added here.
+            router.Any("/mesh/*path", apiKeyMiddleware(cfg), func(c *gin.Context) {
                 r := c.Request.Clone(c.Request.Context())
                 r.URL.Path = strings.TrimPrefix(c.Request.URL.Path, "/mesh")
                 rosettaRouter.ServeHTTP(c.Writer, r)
@@ -142,6 +154,7 @@ func SetupRouter(connectorService connector.Service, cfg *config.Config) *gin.En
 
 			// Transaction operations
 			overledger.POST("/transactions", handlers.CreateOverledgerTransaction)
+			overledger.POST("/fees/estimate", handlers.EstimateOverledgerFees)
 			overledger.GET("/networks/:networkId/transactions/:txHash/status", handlers.GetOverledgerTransactionStatus)
 
 			// Connection test
@@ -152,10 +165,37 @@ func SetupRouter(connectorService connector.Service, cfg *config.Config) *gin.En
 		mesh := v1.Group("/mesh")
 		{
 			mesh.GET("/networks", handlers.GetMeshNetworks)
+			mesh.POST("/network/status", handlers.GetMeshNetworkStatus)
+			mesh.POST("/network/options", handlers.GetMeshNetworkOptions)
 			mesh.POST("/account/balance", handlers.GetMeshAccountBalance)
+			mesh.POST("/account/coins", handlers.GetMeshAccountCoins)
 			// New: block and transaction retrieval
 			mesh.POST("/block", handlers.GetMeshBlock)
 			mesh.POST("/block/transaction", handlers.GetMeshBlockTransaction)
+			mesh.POST("/mempool", handlers.GetMeshMempool)
+			mesh.POST("/mempool/transaction", handlers.GetMeshMempoolTransaction)
+			mesh.POST("/call", handlers.CallMesh)
+			mesh.POST("/search/transactions", handlers.SearchMeshTransactions)
+
+			// Construction flow
+			mesh.POST("/construction/derive", handlers.MeshConstructionDerive)
+			mesh.POST("/construction/preprocess", handlers.MeshConstructionPreprocess)
+			mesh.POST("/construction/metadata", handlers.MeshConstructionMetadata)
+			mesh.POST("/construction/payloads", handlers.MeshConstructionPayloads)
+			mesh.POST("/construction/combine", handlers.MeshConstructionCombine)
+			mesh.POST("/construction/parse", handlers.MeshConstructionParse)
+			mesh.POST("/construction/hash", handlers.MeshConstructionHash)
+			mesh.POST("/construction/submit", handlers.MeshConstructionSubmit)
+
+			// Convenience endpoints, e.g. /networks/Ethereum/Sepolia/block/latest
+			mesh.GET("/networks/:blockchain/:network/block/latest", handlers.GetMeshLatestBlock)
+			mesh.GET("/networks/:blockchain/:network/accounts/:address/balance", handlers.GetMeshBalanceByAddress)
+			// Block cache hit, miss, eviction and reorg counts per network
+			if meshNetworks != nil {
+				mesh.GET("/cache/stats", func(c *gin.Context) {
+					c.JSON(http.StatusOK, meshNetworks.CacheStats())
+				})
+			}
 		}
 	}
 
@@ -191,9 +231,23 @@ func apiKeyMiddleware(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// publicMeshPaths are the read-only Rosetta data endpoints served without an
+// API key. Construction, mempool and call endpoints require one.
+var publicMeshPaths = map[string]bool{
+	"/mesh/network/list":        true,
+	"/mesh/network/options":     true,
+	"/mesh/network/status":      true,
+	"/mesh/account/balance":     true,
+	"/mesh/account/coins":       true,
+	"/mesh/block":               true,
+	"/mesh/block/transaction":   true,
+	"/mesh/events/blocks":       true,
+	"/mesh/search/transactions": true,
+}
+
 // isPublicPath checks if the path is public and does not require API key
 func isPublicPath(path string) bool {
-	if path == "/health" || path == "/status" || path == "/tests" || strings.HasPrefix(path, "/_next/") || strings.HasPrefix(path, "/static/") || path == "/" || strings.HasPrefix(path, "/mesh/") {
+	if path == "/health" || path == "/status" || path == "/tests" || strings.HasPrefix(path, "/_next/") || strings.HasPrefix(path, "/static/") || path == "/" || publicMeshPaths[path] {
 		return true
 	}
 	return false
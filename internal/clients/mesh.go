@@ -2,36 +2,76 @@ package clients
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "fmt"
     "io"
     "net/http"
     "strings"
     "time"
+
+    rotypes "github.com/coinbase/rosetta-sdk-go/types"
 )
 
 // MeshClient is a lightweight HTTP client for interacting with Coinbase Mesh compliant API servers.
-// NOTE: This initial scaffold intentionally avoids introducing the external mesh-sdk-go dependency so the project continues to build offline.
-//       Once the remote module can be fetched, this file can either be removed or refactored to delegate to the official SDK.
+// It encodes the rosetta-sdk-go request types itself, so it behaves like MeshSDKClient without the
+// SDK's generated client.
 //
-// The Mesh API mostly uses POST requests with a JSON body consisting of `network_identifier`, `block_identifier`, etc. This
-// client provides thin wrappers for common endpoints so the rest of the application can be migrated incrementally.
+// The Mesh API mostly uses POST requests with a JSON body consisting of `network_identifier`, `block_identifier`, etc.
+
+// MeshAPIV2 abstracts the Mesh client operations used by adapters so implementations can be swapped (e.g., HTTP vs SDK).
+// Requests and responses are the rosetta-sdk-go types and every call takes a context. It replaces the untyped MeshAPI,
+// whose methods took interface{} identifiers and returned raw *http.Response values.
+type MeshAPIV2 interface {
+    NetworkList(ctx context.Context) (*rotypes.NetworkListResponse, error)
+    NetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error)
+    NetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error)
+    AccountBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error)
+    // AccountCoins lists an account's unspent coins; account-based networks answer with a Rosetta error.
+    AccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error)
+    // Block treats a nil block_identifier as the latest block.
+    Block(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error)
+    BlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error)
+    // Call runs a read-only network call such as eth_call; see /network/options for the methods.
+    Call(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error)
+    // EventsBlocks pages through block_added/block_removed events from a sequence number; nil offset and limit use the server defaults.
+    EventsBlocks(ctx context.Context, request *rotypes.EventsBlocksRequest) (*rotypes.EventsBlocksResponse, error)
+    // SearchTransactions searches indexed transactions by account, currency, status, type, max_block, offset, limit, ...
+    SearchTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error)
+    // Mempool lists pending transaction identifiers; MempoolTransaction returns one of them.
+    Mempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error)
+    MempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error)
+    // Construction flow: derive, preprocess, metadata, payloads, combine, parse, hash and submit.
+    ConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error)
+    ConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error)
+    ConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error)
+    ConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error)
+    ConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error)
+    ConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error)
+    ConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error)
+    ConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error)
+    Health(ctx context.Context) bool
+}
+
+// MeshError is an error response from a Mesh server. Err holds the Rosetta error when the body is one.
+type MeshError struct {
+    StatusCode int
+    Err        *rotypes.Error
+    Body       string
+}
 
-// MeshAPI abstracts the Mesh client operations used by adapters so implementations can be swapped (e.g., HTTP vs SDK).
-type MeshAPI interface {
-    ListNetworks() (*http.Response, error)
-    NetworkStatus(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error)
-    NetworkOptions(networkIdentifier interface{}) (*http.Response, error)
-    AccountBalance(networkIdentifier, accountIdentifier interface{}) (*http.Response, error)
-    // New: block and transaction retrieval
-    Block(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error)
-    BlockTransaction(networkIdentifier interface{}, blockIdentifier interface{}, transactionIdentifier interface{}) (*http.Response, error)
-    Health() bool
+func (e *MeshError) Error() string {
+    if e.Err != nil {
+        return fmt.Sprintf("mesh API error (%d): %s (code %d)", e.StatusCode, e.Err.Message, e.Err.Code)
+    }
+    return fmt.Sprintf("mesh API error (%d): %s", e.StatusCode, e.Body)
 }
 
 type MeshClient struct {
     BaseURL string
-    Client  *http.Client
+    // APIKey, when set, is sent as X-API-Key for servers that require one
+    APIKey string
+    Client *http.Client
 }
 
 func NewMeshClient(baseURL string) *MeshClient {
@@ -46,99 +86,238 @@ func NewMeshClient(baseURL string) *MeshClient {
     }
 }
 
-// do executes an HTTP request against the Mesh API. The body value will be JSON encoded when non-nil.
-func (m *MeshClient) do(method, path string, body interface{}) (*http.Response, error) {
-    if !strings.HasPrefix(path, "/") {
-        path = "/" + path
-    }
-    var reader io.Reader
-    if body != nil {
-        data, err := json.Marshal(body)
-        if err != nil {
-            return nil, fmt.Errorf("failed to marshal request body: %w", err)
-        }
-        reader = bytes.NewReader(data)
+// post sends request as JSON to path and decodes a 200 response into response. Other statuses
+// are returned as a *MeshError.
+func (m *MeshClient) post(ctx context.Context, path string, request, response interface{}) error {
+    data, err := json.Marshal(request)
+    if err != nil {
+        return fmt.Errorf("failed to marshal request body: %w", err)
     }
-    req, err := http.NewRequest(method, m.BaseURL+path, reader)
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.BaseURL+path, bytes.NewReader(data))
     if err != nil {
-        return nil, fmt.Errorf("failed to create request: %w", err)
+        return fmt.Errorf("failed to create request: %w", err)
     }
     req.Header.Set("Content-Type", "application/json")
+    if m.APIKey != "" {
+        req.Header.Set("X-API-Key", m.APIKey)
+    }
 
     resp, err := m.Client.Do(req)
     if err != nil {
-        return nil, fmt.Errorf("request failed: %w", err)
+        return fmt.Errorf("request failed: %w", err)
     }
-    if resp.StatusCode >= 400 {
-        defer resp.Body.Close()
-        b, _ := io.ReadAll(resp.Body)
-        return nil, fmt.Errorf("mesh API error (%d): %s", resp.StatusCode, string(b))
+    defer resp.Body.Close()
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return fmt.Errorf("failed to read response: %w", err)
     }
-    return resp, nil
+    if resp.StatusCode != http.StatusOK {
+        meshErr := &MeshError{StatusCode: resp.StatusCode, Body: string(body)}
+        var rErr rotypes.Error
+        if json.Unmarshal(body, &rErr) == nil && rErr.Message != "" {
+            meshErr.Err = &rErr
+        }
+        return meshErr
+    }
+    if err := json.Unmarshal(body, response); err != nil {
+        return fmt.Errorf("failed to decode %s response: %w", path, err)
+    }
+    return nil
 }
 
-// ListNetworks maps to POST /network/list
-func (m *MeshClient) ListNetworks() (*http.Response, error) {
-    return m.do(http.MethodPost, "/network/list", map[string]interface{}{})
+// NetworkList maps to POST /network/list
+func (m *MeshClient) NetworkList(ctx context.Context) (*rotypes.NetworkListResponse, error) {
+    var out rotypes.NetworkListResponse
+    if err := m.post(ctx, "/network/list", &rotypes.MetadataRequest{}, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
 }
 
 // NetworkStatus maps to POST /network/status
-func (m *MeshClient) NetworkStatus(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error) {
-    body := map[string]interface{}{
-        "network_identifier": networkIdentifier,
+func (m *MeshClient) NetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error) {
+    var out rotypes.NetworkStatusResponse
+    if err := m.post(ctx, "/network/status", request, &out); err != nil {
+        return nil, err
     }
-    if blockIdentifier != nil {
-        body["block_identifier"] = blockIdentifier
-    }
-    return m.do(http.MethodPost, "/network/status", body)
+    return &out, nil
 }
 
 // NetworkOptions maps to POST /network/options
-func (m *MeshClient) NetworkOptions(networkIdentifier interface{}) (*http.Response, error) {
-    body := map[string]interface{}{
-        "network_identifier": networkIdentifier,
+func (m *MeshClient) NetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error) {
+    var out rotypes.NetworkOptionsResponse
+    if err := m.post(ctx, "/network/options", request, &out); err != nil {
+        return nil, err
     }
-    return m.do(http.MethodPost, "/network/options", body)
+    return &out, nil
 }
 
 // AccountBalance maps to POST /account/balance
-func (m *MeshClient) AccountBalance(networkIdentifier, accountIdentifier interface{}) (*http.Response, error) {
-    body := map[string]interface{}{
-        "network_identifier":  networkIdentifier,
-        "account_identifier":  accountIdentifier,
+func (m *MeshClient) AccountBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error) {
+    var out rotypes.AccountBalanceResponse
+    if err := m.post(ctx, "/account/balance", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// AccountCoins maps to POST /account/coins
+func (m *MeshClient) AccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error) {
+    var out rotypes.AccountCoinsResponse
+    if err := m.post(ctx, "/account/coins", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// Block maps to POST /block
+func (m *MeshClient) Block(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error) {
+    var out rotypes.BlockResponse
+    if err := m.post(ctx, "/block", latestBlock(request), &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// BlockTransaction maps to POST /block/transaction
+func (m *MeshClient) BlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error) {
+    var out rotypes.BlockTransactionResponse
+    if err := m.post(ctx, "/block/transaction", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// Call maps to POST /call
+func (m *MeshClient) Call(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error) {
+    var out rotypes.CallResponse
+    if err := m.post(ctx, "/call", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// EventsBlocks maps to POST /events/blocks
+func (m *MeshClient) EventsBlocks(ctx context.Context, request *rotypes.EventsBlocksRequest) (*rotypes.EventsBlocksResponse, error) {
+    var out rotypes.EventsBlocksResponse
+    if err := m.post(ctx, "/events/blocks", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// SearchTransactions maps to POST /search/transactions
+func (m *MeshClient) SearchTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error) {
+    var out rotypes.SearchTransactionsResponse
+    if err := m.post(ctx, "/search/transactions", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// Mempool maps to POST /mempool
+func (m *MeshClient) Mempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error) {
+    var out rotypes.MempoolResponse
+    if err := m.post(ctx, "/mempool", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// MempoolTransaction maps to POST /mempool/transaction
+func (m *MeshClient) MempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error) {
+    var out rotypes.MempoolTransactionResponse
+    if err := m.post(ctx, "/mempool/transaction", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// ConstructionDerive maps to POST /construction/derive
+func (m *MeshClient) ConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error) {
+    var out rotypes.ConstructionDeriveResponse
+    if err := m.post(ctx, "/construction/derive", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// ConstructionPreprocess maps to POST /construction/preprocess
+func (m *MeshClient) ConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error) {
+    var out rotypes.ConstructionPreprocessResponse
+    if err := m.post(ctx, "/construction/preprocess", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// ConstructionMetadata maps to POST /construction/metadata
+func (m *MeshClient) ConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error) {
+    var out rotypes.ConstructionMetadataResponse
+    if err := m.post(ctx, "/construction/metadata", request, &out); err != nil {
+        return nil, err
     }
-    return m.do(http.MethodPost, "/account/balance", body)
+    return &out, nil
 }
 
-// New: Block maps to POST /block
-func (m *MeshClient) Block(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error) {
-    body := map[string]interface{}{
-        "network_identifier": networkIdentifier,
+// ConstructionPayloads maps to POST /construction/payloads
+func (m *MeshClient) ConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error) {
+    var out rotypes.ConstructionPayloadsResponse
+    if err := m.post(ctx, "/construction/payloads", request, &out); err != nil {
+        return nil, err
     }
-    if blockIdentifier == nil {
-        body["block_identifier"] = map[string]interface{}{}
-    } else {
-        body["block_identifier"] = blockIdentifier
+    return &out, nil
+}
+
+// ConstructionCombine maps to POST /construction/combine
+func (m *MeshClient) ConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error) {
+    var out rotypes.ConstructionCombineResponse
+    if err := m.post(ctx, "/construction/combine", request, &out); err != nil {
+        return nil, err
     }
-    return m.do(http.MethodPost, "/block", body)
+    return &out, nil
 }
 
-// New: BlockTransaction maps to POST /block/transaction
-func (m *MeshClient) BlockTransaction(networkIdentifier interface{}, blockIdentifier interface{}, transactionIdentifier interface{}) (*http.Response, error) {
-    body := map[string]interface{}{
-        "network_identifier":     networkIdentifier,
-        "transaction_identifier": transactionIdentifier,
+// ConstructionParse maps to POST /construction/parse
+func (m *MeshClient) ConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error) {
+    var out rotypes.ConstructionParseResponse
+    if err := m.post(ctx, "/construction/parse", request, &out); err != nil {
+        return nil, err
     }
-    if blockIdentifier == nil {
-        body["block_identifier"] = map[string]interface{}{}
-    } else {
-        body["block_identifier"] = blockIdentifier
+    return &out, nil
+}
+
+// ConstructionHash maps to POST /construction/hash
+func (m *MeshClient) ConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error) {
+    var out rotypes.TransactionIdentifierResponse
+    if err := m.post(ctx, "/construction/hash", request, &out); err != nil {
+        return nil, err
     }
-    return m.do(http.MethodPost, "/block/transaction", body)
+    return &out, nil
+}
+
+// ConstructionSubmit maps to POST /construction/submit
+func (m *MeshClient) ConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error) {
+    var out rotypes.TransactionIdentifierResponse
+    if err := m.post(ctx, "/construction/submit", request, &out); err != nil {
+        return nil, err
+    }
+    return &out, nil
 }
 
 // Health checks the health of the mesh client
-func (m *MeshClient) Health() bool {
-    _, err := m.ListNetworks()
+func (m *MeshClient) Health(ctx context.Context) bool {
+    _, err := m.NetworkList(ctx)
     return err == nil
-}
\ No newline at end of file
+}
+
+// latestBlock returns request with a nil block_identifier replaced by an empty one, which
+// selects the latest block.
+func latestBlock(request *rotypes.BlockRequest) *rotypes.BlockRequest {
+    if request == nil || request.BlockIdentifier != nil {
+        return request
+    }
+    r := *request
+    r.BlockIdentifier = &rotypes.PartialBlockIdentifier{}
+    return &r
+}
@@ -1,12 +1,16 @@
 package clients
 
 import (
+    "context"
     "encoding/json"
+    "errors"
     "io"
     "net/http"
     "net/http/httptest"
     "strings"
     "testing"
+
+    rotypes "github.com/coinbase/rosetta-sdk-go/types"
 )
 
 // helper to decode JSON body into a generic map
@@ -28,6 +32,8 @@ func decodeBody(t *testing.T, r *http.Request) map[string]any {
     return m
 }
 
+var sepoliaID = &rotypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"}
+
 func TestMeshClient_Block_NilBlockIdentifierSendsEmptyObject(t *testing.T) {
     var captured map[string]any
     srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -35,7 +41,7 @@ func TestMeshClient_Block_NilBlockIdentifierSendsEmptyObject(t *testing.T) {
             captured = decodeBody(t, r)
             w.Header().Set("Content-Type", "application/json")
             w.WriteHeader(http.StatusOK)
-            w.Write([]byte(`{}`))
+            w.Write([]byte(`{"block": {"block_identifier": {"index": 7, "hash": "0x7"}, "timestamp": 1}}`))
             return
         }
         w.WriteHeader(http.StatusNotFound)
@@ -43,13 +49,14 @@ func TestMeshClient_Block_NilBlockIdentifierSendsEmptyObject(t *testing.T) {
     defer srv.Close()
 
     client := NewMeshClient(srv.URL)
-    networkID := map[string]any{"blockchain": "Ethereum", "network": "Sepolia"}
 
-    resp, err := client.Block(networkID, nil)
+    resp, err := client.Block(context.Background(), &rotypes.BlockRequest{NetworkIdentifier: sepoliaID})
     if err != nil {
         t.Fatalf("Block returned error: %v", err)
     }
-    resp.Body.Close()
+    if resp.Block == nil || resp.Block.BlockIdentifier.Index != 7 {
+        t.Fatalf("response not decoded: %+v", resp)
+    }
 
     if captured == nil {
         t.Fatalf("server did not capture request body")
@@ -78,7 +85,7 @@ func TestMeshClient_BlockTransaction_WithValues(t *testing.T) {
             captured = decodeBody(t, r)
             w.Header().Set("Content-Type", "application/json")
             w.WriteHeader(http.StatusOK)
-            _, _ = w.Write([]byte(`{}`))
+            _, _ = w.Write([]byte(`{"transaction": {"transaction_identifier": {"hash": "0xabc"}, "operations": []}}`))
             return
         }
         w.WriteHeader(http.StatusNotFound)
@@ -86,15 +93,17 @@ func TestMeshClient_BlockTransaction_WithValues(t *testing.T) {
     defer srv.Close()
 
     client := NewMeshClient(srv.URL)
-    networkID := map[string]any{"blockchain": "Ethereum", "network": "Sepolia"}
-    blockID := map[string]any{"index": 1}
-    txID := map[string]any{"hash": "0xabc"}
-
-    resp, err := client.BlockTransaction(networkID, blockID, txID)
+    resp, err := client.BlockTransaction(context.Background(), &rotypes.BlockTransactionRequest{
+        NetworkIdentifier:     sepoliaID,
+        BlockIdentifier:       &rotypes.BlockIdentifier{Index: 1, Hash: "0x1"},
+        TransactionIdentifier: &rotypes.TransactionIdentifier{Hash: "0xabc"},
+    })
     if err != nil {
         t.Fatalf("BlockTransaction returned error: %v", err)
     }
-    resp.Body.Close()
+    if resp.Transaction.TransactionIdentifier.Hash != "0xabc" {
+        t.Fatalf("response not decoded: %+v", resp)
+    }
 
     if captured == nil {
         t.Fatalf("server did not capture request body")
@@ -120,6 +129,107 @@ func TestMeshClient_BlockTransaction_WithValues(t *testing.T) {
     }
 }
 
+func TestMeshClient_Call_SendsMethodAndParameters(t *testing.T) {
+    var captured map[string]any
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/call" && r.Method == http.MethodPost {
+            captured = decodeBody(t, r)
+            w.Header().Set("Content-Type", "application/json")
+            _, _ = w.Write([]byte(`{"result": {"symbol": "USDC"}, "idempotent": false}`))
+            return
+        }
+        w.WriteHeader(http.StatusNotFound)
+    }))
+    defer srv.Close()
+
+    client := NewMeshClient(srv.URL)
+    resp, err := client.Call(context.Background(), &rotypes.CallRequest{
+        NetworkIdentifier: sepoliaID,
+        Method:            "erc20_metadata",
+        Parameters:        map[string]interface{}{"address": "0xabc"},
+    })
+    if err != nil {
+        t.Fatalf("Call returned error: %v", err)
+    }
+    if resp.Result["symbol"] != "USDC" {
+        t.Fatalf("unexpected result: %v", resp.Result)
+    }
+
+    if captured["method"] != "erc20_metadata" {
+        t.Fatalf("unexpected method: %v", captured["method"])
+    }
+    params, ok := captured["parameters"].(map[string]any)
+    if !ok || params["address"] != "0xabc" {
+        t.Fatalf("unexpected parameters: %v", captured["parameters"])
+    }
+}
+
+func TestMeshClient_EventsBlocks_OmitsUnsetPaging(t *testing.T) {
+    var captured []map[string]any
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/events/blocks" && r.Method == http.MethodPost {
+            captured = append(captured, decodeBody(t, r))
+            w.Header().Set("Content-Type", "application/json")
+            _, _ = w.Write([]byte(`{"max_sequence": 0, "events": []}`))
+            return
+        }
+        w.WriteHeader(http.StatusNotFound)
+    }))
+    defer srv.Close()
+
+    client := NewMeshClient(srv.URL)
+    offset, limit := int64(10), int64(5)
+    for _, paging := range [][2]*int64{{nil, nil}, {&offset, &limit}} {
+        _, err := client.EventsBlocks(context.Background(), &rotypes.EventsBlocksRequest{
+            NetworkIdentifier: sepoliaID,
+            Offset:            paging[0],
+            Limit:             paging[1],
+        })
+        if err != nil {
+            t.Fatalf("EventsBlocks returned error: %v", err)
+        }
+    }
+
+    if _, ok := captured[0]["offset"]; ok {
+        t.Fatalf("offset should be omitted when nil: %v", captured[0])
+    }
+    if captured[1]["offset"] != float64(10) || captured[1]["limit"] != float64(5) {
+        t.Fatalf("unexpected paging: %v", captured[1])
+    }
+}
+
+func TestMeshClient_SearchTransactions_SendsFilters(t *testing.T) {
+    var captured map[string]any
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/search/transactions" && r.Method == http.MethodPost {
+            captured = decodeBody(t, r)
+            w.Header().Set("Content-Type", "application/json")
+            _, _ = w.Write([]byte(`{"transactions": [], "total_count": 0}`))
+            return
+        }
+        w.WriteHeader(http.StatusNotFound)
+    }))
+    defer srv.Close()
+
+    client := NewMeshClient(srv.URL)
+    typ, offset := "Transfer", int64(10)
+    _, err := client.SearchTransactions(context.Background(), &rotypes.SearchTransactionsRequest{
+        NetworkIdentifier: sepoliaID,
+        Type:              &typ,
+        Offset:            &offset,
+    })
+    if err != nil {
+        t.Fatalf("SearchTransactions returned error: %v", err)
+    }
+
+    if captured["type"] != "Transfer" || captured["offset"] != float64(10) {
+        t.Fatalf("filters not forwarded: %v", captured)
+    }
+    if ni, ok := captured["network_identifier"].(map[string]any); !ok || ni["network"] != "Sepolia" {
+        t.Fatalf("unexpected network_identifier: %v", captured["network_identifier"])
+    }
+}
+
 func TestMeshClient_ErrorStatusIncludesBody(t *testing.T) {
     srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         if r.URL.Path == "/network/options" && r.Method == http.MethodPost {
@@ -132,24 +242,44 @@ func TestMeshClient_ErrorStatusIncludesBody(t *testing.T) {
     defer srv.Close()
 
     client := NewMeshClient(srv.URL)
-    networkID := map[string]any{"blockchain": "Ethereum", "network": "Sepolia"}
 
-    resp, err := client.NetworkOptions(networkID)
+    _, err := client.NetworkOptions(context.Background(), &rotypes.NetworkRequest{NetworkIdentifier: sepoliaID})
     if err == nil || !strings.Contains(err.Error(), "mesh API error (500): boom") {
-        if err == nil {
-            if resp != nil { resp.Body.Close() }
-        }
         t.Fatalf("expected 500 error including body, got: %v", err)
     }
+    var meshErr *MeshError
+    if !errors.As(err, &meshErr) || meshErr.StatusCode != 500 || meshErr.Err != nil {
+        t.Fatalf("expected a MeshError without a Rosetta error, got: %#v", err)
+    }
+}
+
+func TestMeshClient_RosettaErrorDecoded(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusInternalServerError)
+        _, _ = w.Write([]byte(`{"code": 6, "message": "Block not found", "retriable": true}`))
+    }))
+    defer srv.Close()
+
+    client := NewMeshClient(srv.URL)
+    _, err := client.Block(context.Background(), &rotypes.BlockRequest{NetworkIdentifier: sepoliaID})
+    var meshErr *MeshError
+    if !errors.As(err, &meshErr) || meshErr.Err == nil {
+        t.Fatalf("expected a MeshError with a Rosetta error, got: %v", err)
+    }
+    if meshErr.Err.Code != 6 || !meshErr.Err.Retriable {
+        t.Fatalf("unexpected Rosetta error: %+v", meshErr.Err)
+    }
 }
 
 func TestMeshClient_RequestMarshalError(t *testing.T) {
     client := NewMeshClient("http://127.0.0.1:0") // baseURL won't be used because marshal fails first
 
     // Introduce an unmarshalable value (func) in the body via parameters
-    bad := func() {}
-    resp, err := client.AccountBalance(bad, map[string]any{"address": "0xdead"})
-    if resp != nil { resp.Body.Close() }
+    _, err := client.Call(context.Background(), &rotypes.CallRequest{
+        NetworkIdentifier: sepoliaID,
+        Method:            "eth_call",
+        Parameters:        map[string]interface{}{"bad": func() {}},
+    })
     if err == nil || !strings.Contains(err.Error(), "failed to marshal request body") {
         t.Fatalf("expected marshal error, got: %v", err)
     }
@@ -177,12 +307,40 @@ func TestMeshClient_Health_PositiveAndNegative(t *testing.T) {
     defer unhealthy.Close()
 
     hc := NewMeshClient(healthy.URL)
-    if !hc.Health() {
+    if !hc.Health(context.Background()) {
         t.Fatalf("expected Health() to be true against healthy server")
     }
 
     uc := NewMeshClient(unhealthy.URL)
-    if uc.Health() {
+    if uc.Health(context.Background()) {
         t.Fatalf("expected Health() to be false against unhealthy server")
     }
-}
\ No newline at end of file
+}
+
+func TestMeshClients_SendAPIKey(t *testing.T) {
+    var keys []string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        keys = append(keys, r.Header.Get("X-API-Key"))
+        w.Header().Set("Content-Type", "application/json")
+        w.Write([]byte(`{"network_identifiers": []}`))
+    }))
+    defer srv.Close()
+
+    httpClient := NewMeshClient(srv.URL)
+    if _, err := httpClient.NetworkList(context.Background()); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    httpClient.APIKey = "secret"
+    if _, err := httpClient.NetworkList(context.Background()); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    sdkClient := NewMeshSDKClient(srv.URL)
+    sdkClient.SetAPIKey("secret")
+    if _, err := sdkClient.NetworkList(context.Background()); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    if strings.Join(keys, ",") != ",secret,secret" {
+        t.Fatalf("X-API-Key headers = %q, want none, then the key from both clients", keys)
+    }
+}
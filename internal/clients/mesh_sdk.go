@@ -1,11 +1,7 @@
 package clients
 
 import (
-    "bytes"
     "context"
-    "encoding/json"
-    "fmt"
-    "io"
     "net/http"
     "strings"
 
@@ -13,9 +9,7 @@ import (
     rotypes "github.com/coinbase/rosetta-sdk-go/types"
 )
 
-// MeshSDKClient implements MeshAPI using the official Rosetta (Mesh) SDK client.
-// It adapts typed SDK responses back into http.Response objects to keep the
-// rest of the code unchanged.
+// MeshSDKClient implements MeshAPIV2 using the official Rosetta (Mesh) SDK client.
 type MeshSDKClient struct {
     baseURL   string
     apiClient *roclient.APIClient
@@ -33,273 +27,148 @@ func NewMeshSDKClient(baseURL string) *MeshSDKClient {
     }
 }
 
-// ListNetworks calls /network/list via SDK and wraps the result into an http.Response
-func (m *MeshSDKClient) ListNetworks() (*http.Response, error) {
-    ctx := context.Background()
+// SetAPIKey sends key as X-API-Key with every request, for servers that
+// require one
+func (m *MeshSDKClient) SetAPIKey(key string) {
+    m.apiClient.GetConfig().AddDefaultHeader("X-API-Key", key)
+}
+
+// NetworkList calls /network/list via SDK
+func (m *MeshSDKClient) NetworkList(ctx context.Context) (*rotypes.NetworkListResponse, error) {
     // MetadataRequest is empty for /network/list
-    resp, _, err := m.apiClient.NetworkAPI.NetworkList(ctx, &rotypes.MetadataRequest{})
-    if err != nil {
-        return nil, err
-    }
-    return wrapJSONResponse(resp)
+    resp, rErr, err := m.apiClient.NetworkAPI.NetworkList(ctx, &rotypes.MetadataRequest{})
+    return resp, sdkError(rErr, err)
 }
 
-// NetworkStatus calls /network/status via SDK and wraps the result
-func (m *MeshSDKClient) NetworkStatus(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error) { // blockIdentifier ignored (not in request schema)
-    ctx := context.Background()
-    ni, err := toNetworkIdentifier(networkIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    req := &rotypes.NetworkRequest{NetworkIdentifier: ni}
-    resp, _, err := m.apiClient.NetworkAPI.NetworkStatus(ctx, req)
-    if err != nil {
-        return nil, err
-    }
-    return wrapJSONResponse(resp)
+// NetworkStatus calls /network/status via SDK
+func (m *MeshSDKClient) NetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error) {
+    resp, rErr, err := m.apiClient.NetworkAPI.NetworkStatus(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-// NetworkOptions calls /network/options via SDK and wraps the result
-func (m *MeshSDKClient) NetworkOptions(networkIdentifier interface{}) (*http.Response, error) {
-    ctx := context.Background()
-    ni, err := toNetworkIdentifier(networkIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    req := &rotypes.NetworkRequest{NetworkIdentifier: ni}
-    resp, _, err := m.apiClient.NetworkAPI.NetworkOptions(ctx, req)
-    if err != nil {
-        return nil, err
-    }
-    return wrapJSONResponse(resp)
+// NetworkOptions calls /network/options via SDK
+func (m *MeshSDKClient) NetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error) {
+    resp, rErr, err := m.apiClient.NetworkAPI.NetworkOptions(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-// AccountBalance calls /account/balance via SDK and wraps the result
-func (m *MeshSDKClient) AccountBalance(networkIdentifier, accountIdentifier interface{}) (*http.Response, error) {
-    ctx := context.Background()
-    ni, err := toNetworkIdentifier(networkIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    ai, err := toAccountIdentifier(accountIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    req := &rotypes.AccountBalanceRequest{
-        NetworkIdentifier: ni,
-        AccountIdentifier: ai,
-    }
-    resp, _, err := m.apiClient.AccountAPI.AccountBalance(ctx, req)
-    if err != nil {
-        return nil, err
-    }
-    return wrapJSONResponse(resp)
+// AccountBalance calls /account/balance via SDK
+func (m *MeshSDKClient) AccountBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error) {
+    resp, rErr, err := m.apiClient.AccountAPI.AccountBalance(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-// Block calls /block via SDK and wraps the result
-func (m *MeshSDKClient) Block(networkIdentifier interface{}, blockIdentifier interface{}) (*http.Response, error) {
-    ctx := context.Background()
-    ni, err := toNetworkIdentifier(networkIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    pbi, err := toPartialBlockIdentifier(blockIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    req := &rotypes.BlockRequest{
-        NetworkIdentifier: ni,
-        BlockIdentifier:   pbi,
-    }
-    resp, _, err := m.apiClient.BlockAPI.Block(ctx, req)
-    if err != nil {
-        return nil, err
-    }
-    return wrapJSONResponse(resp)
+// AccountCoins calls /account/coins via SDK
+func (m *MeshSDKClient) AccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error) {
+    resp, rErr, err := m.apiClient.AccountAPI.AccountCoins(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-// BlockTransaction calls /block/transaction via SDK and wraps the result
-func (m *MeshSDKClient) BlockTransaction(networkIdentifier interface{}, blockIdentifier interface{}, transactionIdentifier interface{}) (*http.Response, error) {
-    ctx := context.Background()
-    ni, err := toNetworkIdentifier(networkIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    bi, err := toFullBlockIdentifier(blockIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    ti, err := toTransactionIdentifier(transactionIdentifier)
-    if err != nil {
-        return nil, err
-    }
-    req := &rotypes.BlockTransactionRequest{
-        NetworkIdentifier:     ni,
-        BlockIdentifier:       bi,
-        TransactionIdentifier: ti,
-    }
-    resp, _, err := m.apiClient.BlockAPI.BlockTransaction(ctx, req)
-    if err != nil {
-        return nil, err
-    }
-    return wrapJSONResponse(resp)
+// Block calls /block via SDK
+func (m *MeshSDKClient) Block(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error) {
+    resp, rErr, err := m.apiClient.BlockAPI.Block(ctx, latestBlock(request))
+    return resp, sdkError(rErr, err)
 }
 
-// Health checks the Mesh network list via SDK
-func (m *MeshSDKClient) Health() bool {
-    if _, err := m.ListNetworks(); err != nil {
-        return false
-    }
-    return true
+// BlockTransaction calls /block/transaction via SDK
+func (m *MeshSDKClient) BlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error) {
+    resp, rErr, err := m.apiClient.BlockAPI.BlockTransaction(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-// Helpers
+// Call calls /call via SDK
+func (m *MeshSDKClient) Call(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error) {
+    resp, rErr, err := m.apiClient.CallAPI.Call(ctx, request)
+    return resp, sdkError(rErr, err)
+}
 
-func wrapJSONResponse(v interface{}) (*http.Response, error) {
-    b, err := json.Marshal(v)
-    if err != nil {
-        return nil, fmt.Errorf("failed to marshal response: %w", err)
-    }
-    r := &http.Response{
-        Status:     "200 OK",
-        StatusCode: 200,
-        Header:     make(http.Header),
-        Body:       io.NopCloser(bytes.NewReader(b)),
-    }
-    r.Header.Set("Content-Type", "application/json")
-    return r, nil
+// EventsBlocks calls /events/blocks via SDK
+func (m *MeshSDKClient) EventsBlocks(ctx context.Context, request *rotypes.EventsBlocksRequest) (*rotypes.EventsBlocksResponse, error) {
+    resp, rErr, err := m.apiClient.EventsAPI.EventsBlocks(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-func toNetworkIdentifier(v interface{}) (*rotypes.NetworkIdentifier, error) {
-    switch t := v.(type) {
-    case *rotypes.NetworkIdentifier:
-        return t, nil
-    case rotypes.NetworkIdentifier:
-        return &t, nil
-    case map[string]interface{}:
-        blockchain, _ := t["blockchain"].(string)
-        network, _ := t["network"].(string)
-        if blockchain == "" || network == "" {
-            return nil, fmt.Errorf("invalid network_identifier map: missing fields")
-        }
-        return &rotypes.NetworkIdentifier{Blockchain: blockchain, Network: network}, nil
-    default:
-        return nil, fmt.Errorf("unsupported network_identifier type: %T", v)
-    }
+// SearchTransactions calls /search/transactions via SDK
+func (m *MeshSDKClient) SearchTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error) {
+    resp, rErr, err := m.apiClient.SearchAPI.SearchTransactions(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-func toAccountIdentifier(v interface{}) (*rotypes.AccountIdentifier, error) {
-    switch t := v.(type) {
-    case *rotypes.AccountIdentifier:
-        return t, nil
-    case rotypes.AccountIdentifier:
-        return &t, nil
-    case map[string]interface{}:
-        address, _ := t["address"].(string)
-        if address == "" {
-            return nil, fmt.Errorf("invalid account_identifier map: missing address")
-        }
-        return &rotypes.AccountIdentifier{Address: address}, nil
-    default:
-        return nil, fmt.Errorf("unsupported account_identifier type: %T", v)
-    }
+// Mempool calls /mempool via SDK
+func (m *MeshSDKClient) Mempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error) {
+    resp, rErr, err := m.apiClient.MempoolAPI.Mempool(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-// Converts input to PartialBlockIdentifier for /block
-func toPartialBlockIdentifier(v interface{}) (*rotypes.PartialBlockIdentifier, error) {
-    switch t := v.(type) {
-    case nil:
-        return &rotypes.PartialBlockIdentifier{}, nil
-    case *rotypes.PartialBlockIdentifier:
-        return t, nil
-    case rotypes.PartialBlockIdentifier:
-        return &t, nil
-    case map[string]interface{}:
-        var (
-            idxPtr *int64
-            hashStr string
-        )
-        if raw, ok := t["index"]; ok {
-            switch n := raw.(type) {
-            case float64:
-                v := int64(n)
-                idxPtr = &v
-            case int64:
-                v := n
-                idxPtr = &v
-            case int:
-                v := int64(n)
-                idxPtr = &v
-            }
-        }
-        if h, ok := t["hash"].(string); ok {
-            hashStr = h
-        }
-        pbi := &rotypes.PartialBlockIdentifier{}
-        if idxPtr != nil {
-            pbi.Index = idxPtr
-        }
-        if hashStr != "" {
-            pbi.Hash = &hashStr
-        }
-        return pbi, nil
-    default:
-        return nil, fmt.Errorf("unsupported block_identifier type for /block: %T", v)
-    }
+// MempoolTransaction calls /mempool/transaction via SDK
+func (m *MeshSDKClient) MempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error) {
+    resp, rErr, err := m.apiClient.MempoolAPI.MempoolTransaction(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionDerive calls /construction/derive via SDK
+func (m *MeshSDKClient) ConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionDerive(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionPreprocess calls /construction/preprocess via SDK
+func (m *MeshSDKClient) ConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionPreprocess(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionMetadata calls /construction/metadata via SDK
+func (m *MeshSDKClient) ConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionMetadata(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionPayloads calls /construction/payloads via SDK
+func (m *MeshSDKClient) ConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionPayloads(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionCombine calls /construction/combine via SDK
+func (m *MeshSDKClient) ConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionCombine(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionParse calls /construction/parse via SDK
+func (m *MeshSDKClient) ConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionParse(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionHash calls /construction/hash via SDK
+func (m *MeshSDKClient) ConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionHash(ctx, request)
+    return resp, sdkError(rErr, err)
+}
+
+// ConstructionSubmit calls /construction/submit via SDK
+func (m *MeshSDKClient) ConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error) {
+    resp, rErr, err := m.apiClient.ConstructionAPI.ConstructionSubmit(ctx, request)
+    return resp, sdkError(rErr, err)
 }
 
-// Converts input to BlockIdentifier for /block/transaction
-func toFullBlockIdentifier(v interface{}) (*rotypes.BlockIdentifier, error) {
-    switch t := v.(type) {
-    case *rotypes.BlockIdentifier:
-        return t, nil
-    case rotypes.BlockIdentifier:
-        return &t, nil
-    case map[string]interface{}:
-        var (
-            idx int64
-            hasIdx bool
-            hash string
-        )
-        if raw, ok := t["index"]; ok {
-            switch n := raw.(type) {
-            case float64:
-                idx = int64(n)
-                hasIdx = true
-            case int64:
-                idx = n
-                hasIdx = true
-            case int:
-                idx = int64(n)
-                hasIdx = true
-            }
-        }
-        if h, ok := t["hash"].(string); ok {
-            hash = h
-        }
-        // If neither provided, return empty identifier error
-        if !hasIdx && hash == "" {
-            return nil, fmt.Errorf("invalid block_identifier map: require index and/or hash")
-        }
-        return &rotypes.BlockIdentifier{Index: idx, Hash: hash}, nil
-    default:
-        return nil, fmt.Errorf("unsupported block_identifier type for /block/transaction: %T", v)
+// Health checks the Mesh network list via SDK
+func (m *MeshSDKClient) Health(ctx context.Context) bool {
+    if _, err := m.NetworkList(ctx); err != nil {
+        return false
     }
+    return true
 }
 
-func toTransactionIdentifier(v interface{}) (*rotypes.TransactionIdentifier, error) {
-    switch t := v.(type) {
-    case *rotypes.TransactionIdentifier:
-        return t, nil
-    case rotypes.TransactionIdentifier:
-        return &t, nil
-    case map[string]interface{}:
-        hash, _ := t["hash"].(string)
-        if hash == "" {
-            return nil, fmt.Errorf("invalid transaction_identifier map: missing hash")
-        }
-        return &rotypes.TransactionIdentifier{Hash: hash}, nil
-    default:
-        return nil, fmt.Errorf("unsupported transaction_identifier type: %T", v)
+// Helpers
+
+// sdkError turns the SDK's error pair into one error. The SDK only decodes Rosetta errors from
+// 500 responses.
+func sdkError(rErr *rotypes.Error, err error) error {
+    if rErr != nil {
+        return &MeshError{StatusCode: http.StatusInternalServerError, Err: rErr}
     }
-}
\ No newline at end of file
+    return err
+}
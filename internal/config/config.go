@@ -28,6 +28,20 @@ type Config struct {
 	OverledgerAuthURL      string
 	OverledgerBaseURL      string
 	OverledgerTxSigningKeyID string
+	// OverledgerNonceFile persists the nonces handed out per network and
+	// address; empty keeps them in memory
+	OverledgerNonceFile string
+	// OverledgerRPCURLs maps Overledger location networks to comma-separated
+	// JSON-RPC endpoints used to sync nonces
+	OverledgerRPCURLs map[string]string
+	// OverledgerSigner selects how transactions are signed: "sandbox"
+	// (default), "local" with the key in OverledgerSignerKeyFile, or "remote"
+	// through the service at OverledgerSignerURL
+	OverledgerSigner           string
+	OverledgerSignerKeyFile    string
+	OverledgerSignerPassphrase string
+	OverledgerSignerURL        string
+	OverledgerSignerToken      string
 }
 
 // LoadConfig loads configuration from environment variables
@@ -72,9 +86,34 @@ func LoadConfig() *Config {
 		OverledgerAuthURL:      getEnv("OVERLEDGER_AUTH_URL", "https://auth.overledger.dev/oauth2/token"),
 		OverledgerBaseURL:      getEnv("OVERLEDGER_BASE_URL", "https://api.overledger.dev"),
 		OverledgerTxSigningKeyID: getEnv("OVERLEDGER_TX_SIGNING_KEY_ID", ""),
+		OverledgerNonceFile:      getEnv("OVERLEDGER_NONCE_FILE", "overledger-nonces.json"),
+		OverledgerRPCURLs:        overledgerRPCURLs(),
+		OverledgerSigner:           getEnv("OVERLEDGER_SIGNER", "sandbox"),
+		OverledgerSignerKeyFile:    getEnv("OVERLEDGER_SIGNER_KEY_FILE", ""),
+		OverledgerSignerPassphrase: getEnv("OVERLEDGER_SIGNER_PASSPHRASE", ""),
+		OverledgerSignerURL:        getEnv("OVERLEDGER_SIGNER_URL", ""),
+		OverledgerSignerToken:      getEnv("OVERLEDGER_SIGNER_TOKEN", ""),
 	}
 }
 
+// overledgerRPCURLs reads the RPC endpoints shared with the embedded Mesh
+// networks, keyed by the Overledger location network they serve
+func overledgerRPCURLs() map[string]string {
+	sepolia := getEnv("SEPOLIA_RPC_URL", getEnv("INFURA_RPC_URL", getEnv("ETH_RPC_URL", "")))
+	urls := map[string]string{
+		"ethereum mainnet":         getEnv("MAINNET_RPC_URL", ""),
+		"ethereum sepolia testnet": sepolia,
+		"ethereum holesky testnet": getEnv("HOLESKY_RPC_URL", ""),
+		"polygon amoy testnet":     getEnv("AMOY_RPC_URL", ""),
+	}
+	for network, u := range urls {
+		if u == "" {
+			delete(urls, network)
+		}
+	}
+	return urls
+}
+
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -1,15 +1,21 @@
 package connector
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+
 	"github.com/rutishh0/testingquant/internal/adapters/coinbase"
 	"github.com/rutishh0/testingquant/internal/adapters/mesh"
 	"github.com/rutishh0/testingquant/internal/models"
 	"github.com/rutishh0/testingquant/internal/overledger"
 )
 
+// ErrMeshNotInitialized is returned by the Mesh operations when the service has no Mesh adapter.
+var ErrMeshNotInitialized = errors.New("mesh adapter not initialized")
+
 // Request/Response types
 type CreateCoinbaseWalletRequest struct {
 	Name string `json:"name"`
@@ -30,6 +36,8 @@ type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	// MeshError is the Rosetta error returned by Mesh, if any
+	MeshError *rotypes.Error `json:"mesh_error,omitempty"`
 }
 
 type StatusResponse struct {
@@ -68,12 +76,26 @@ type Service interface {
 	GetCoinbaseTransactionsPaginated(walletID string, limit int, cursor string) (*models.CoinbaseTransactionsPaginatedResponse, error)
 	GetCoinbaseAssets() ([]*models.CoinbaseAsset, error)
 	GetCoinbaseNetworks() ([]*models.CoinbaseNetwork, error)
-	// Mesh operations
-	GetMeshNetworks() (*models.MeshNetworksResponse, error)
-	GetMeshNetworkBalance(networkIdentifier, accountIdentifier interface{}) (*models.MeshBalanceResponse, error)
-	// New Mesh methods for blocks and transactions
-	GetMeshBlock(networkIdentifier, blockIdentifier interface{}) (map[string]interface{}, error)
-	GetMeshBlockTransaction(networkIdentifier, blockIdentifier, transactionIdentifier interface{}) (map[string]interface{}, error)
+	// Mesh operations, on the Rosetta request and response types
+	GetMeshNetworks(ctx context.Context) (*models.MeshNetworksResponse, error)
+	GetMeshNetworkBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error)
+	GetMeshBlock(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error)
+	GetMeshBlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error)
+	CallMesh(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error)
+	SearchMeshTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error)
+	GetMeshNetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error)
+	GetMeshNetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error)
+	GetMeshAccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error)
+	GetMeshMempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error)
+	GetMeshMempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error)
+	MeshConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error)
+	MeshConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error)
+	MeshConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error)
+	MeshConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error)
+	MeshConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error)
+	MeshConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error)
+	MeshConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error)
+	MeshConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error)
 	GetCoinbaseExchangeRates(baseCurrency string) (*models.CoinbaseExchangeRates, error)
 	EstimateCoinbaseTransactionFee(walletID string, req *EstimateFeeRequest) (*models.CoinbaseFeeEstimate, error)
 	
@@ -81,6 +103,7 @@ type Service interface {
 	GetOverledgerNetworks() (*overledger.NetworksResponse, error)
 	GetOverledgerBalance(networkID, address string) (*overledger.BalanceResponse, error)
 	CreateOverledgerTransaction(req *overledger.TransactionRequest) (*overledger.TransactionResponse, error)
+	EstimateOverledgerFees(req *overledger.FeeEstimateRequest) (*overledger.FeeEstimateResponse, error)
 	GetOverledgerTransactionStatus(networkID, txHash string) (*overledger.TransactionStatusResponse, error)
 	TestOverledgerConnection() error
 
@@ -209,35 +232,155 @@ func (s *service) GetOverledgerNetworks() (*overledger.NetworksResponse, error)
 }
 
 // Mesh operations
-func (s *service) GetMeshNetworks() (*models.MeshNetworksResponse, error) {
+func (s *service) GetMeshNetworks(ctx context.Context) (*models.MeshNetworksResponse, error) {
 	if s.meshAdapter == nil {
-		return nil, errors.New("mesh adapter not initialized")
+		return nil, ErrMeshNotInitialized
 	}
-	return s.meshAdapter.ListNetworks()
+	return s.meshAdapter.ListNetworks(ctx)
 }
 
 // GetMeshNetworkBalance retrieves the balance of an account on a specified network
-func (s *service) GetMeshNetworkBalance(networkIdentifier, accountIdentifier interface{}) (*models.MeshBalanceResponse, error) {
+func (s *service) GetMeshNetworkBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.AccountBalance(ctx, request)
+}
+
+// GetMeshBlock retrieves a block by identifier on a specified network
+func (s *service) GetMeshBlock(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.Block(ctx, request)
+}
+
+// GetMeshBlockTransaction retrieves a transaction by identifier within a block on a specified network
+func (s *service) GetMeshBlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.BlockTransaction(ctx, request)
+}
+
+// CallMesh runs a read-only call, such as eth_call or an ERC-20 metadata lookup, on a specified network
+func (s *service) CallMesh(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.Call(ctx, request)
+}
+
+// SearchMeshTransactions searches a network's indexed transactions by account, currency, status, type and block
+func (s *service) SearchMeshTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.SearchTransactions(ctx, request)
+}
+
+// GetMeshNetworkStatus retrieves the current block, sync status and peers of a network
+func (s *service) GetMeshNetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.NetworkStatus(ctx, request)
+}
+
+// GetMeshNetworkOptions retrieves the versions, operation types and errors a network supports
+func (s *service) GetMeshNetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.NetworkOptions(ctx, request)
+}
+
+// GetMeshAccountCoins retrieves the unspent coins of an account on a UTXO network
+func (s *service) GetMeshAccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.AccountCoins(ctx, request)
+}
+
+// GetMeshMempool lists the pending transactions of a network
+func (s *service) GetMeshMempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error) {
 	if s.meshAdapter == nil {
-		return nil, errors.New("mesh adapter not initialized")
+		return nil, ErrMeshNotInitialized
 	}
-	return s.meshAdapter.AccountBalance(networkIdentifier, accountIdentifier)
+	return s.meshAdapter.Mempool(ctx, request)
 }
 
-// New: GetMeshBlock retrieves a block by identifier on a specified network
-func (s *service) GetMeshBlock(networkIdentifier, blockIdentifier interface{}) (map[string]interface{}, error) {
+// GetMeshMempoolTransaction retrieves a pending transaction by hash
+func (s *service) GetMeshMempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error) {
 	if s.meshAdapter == nil {
-		return nil, errors.New("mesh adapter not initialized")
+		return nil, ErrMeshNotInitialized
 	}
-	return s.meshAdapter.Block(networkIdentifier, blockIdentifier)
+	return s.meshAdapter.MempoolTransaction(ctx, request)
 }
 
-// New: GetMeshBlockTransaction retrieves a transaction by identifier within a block on a specified network
-func (s *service) GetMeshBlockTransaction(networkIdentifier, blockIdentifier, transactionIdentifier interface{}) (map[string]interface{}, error) {
+// MeshConstructionDerive derives an account identifier from a public key
+func (s *service) MeshConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error) {
 	if s.meshAdapter == nil {
-		return nil, errors.New("mesh adapter not initialized")
+		return nil, ErrMeshNotInitialized
 	}
-	return s.meshAdapter.BlockTransaction(networkIdentifier, blockIdentifier, transactionIdentifier)
+	return s.meshAdapter.ConstructionDerive(ctx, request)
+}
+
+// MeshConstructionPreprocess returns the metadata options for a set of operations
+func (s *service) MeshConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.ConstructionPreprocess(ctx, request)
+}
+
+// MeshConstructionMetadata fetches the nonce, gas and fee metadata needed to build a transaction
+func (s *service) MeshConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.ConstructionMetadata(ctx, request)
+}
+
+// MeshConstructionPayloads builds an unsigned transaction and its signing payloads
+func (s *service) MeshConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.ConstructionPayloads(ctx, request)
+}
+
+// MeshConstructionCombine attaches signatures to an unsigned transaction
+func (s *service) MeshConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.ConstructionCombine(ctx, request)
+}
+
+// MeshConstructionParse decodes the operations of a signed or unsigned transaction
+func (s *service) MeshConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.ConstructionParse(ctx, request)
+}
+
+// MeshConstructionHash returns the hash of a signed transaction
+func (s *service) MeshConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.ConstructionHash(ctx, request)
+}
+
+// MeshConstructionSubmit broadcasts a signed transaction
+func (s *service) MeshConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error) {
+	if s.meshAdapter == nil {
+		return nil, ErrMeshNotInitialized
+	}
+	return s.meshAdapter.ConstructionSubmit(ctx, request)
 }
 
 func (s *service) GetOverledgerBalance(networkID, address string) (*overledger.BalanceResponse, error) {
@@ -254,6 +397,13 @@ func (s *service) CreateOverledgerTransaction(req *overledger.TransactionRequest
 	return s.overledgerClient.CreateTransaction(req)
 }
 
+func (s *service) EstimateOverledgerFees(req *overledger.FeeEstimateRequest) (*overledger.FeeEstimateResponse, error) {
+	if s.overledgerClient == nil {
+		return nil, errors.New("overledger client not initialized")
+	}
+	return s.overledgerClient.EstimateFees(req)
+}
+
 func (s *service) GetOverledgerTransactionStatus(networkID, txHash string) (*overledger.TransactionStatusResponse, error) {
 	if s.overledgerClient == nil {
 		return nil, errors.New("overledger client not initialized")
@@ -294,7 +444,7 @@ func (s *service) HealthCheck() (*HealthResponse, error) {
 	if s.meshAdapter != nil {
 		meshHealthy := "healthy"
 		meshMsg := ""
-		if _, err := s.meshAdapter.ListNetworks(); err != nil {
+		if _, err := s.meshAdapter.ListNetworks(context.Background()); err != nil {
 			meshHealthy = "unhealthy"
 			meshMsg = err.Error()
 		}
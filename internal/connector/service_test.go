@@ -1,8 +1,11 @@
 package connector
 
 import (
+	"context"
 	"testing"
 
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+
 	"github.com/rutishh0/testingquant/internal/adapters/coinbase"
 	"github.com/rutishh0/testingquant/internal/adapters/mesh"
 	"github.com/rutishh0/testingquant/internal/clients"
@@ -104,24 +107,86 @@ func TestOverledgerOperations(t *testing.T) {
 type mockMeshAdapter struct {
 	networksResp *models.MeshNetworksResponse
 	networksErr  error
-	balanceResp  *models.MeshBalanceResponse
+	balanceResp  *rotypes.AccountBalanceResponse
 	balanceErr   error
 	health       bool
 }
 
-func (m *mockMeshAdapter) ListNetworks() (*models.MeshNetworksResponse, error) { return m.networksResp, m.networksErr }
-func (m *mockMeshAdapter) AccountBalance(networkIdentifier, accountIdentifier interface{}) (*models.MeshBalanceResponse, error) {
+func (m *mockMeshAdapter) ListNetworks(ctx context.Context) (*models.MeshNetworksResponse, error) {
+	return m.networksResp, m.networksErr
+}
+func (m *mockMeshAdapter) AccountBalance(ctx context.Context, request *rotypes.AccountBalanceRequest) (*rotypes.AccountBalanceResponse, error) {
 	return m.balanceResp, m.balanceErr
 }
-func (m *mockMeshAdapter) Health() bool { return m.health }
+func (m *mockMeshAdapter) Health(ctx context.Context) bool { return m.health }
 
 // Satisfy new mesh.Adapter interface additions
-func (m *mockMeshAdapter) Block(networkIdentifier, blockIdentifier interface{}) (map[string]interface{}, error) {
-	return map[string]interface{}{}, nil
+func (m *mockMeshAdapter) Block(ctx context.Context, request *rotypes.BlockRequest) (*rotypes.BlockResponse, error) {
+	return &rotypes.BlockResponse{}, nil
+}
+
+func (m *mockMeshAdapter) BlockTransaction(ctx context.Context, request *rotypes.BlockTransactionRequest) (*rotypes.BlockTransactionResponse, error) {
+	return &rotypes.BlockTransactionResponse{}, nil
+}
+
+func (m *mockMeshAdapter) Call(ctx context.Context, request *rotypes.CallRequest) (*rotypes.CallResponse, error) {
+	return &rotypes.CallResponse{}, nil
+}
+
+func (m *mockMeshAdapter) SearchTransactions(ctx context.Context, request *rotypes.SearchTransactionsRequest) (*rotypes.SearchTransactionsResponse, error) {
+	return &rotypes.SearchTransactionsResponse{}, nil
+}
+
+func (m *mockMeshAdapter) NetworkStatus(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkStatusResponse, error) {
+	return &rotypes.NetworkStatusResponse{}, nil
+}
+
+func (m *mockMeshAdapter) NetworkOptions(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.NetworkOptionsResponse, error) {
+	return &rotypes.NetworkOptionsResponse{}, nil
+}
+
+func (m *mockMeshAdapter) AccountCoins(ctx context.Context, request *rotypes.AccountCoinsRequest) (*rotypes.AccountCoinsResponse, error) {
+	return &rotypes.AccountCoinsResponse{}, nil
+}
+
+func (m *mockMeshAdapter) Mempool(ctx context.Context, request *rotypes.NetworkRequest) (*rotypes.MempoolResponse, error) {
+	return &rotypes.MempoolResponse{}, nil
+}
+
+func (m *mockMeshAdapter) MempoolTransaction(ctx context.Context, request *rotypes.MempoolTransactionRequest) (*rotypes.MempoolTransactionResponse, error) {
+	return &rotypes.MempoolTransactionResponse{}, nil
+}
+
+func (m *mockMeshAdapter) ConstructionDerive(ctx context.Context, request *rotypes.ConstructionDeriveRequest) (*rotypes.ConstructionDeriveResponse, error) {
+	return &rotypes.ConstructionDeriveResponse{}, nil
+}
+
+func (m *mockMeshAdapter) ConstructionPreprocess(ctx context.Context, request *rotypes.ConstructionPreprocessRequest) (*rotypes.ConstructionPreprocessResponse, error) {
+	return &rotypes.ConstructionPreprocessResponse{}, nil
+}
+
+func (m *mockMeshAdapter) ConstructionMetadata(ctx context.Context, request *rotypes.ConstructionMetadataRequest) (*rotypes.ConstructionMetadataResponse, error) {
+	return &rotypes.ConstructionMetadataResponse{}, nil
+}
+
+func (m *mockMeshAdapter) ConstructionPayloads(ctx context.Context, request *rotypes.ConstructionPayloadsRequest) (*rotypes.ConstructionPayloadsResponse, error) {
+	return &rotypes.ConstructionPayloadsResponse{}, nil
 }
 
-func (m *mockMeshAdapter) BlockTransaction(networkIdentifier, blockIdentifier, transactionIdentifier interface{}) (map[string]interface{}, error) {
-	return map[string]interface{}{}, nil
+func (m *mockMeshAdapter) ConstructionCombine(ctx context.Context, request *rotypes.ConstructionCombineRequest) (*rotypes.ConstructionCombineResponse, error) {
+	return &rotypes.ConstructionCombineResponse{}, nil
+}
+
+func (m *mockMeshAdapter) ConstructionParse(ctx context.Context, request *rotypes.ConstructionParseRequest) (*rotypes.ConstructionParseResponse, error) {
+	return &rotypes.ConstructionParseResponse{}, nil
+}
+
+func (m *mockMeshAdapter) ConstructionHash(ctx context.Context, request *rotypes.ConstructionHashRequest) (*rotypes.TransactionIdentifierResponse, error) {
+	return &rotypes.TransactionIdentifierResponse{}, nil
+}
+
+func (m *mockMeshAdapter) ConstructionSubmit(ctx context.Context, request *rotypes.ConstructionSubmitRequest) (*rotypes.TransactionIdentifierResponse, error) {
+	return &rotypes.TransactionIdentifierResponse{}, nil
 }
 
 func TestService_GetMeshNetworks_Success(t *testing.T) {
@@ -140,7 +205,7 @@ func TestService_GetMeshNetworks_Success(t *testing.T) {
 		},
 	}}}
 
-	resp, err := s.GetMeshNetworks()
+	resp, err := s.GetMeshNetworks(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Len(t, resp.Networks, 1)
@@ -150,32 +215,32 @@ func TestService_GetMeshNetworks_Success(t *testing.T) {
 
 func TestService_GetMeshNetworks_Error(t *testing.T) {
 	s := &service{meshAdapter: &mockMeshAdapter{networksErr: assert.AnError}}
-	resp, err := s.GetMeshNetworks()
+	resp, err := s.GetMeshNetworks(context.Background())
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 }
 
 func TestService_GetMeshNetworks_NilAdapter(t *testing.T) {
 	s := &service{meshAdapter: nil}
-	resp, err := s.GetMeshNetworks()
+	resp, err := s.GetMeshNetworks(context.Background())
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "mesh adapter not initialized")
 	assert.Nil(t, resp)
 }
 
+var balanceRequest = &rotypes.AccountBalanceRequest{
+	NetworkIdentifier: &rotypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"},
+	AccountIdentifier: &rotypes.AccountIdentifier{Address: "0xabc"},
+}
+
 func TestService_GetMeshNetworkBalance_Success(t *testing.T) {
-	balance := &models.MeshBalanceResponse{Balances: []models.MeshBalance{{
-		Value: "1000000000000000000",
-		Currency: struct {
-			Symbol   string `json:"symbol"`
-			Decimals int    `json:"decimals"`
-		}{Symbol: "ETH", Decimals: 18},
+	balance := &rotypes.AccountBalanceResponse{Balances: []*rotypes.Amount{{
+		Value:    "1000000000000000000",
+		Currency: &rotypes.Currency{Symbol: "ETH", Decimals: 18},
 	}}}
 	s := &service{meshAdapter: &mockMeshAdapter{balanceResp: balance}}
 
-	networkID := map[string]string{"blockchain": "Ethereum", "network": "Sepolia"}
-	accountID := map[string]string{"address": "0xabc"}
-	resp, err := s.GetMeshNetworkBalance(networkID, accountID)
+	resp, err := s.GetMeshNetworkBalance(context.Background(), balanceRequest)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Len(t, resp.Balances, 1)
@@ -184,7 +249,7 @@ func TestService_GetMeshNetworkBalance_Success(t *testing.T) {
 
 func TestService_GetMeshNetworkBalance_Error(t *testing.T) {
 	s := &service{meshAdapter: &mockMeshAdapter{balanceErr: assert.AnError}}
-	resp, err := s.GetMeshNetworkBalance(map[string]string{"b": "c"}, map[string]string{"a": "b"})
+	resp, err := s.GetMeshNetworkBalance(context.Background(), balanceRequest)
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 }
\ No newline at end of file
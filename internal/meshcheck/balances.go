@@ -0,0 +1,80 @@
+package meshcheck
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// balanceTracker holds the balances computed from operations, per account
+// and currency.
+type balanceTracker struct {
+	entries map[string]*trackedBalance
+}
+
+type trackedBalance struct {
+	account  *rotypes.AccountIdentifier
+	currency *rotypes.Currency
+	value    *big.Int
+}
+
+func newBalanceTracker() *balanceTracker {
+	return &balanceTracker{entries: map[string]*trackedBalance{}}
+}
+
+func accountCurrencyKey(account *rotypes.AccountIdentifier, currency *rotypes.Currency) string {
+	return rotypes.Hash(account) + "/" + rotypes.Hash(currency)
+}
+
+func (b *balanceTracker) tracked(account *rotypes.AccountIdentifier, currency *rotypes.Currency) bool {
+	_, ok := b.entries[accountCurrencyKey(account, currency)]
+	return ok
+}
+
+func (b *balanceTracker) set(account *rotypes.AccountIdentifier, currency *rotypes.Currency, value *big.Int) {
+	b.entries[accountCurrencyKey(account, currency)] = &trackedBalance{account: account, currency: currency, value: new(big.Int).Set(value)}
+}
+
+// add applies amount to the balance of account, which must be tracked.
+func (b *balanceTracker) add(account *rotypes.AccountIdentifier, amount *rotypes.Amount) error {
+	entry, ok := b.entries[accountCurrencyKey(account, amount.Currency)]
+	if !ok {
+		return fmt.Errorf("%s %s is not tracked", account.Address, amount.Currency.Symbol)
+	}
+	value, err := rotypes.AmountValue(amount)
+	if err != nil {
+		return err
+	}
+	entry.value.Add(entry.value, value)
+	return nil
+}
+
+// get returns a copy of the balance, or nil when it is not tracked.
+func (b *balanceTracker) get(account *rotypes.AccountIdentifier, currency *rotypes.Currency) *big.Int {
+	entry, ok := b.entries[accountCurrencyKey(account, currency)]
+	if !ok {
+		return nil
+	}
+	return new(big.Int).Set(entry.value)
+}
+
+func (b *balanceTracker) len() int {
+	return len(b.entries)
+}
+
+// accounts lists the tracked account and currency pairs in a stable order.
+func (b *balanceTracker) accounts() []*AccountCurrency {
+	keys := make([]string, 0, len(b.entries))
+	for key := range b.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]*AccountCurrency, 0, len(keys))
+	for _, key := range keys {
+		entry := b.entries[key]
+		out = append(out, &AccountCurrency{Account: entry.account, Currency: entry.currency})
+	}
+	return out
+}
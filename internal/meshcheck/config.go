@@ -0,0 +1,143 @@
+// Package meshcheck runs rosetta-cli style checks against a Mesh (Rosetta)
+// endpoint, driven by the rosetta-cli configuration in config/mesh-cli-config.json.
+package meshcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Config is the subset of the rosetta-cli configuration file used by the checks.
+type Config struct {
+	Network      *rotypes.NetworkIdentifier `json:"network"`
+	OnlineURL    string                     `json:"online_url"`
+	Data         DataConfig                 `json:"data"`
+	Construction ConstructionConfig         `json:"construction"`
+
+	// dir is the directory of the configuration file; relative account
+	// file paths that are not found from the working directory are tried
+	// from there.
+	dir string
+}
+
+// DataConfig configures check:data.
+type DataConfig struct {
+	StartIndex int64 `json:"start_index"`
+	// EndIndex is the last block checked; nil checks up to the current head.
+	EndIndex *int64 `json:"end_index,omitempty"`
+
+	ActiveReconciliationConcurrency int `json:"active_reconciliation_concurrency"`
+	// InactiveReconciliationFrequency is how many blocks pass between
+	// reconciliations of accounts that did not change; zero only reconciles
+	// them once the range is synced.
+	InactiveReconciliationFrequency int64 `json:"inactive_reconciliation_frequency"`
+	IgnoreReconciliationError       bool  `json:"ignore_reconciliation_error"`
+	ReconciliationDisabled          bool  `json:"reconciliation_disabled"`
+	BalanceTrackingDisabled         bool  `json:"balance_tracking_disabled"`
+	LogBlocks                       bool  `json:"log_blocks"`
+	LogReconciliation               bool  `json:"log_reconciliation"`
+
+	ExemptAccounts      string `json:"exempt_accounts"`
+	BootstrapBalances   string `json:"bootstrap_balances"`
+	InterestingAccounts string `json:"interesting_accounts"`
+}
+
+// ConstructionConfig configures check:construction.
+type ConstructionConfig struct {
+	// OfflineURL serves the construction endpoints that need no node; it
+	// defaults to the online URL.
+	OfflineURL string `json:"offline_url"`
+	// StaleDepth is how many blocks a broadcast may go unconfirmed before it
+	// is broadcast again, at most BroadcastLimit times in total.
+	StaleDepth     int64 `json:"stale_depth"`
+	BroadcastLimit int   `json:"broadcast_limit"`
+	// EndConditions maps create_account and transfer to the number of each
+	// the check must complete.
+	EndConditions     map[string]int      `json:"end_conditions"`
+	PrefundedAccounts []*PrefundedAccount `json:"prefunded_accounts"`
+}
+
+// PrefundedAccount is a funded account the construction check signs for.
+type PrefundedAccount struct {
+	PrivateKey string                     `json:"privkey"`
+	Account    *rotypes.AccountIdentifier `json:"account_identifier"`
+	CurveType  rotypes.CurveType          `json:"curve_type"`
+	Currency   *rotypes.Currency          `json:"currency"`
+}
+
+// AccountCurrency is an entry of exempt_accounts.json or interesting_accounts.json.
+type AccountCurrency struct {
+	Account  *rotypes.AccountIdentifier `json:"account_identifier"`
+	Currency *rotypes.Currency          `json:"currency"`
+}
+
+// BootstrapBalance is an entry of bootstrap_balances.json: a balance held
+// before the genesis block's operations are applied.
+type BootstrapBalance struct {
+	Account  *rotypes.AccountIdentifier `json:"account_identifier"`
+	Currency *rotypes.Currency          `json:"currency"`
+	Value    string                     `json:"value"`
+}
+
+// LoadConfig reads a rosetta-cli configuration file.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if cfg.Network == nil || cfg.Network.Blockchain == "" || cfg.Network.Network == "" {
+		return nil, fmt.Errorf("config %s: network.blockchain and network.network are required", path)
+	}
+	if cfg.OnlineURL == "" {
+		return nil, fmt.Errorf("config %s: online_url is required", path)
+	}
+	cfg.dir = filepath.Dir(path)
+	return &cfg, nil
+}
+
+// ExemptAccounts loads data.exempt_accounts; accounts listed there are not
+// reconciled.
+func (c *Config) ExemptAccounts() ([]*AccountCurrency, error) {
+	var out []*AccountCurrency
+	return out, c.readList(c.Data.ExemptAccounts, &out)
+}
+
+// InterestingAccounts loads data.interesting_accounts; accounts listed there
+// are reconciled after every block.
+func (c *Config) InterestingAccounts() ([]*AccountCurrency, error) {
+	var out []*AccountCurrency
+	return out, c.readList(c.Data.InterestingAccounts, &out)
+}
+
+// BootstrapBalances loads data.bootstrap_balances.
+func (c *Config) BootstrapBalances() ([]*BootstrapBalance, error) {
+	var out []*BootstrapBalance
+	return out, c.readList(c.Data.BootstrapBalances, &out)
+}
+
+// readList decodes the JSON file at path into out; an empty path leaves out
+// empty.
+func (c *Config) readList(path string, out interface{}) error {
+	if path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !filepath.IsAbs(path) && c.dir != "" {
+		b, err = os.ReadFile(filepath.Join(c.dir, filepath.Base(path)))
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,479 @@
+package meshcheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/rutishh0/testingquant/internal/clients"
+)
+
+// End conditions understood by ConstructionChecker.
+const (
+	EndConditionCreateAccount = "create_account"
+	EndConditionTransfer      = "transfer"
+)
+
+// ErrNotConfirmed is returned by ConstructionChecker.Run when a submitted
+// transaction does not appear in a block after construction.broadcast_limit
+// broadcasts.
+var ErrNotConfirmed = errors.New("transaction not confirmed")
+
+// transferValue is the amount, in base units, of every transfer the check
+// makes; it is kept small so prefunded accounts last.
+var transferValue = big.NewInt(1_000_000_000)
+
+// ConfirmedTransaction is a transfer the check submitted and found in a block.
+type ConfirmedTransaction struct {
+	Transaction *rotypes.TransactionIdentifier
+	Block       *rotypes.BlockIdentifier
+	From, To    string
+	Broadcasts  int
+}
+
+// ConstructionResult summarizes a check:construction run.
+type ConstructionResult struct {
+	// CreatedAccounts are the addresses derived for generated keys.
+	CreatedAccounts []string
+	Transfers       []*ConfirmedTransaction
+}
+
+// ConstructionChecker implements rosetta-cli check:construction for
+// transfers: it derives new accounts, builds transfers from the prefunded
+// accounts through preprocess, metadata, payloads, parse, combine and hash,
+// signs them locally, submits them and waits for each to appear in a block.
+type ConstructionChecker struct {
+	online  clients.MeshAPIV2
+	offline clients.MeshAPIV2
+	cfg     *Config
+	// PollInterval is how long to wait for a new block while confirming a
+	// transaction; it defaults to two seconds.
+	PollInterval time.Duration
+	// Logf, when set, receives a line for every step of the run.
+	Logf func(format string, args ...interface{})
+}
+
+// NewConstructionChecker creates a construction checker for cfg's network.
+// online serves metadata, submit and blocks; offline serves the remaining
+// construction endpoints and may be the same client.
+func NewConstructionChecker(online, offline clients.MeshAPIV2, cfg *Config) *ConstructionChecker {
+	return &ConstructionChecker{online: online, offline: offline, cfg: cfg, PollInterval: 2 * time.Second}
+}
+
+// signer is an account the check holds the key for.
+type signer struct {
+	key      *ecdsa.PrivateKey
+	account  *rotypes.AccountIdentifier
+	currency *rotypes.Currency
+}
+
+// constructionRun is the state of one Run.
+type constructionRun struct {
+	*ConstructionChecker
+	result    *ConstructionResult
+	prefunded []*signer
+	created   []*rotypes.AccountIdentifier
+}
+
+// Run creates accounts and makes transfers until construction.end_conditions
+// are met. Rejected requests, transactions that parse to different
+// operations, mismatched hashes and transfers that are never confirmed stop
+// the run with an error.
+func (c *ConstructionChecker) Run(ctx context.Context) (*ConstructionResult, error) {
+	cfg := c.cfg.Construction
+	wantAccounts := cfg.EndConditions[EndConditionCreateAccount]
+	wantTransfers := cfg.EndConditions[EndConditionTransfer]
+	for name := range cfg.EndConditions {
+		if name != EndConditionCreateAccount && name != EndConditionTransfer {
+			return nil, fmt.Errorf("unsupported end condition %q", name)
+		}
+	}
+	if wantAccounts == 0 && wantTransfers == 0 {
+		return nil, errors.New("construction.end_conditions sets nothing to do")
+	}
+
+	r := &constructionRun{ConstructionChecker: c, result: &ConstructionResult{}}
+	if err := r.loadPrefunded(ctx); err != nil {
+		return r.result, err
+	}
+	if wantTransfers > 0 && len(r.prefunded) == 0 {
+		return r.result, errors.New("transfers need at least one construction.prefunded_accounts entry")
+	}
+
+	for len(r.result.CreatedAccounts) < wantAccounts || len(r.result.Transfers) < wantTransfers {
+		if len(r.result.CreatedAccounts) < wantAccounts {
+			if err := r.createAccount(ctx); err != nil {
+				return r.result, fmt.Errorf("create_account: %w", err)
+			}
+		}
+		if len(r.result.Transfers) < wantTransfers {
+			from := r.prefunded[len(r.result.Transfers)%len(r.prefunded)]
+			if err := r.transfer(ctx, from, r.recipient(from)); err != nil {
+				return r.result, fmt.Errorf("transfer: %w", err)
+			}
+		}
+	}
+	return r.result, nil
+}
+
+// loadPrefunded parses the prefunded keys and checks that each derives to
+// its configured account.
+func (r *constructionRun) loadPrefunded(ctx context.Context) error {
+	for i, p := range r.cfg.Construction.PrefundedAccounts {
+		if p.CurveType != rotypes.Secp256k1 {
+			return fmt.Errorf("prefunded account %d: unsupported curve %q", i, p.CurveType)
+		}
+		if p.Account == nil || p.Currency == nil {
+			return fmt.Errorf("prefunded account %d: account_identifier and currency are required", i)
+		}
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(p.PrivateKey, "0x"))
+		if err != nil {
+			return fmt.Errorf("prefunded account %d: invalid privkey: %w", i, err)
+		}
+		account, err := r.derive(ctx, key)
+		if err != nil {
+			return fmt.Errorf("prefunded account %d: %w", i, err)
+		}
+		if !strings.EqualFold(account.Address, p.Account.Address) {
+			return fmt.Errorf("prefunded account %d: privkey derives %s, not %s", i, account.Address, p.Account.Address)
+		}
+		r.prefunded = append(r.prefunded, &signer{key: key, account: p.Account, currency: p.Currency})
+	}
+	return nil
+}
+
+// createAccount generates a key and derives its account.
+func (r *constructionRun) createAccount(ctx context.Context) error {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	account, err := r.derive(ctx, key)
+	if err != nil {
+		return err
+	}
+	r.created = append(r.created, account)
+	r.result.CreatedAccounts = append(r.result.CreatedAccounts, account.Address)
+	r.logf("created account %s", account.Address)
+	return nil
+}
+
+func (r *constructionRun) derive(ctx context.Context, key *ecdsa.PrivateKey) (*rotypes.AccountIdentifier, error) {
+	resp, err := r.offline.ConstructionDerive(ctx, &rotypes.ConstructionDeriveRequest{
+		NetworkIdentifier: r.cfg.Network,
+		PublicKey:         publicKey(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("derive: %w", err)
+	}
+	if resp.AccountIdentifier == nil || resp.AccountIdentifier.Address == "" {
+		return nil, errors.New("derive returned no account")
+	}
+	return resp.AccountIdentifier, nil
+}
+
+// recipient picks the created accounts in turn, falling back to the sender
+// itself when none have been created.
+func (r *constructionRun) recipient(from *signer) *rotypes.AccountIdentifier {
+	if len(r.created) == 0 {
+		return from.account
+	}
+	return r.created[len(r.result.Transfers)%len(r.created)]
+}
+
+// transfer constructs, signs, submits and confirms one transfer.
+func (r *constructionRun) transfer(ctx context.Context, from *signer, to *rotypes.AccountIdentifier) error {
+	network := r.cfg.Network
+	intent := transferOperations(from.account, to, transferValue, from.currency)
+
+	pre, err := r.offline.ConstructionPreprocess(ctx, &rotypes.ConstructionPreprocessRequest{
+		NetworkIdentifier: network,
+		Operations:        intent,
+	})
+	if err != nil {
+		return fmt.Errorf("preprocess: %w", err)
+	}
+	var publicKeys []*rotypes.PublicKey
+	for _, required := range pre.RequiredPublicKeys {
+		if !strings.EqualFold(required.Address, from.account.Address) {
+			return fmt.Errorf("preprocess requires the key of %s, which the check does not hold", required.Address)
+		}
+		publicKeys = append(publicKeys, publicKey(from.key))
+	}
+
+	meta, err := r.online.ConstructionMetadata(ctx, &rotypes.ConstructionMetadataRequest{
+		NetworkIdentifier: network,
+		Options:           pre.Options,
+		PublicKeys:        publicKeys,
+	})
+	if err != nil {
+		return fmt.Errorf("metadata: %w", err)
+	}
+
+	payloads, err := r.offline.ConstructionPayloads(ctx, &rotypes.ConstructionPayloadsRequest{
+		NetworkIdentifier: network,
+		Operations:        intent,
+		Metadata:          meta.Metadata,
+		PublicKeys:        publicKeys,
+	})
+	if err != nil {
+		return fmt.Errorf("payloads: %w", err)
+	}
+	if err := r.checkParse(ctx, payloads.UnsignedTransaction, false, intent, nil); err != nil {
+		return err
+	}
+
+	var signatures []*rotypes.Signature
+	for _, payload := range payloads.Payloads {
+		sig, err := sign(from, payload)
+		if err != nil {
+			return err
+		}
+		signatures = append(signatures, sig)
+	}
+	combined, err := r.offline.ConstructionCombine(ctx, &rotypes.ConstructionCombineRequest{
+		NetworkIdentifier:   network,
+		UnsignedTransaction: payloads.UnsignedTransaction,
+		Signatures:          signatures,
+	})
+	if err != nil {
+		return fmt.Errorf("combine: %w", err)
+	}
+	if err := r.checkParse(ctx, combined.SignedTransaction, true, intent, from.account); err != nil {
+		return err
+	}
+
+	hash, err := r.offline.ConstructionHash(ctx, &rotypes.ConstructionHashRequest{
+		NetworkIdentifier: network,
+		SignedTransaction: combined.SignedTransaction,
+	})
+	if err != nil {
+		return fmt.Errorf("hash: %w", err)
+	}
+
+	confirmed, err := r.broadcast(ctx, combined.SignedTransaction, hash.TransactionIdentifier)
+	if err != nil {
+		return err
+	}
+	confirmed.From, confirmed.To = from.account.Address, to.Address
+	r.result.Transfers = append(r.result.Transfers, confirmed)
+	r.logf("transfer %s from %s to %s confirmed in block %d", confirmed.Transaction.Hash, confirmed.From, confirmed.To, confirmed.Block.Index)
+	return nil
+}
+
+// checkParse parses tx and compares its operations with intent and, for
+// signed transactions, its signers with signer.
+func (r *constructionRun) checkParse(
+	ctx context.Context,
+	tx string,
+	signed bool,
+	intent []*rotypes.Operation,
+	signer *rotypes.AccountIdentifier,
+) error {
+	kind := "unsigned"
+	if signed {
+		kind = "signed"
+	}
+	parsed, err := r.offline.ConstructionParse(ctx, &rotypes.ConstructionParseRequest{
+		NetworkIdentifier: r.cfg.Network,
+		Signed:            signed,
+		Transaction:       tx,
+	})
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", kind, err)
+	}
+	if err := matchOperations(intent, parsed.Operations); err != nil {
+		return fmt.Errorf("parse %s: %w", kind, err)
+	}
+	switch {
+	case !signed && len(parsed.AccountIdentifierSigners) > 0:
+		return fmt.Errorf("parse unsigned: reported %d signers", len(parsed.AccountIdentifierSigners))
+	case signed && (len(parsed.AccountIdentifierSigners) != 1 ||
+		!strings.EqualFold(parsed.AccountIdentifierSigners[0].Address, signer.Address)):
+		return fmt.Errorf("parse signed: signers %v, want %s", parsed.AccountIdentifierSigners, signer.Address)
+	}
+	return nil
+}
+
+// broadcast submits tx and waits for it to appear in a block, submitting it
+// again each time construction.stale_depth blocks pass without it.
+func (r *constructionRun) broadcast(ctx context.Context, tx string, want *rotypes.TransactionIdentifier) (*ConfirmedTransaction, error) {
+	staleDepth, limit := r.cfg.Construction.StaleDepth, r.cfg.Construction.BroadcastLimit
+	if staleDepth <= 0 {
+		staleDepth = 3
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+
+	// Blocks are scanned from the head seen before the first submission, so
+	// a transaction included right away is not missed.
+	next, err := r.head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	next++
+
+	for broadcasts := 1; broadcasts <= limit; broadcasts++ {
+		submitted, err := r.online.ConstructionSubmit(ctx, &rotypes.ConstructionSubmitRequest{
+			NetworkIdentifier: r.cfg.Network,
+			SignedTransaction: tx,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("submit: %w", err)
+		}
+		if submitted.TransactionIdentifier == nil || submitted.TransactionIdentifier.Hash != want.Hash {
+			return nil, fmt.Errorf("submit returned %v, hash returned %s", submitted.TransactionIdentifier, want.Hash)
+		}
+		r.logf("submitted %s (broadcast %d of %d)", want.Hash, broadcasts, limit)
+
+		stale := next + staleDepth
+		for next < stale {
+			head, err := r.head(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for ; next <= head && next < stale; next++ {
+				block, err := r.blockContaining(ctx, next, want)
+				if err != nil {
+					return nil, err
+				}
+				if block != nil {
+					return &ConfirmedTransaction{Transaction: want, Block: block, Broadcasts: broadcasts}, nil
+				}
+			}
+			if next < stale {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(r.PollInterval):
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s after %d broadcasts", ErrNotConfirmed, want.Hash, limit)
+}
+
+func (r *constructionRun) head(ctx context.Context) (int64, error) {
+	status, err := r.online.NetworkStatus(ctx, &rotypes.NetworkRequest{NetworkIdentifier: r.cfg.Network})
+	if err != nil {
+		return 0, fmt.Errorf("network status: %w", err)
+	}
+	return status.CurrentBlockIdentifier.Index, nil
+}
+
+// blockContaining returns the identifier of block index when it includes
+// tx, or nil when it does not.
+func (r *constructionRun) blockContaining(ctx context.Context, index int64, tx *rotypes.TransactionIdentifier) (*rotypes.BlockIdentifier, error) {
+	resp, err := r.online.Block(ctx, &rotypes.BlockRequest{
+		NetworkIdentifier: r.cfg.Network,
+		BlockIdentifier:   &rotypes.PartialBlockIdentifier{Index: &index},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", index, err)
+	}
+	if resp.Block == nil {
+		return nil, fmt.Errorf("block %d: not found", index)
+	}
+	for _, t := range resp.Block.Transactions {
+		if strings.EqualFold(t.TransactionIdentifier.Hash, tx.Hash) {
+			return resp.Block.BlockIdentifier, nil
+		}
+	}
+	for _, t := range resp.OtherTransactions {
+		if strings.EqualFold(t.Hash, tx.Hash) {
+			return resp.Block.BlockIdentifier, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *ConstructionChecker) logf(format string, args ...interface{}) {
+	if c.Logf != nil {
+		c.Logf(format, args...)
+	}
+}
+
+// transferOperations is a debit of value from `from` and a related credit to
+// `to`, the operations Mesh construction accepts for a transfer.
+func transferOperations(from, to *rotypes.AccountIdentifier, value *big.Int, currency *rotypes.Currency) []*rotypes.Operation {
+	return []*rotypes.Operation{
+		{
+			OperationIdentifier: &rotypes.OperationIdentifier{Index: 0},
+			Type:                "Transfer",
+			Account:             from,
+			Amount:              &rotypes.Amount{Value: new(big.Int).Neg(value).String(), Currency: currency},
+		},
+		{
+			OperationIdentifier: &rotypes.OperationIdentifier{Index: 1},
+			RelatedOperations:   []*rotypes.OperationIdentifier{{Index: 0}},
+			Type:                "Transfer",
+			Account:             to,
+			Amount:              &rotypes.Amount{Value: value.String(), Currency: currency},
+		},
+	}
+}
+
+// matchOperations checks that parsed has the type, account and amount of
+// every operation in intent, in order.
+func matchOperations(intent, parsed []*rotypes.Operation) error {
+	if len(parsed) != len(intent) {
+		return fmt.Errorf("got %d operations, want %d", len(parsed), len(intent))
+	}
+	for i, want := range intent {
+		got := parsed[i]
+		switch {
+		case got.Type != want.Type:
+			return fmt.Errorf("operation %d: type %s, want %s", i, got.Type, want.Type)
+		case got.Account == nil || !strings.EqualFold(got.Account.Address, want.Account.Address):
+			return fmt.Errorf("operation %d: account %v, want %s", i, got.Account, want.Account.Address)
+		case got.Amount == nil || got.Amount.Value != want.Amount.Value ||
+			rotypes.Hash(got.Amount.Currency) != rotypes.Hash(want.Amount.Currency):
+			return fmt.Errorf("operation %d: amount %v, want %s %s", i, got.Amount, want.Amount.Value, want.Amount.Currency.Symbol)
+		}
+	}
+	return nil
+}
+
+func publicKey(key *ecdsa.PrivateKey) *rotypes.PublicKey {
+	return &rotypes.PublicKey{
+		Bytes:     crypto.CompressPubkey(&key.PublicKey),
+		CurveType: rotypes.Secp256k1,
+	}
+}
+
+// sign signs payload with s's key in the signature type it asks for.
+func sign(s *signer, payload *rotypes.SigningPayload) (*rotypes.Signature, error) {
+	if payload.AccountIdentifier == nil || !strings.EqualFold(payload.AccountIdentifier.Address, s.account.Address) {
+		return nil, fmt.Errorf("payload for %v, which the check does not hold the key of", payload.AccountIdentifier)
+	}
+	if len(payload.Bytes) != 32 {
+		return nil, fmt.Errorf("payload is %d bytes, want a 32 byte hash", len(payload.Bytes))
+	}
+	sig, err := crypto.Sign(payload.Bytes, s.key)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	sigType := payload.SignatureType
+	switch sigType {
+	case "", rotypes.EcdsaRecovery:
+		sigType = rotypes.EcdsaRecovery
+	case rotypes.Ecdsa:
+		sig = sig[:64]
+	default:
+		return nil, fmt.Errorf("unsupported signature type %s", sigType)
+	}
+	return &rotypes.Signature{
+		SigningPayload: payload,
+		PublicKey:      publicKey(s.key),
+		SignatureType:  sigType,
+		Bytes:          sig,
+	}, nil
+}
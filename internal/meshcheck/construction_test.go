@@ -0,0 +1,251 @@
+package meshcheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/server"
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rutishh0/mesh-server/services"
+	"github.com/rutishh0/testingquant/internal/clients"
+)
+
+var sepoliaChainID = big.NewInt(11155111)
+
+// ledger is a chain behind a Mesh server: the mesh-server services answer
+// the offline construction endpoints, while /network/status, /block,
+// /construction/metadata and /construction/submit are served from the
+// ledger. Every /network/status mines a block with the pending transactions.
+type ledger struct {
+	mu      sync.Mutex
+	blocks  []*rotypes.Block
+	pending []*ethtypes.Transaction
+	nonces  map[common.Address]uint64
+	// submits counts broadcasts per hash; the first drop broadcasts of each
+	// transaction are accepted but never mined.
+	submits map[string]int
+	drop    int
+}
+
+func startLedgerServer(t *testing.T, drop int) (*httptest.Server, *ledger) {
+	t.Helper()
+	networks, err := services.NewNetworks(&services.Network{
+		Identifier: sepolia,
+		ChainID:    sepoliaChainID,
+		Currency:   &rotypes.Currency{Symbol: "ETH", Decimals: 18},
+		Mode:       services.ModeMock,
+		Sim:        &services.SimConfig{Blocks: 1},
+	})
+	require.NoError(t, err)
+	assr, err := asserter.NewServer(services.OperationTypes, true, networks.Identifiers(), services.CallMethods, false, "")
+	require.NoError(t, err)
+	router := server.NewRouter(server.NewConstructionAPIController(services.NewConstructionAPIService(networks), assr))
+
+	l := &ledger{nonces: map[common.Address]uint64{}, submits: map[string]int{}, drop: drop}
+	l.mine()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		switch r.URL.Path {
+		case "/network/status", "/block", "/construction/metadata", "/construction/submit":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		default:
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		var response interface{}
+		switch r.URL.Path {
+		case "/network/status":
+			head := l.mine()
+			response = &rotypes.NetworkStatusResponse{
+				CurrentBlockIdentifier: head.BlockIdentifier,
+				CurrentBlockTimestamp:  head.Timestamp,
+				GenesisBlockIdentifier: l.blocks[0].BlockIdentifier,
+				Peers:                  []*rotypes.Peer{},
+			}
+		case "/block":
+			index := int64(request["block_identifier"].(map[string]interface{})["index"].(float64))
+			response = &rotypes.BlockResponse{Block: l.blocks[index]}
+		case "/construction/metadata":
+			from := common.HexToAddress(request["options"].(map[string]interface{})["from"].(string))
+			response = &rotypes.ConstructionMetadataResponse{
+				Metadata: map[string]interface{}{
+					"nonce":                    hexutil.Uint64(l.nonces[from]),
+					"gas_limit":                hexutil.Uint64(21000),
+					"max_fee_per_gas":          (*hexutil.Big)(big.NewInt(2_000_000_000)),
+					"max_priority_fee_per_gas": (*hexutil.Big)(big.NewInt(1_000_000_000)),
+					"chain_id":                 (*hexutil.Big)(sepoliaChainID),
+				},
+			}
+		case "/construction/submit":
+			raw, err := hexutil.Decode(request["signed_transaction"].(string))
+			require.NoError(t, err)
+			tx := new(ethtypes.Transaction)
+			require.NoError(t, tx.UnmarshalBinary(raw))
+			hash := tx.Hash().Hex()
+			if l.submits[hash]++; l.submits[hash] > l.drop {
+				l.pending = append(l.pending, tx)
+			}
+			response = &rotypes.TransactionIdentifierResponse{
+				TransactionIdentifier: &rotypes.TransactionIdentifier{Hash: hash},
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	return srv, l
+}
+
+// mine appends a block holding the pending transactions whose nonces are
+// next for their senders.
+func (l *ledger) mine() *rotypes.Block {
+	index := int64(len(l.blocks))
+	block := &rotypes.Block{
+		BlockIdentifier: &rotypes.BlockIdentifier{Index: index, Hash: hexutil.EncodeBig(big.NewInt(index + 1))},
+		Timestamp:       1_700_000_000_000 + index*12_000,
+		Transactions:    []*rotypes.Transaction{},
+	}
+	block.ParentBlockIdentifier = block.BlockIdentifier
+	if index > 0 {
+		block.ParentBlockIdentifier = l.blocks[index-1].BlockIdentifier
+	}
+	signer := ethtypes.LatestSignerForChainID(sepoliaChainID)
+	for _, tx := range l.pending {
+		from, err := ethtypes.Sender(signer, tx)
+		if err != nil || tx.Nonce() != l.nonces[from] {
+			continue
+		}
+		l.nonces[from]++
+		block.Transactions = append(block.Transactions, &rotypes.Transaction{
+			TransactionIdentifier: &rotypes.TransactionIdentifier{Hash: tx.Hash().Hex()},
+			Operations:            []*rotypes.Operation{},
+		})
+	}
+	l.pending = nil
+	l.blocks = append(l.blocks, block)
+	return block
+}
+
+func (l *ledger) nonce(address string) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.nonces[common.HexToAddress(address)]
+}
+
+func constructionConfig(t *testing.T, url string, key *ecdsa.PrivateKey, accounts, transfers int) *Config {
+	t.Helper()
+	return &Config{
+		Network:   sepolia,
+		OnlineURL: url,
+		Construction: ConstructionConfig{
+			OfflineURL:     url,
+			StaleDepth:     2,
+			BroadcastLimit: 1,
+			EndConditions:  map[string]int{EndConditionCreateAccount: accounts, EndConditionTransfer: transfers},
+			PrefundedAccounts: []*PrefundedAccount{{
+				PrivateKey: "0x" + hex.EncodeToString(crypto.FromECDSA(key)),
+				Account:    &rotypes.AccountIdentifier{Address: crypto.PubkeyToAddress(key.PublicKey).Hex()},
+				CurveType:  rotypes.Secp256k1,
+				Currency:   &rotypes.Currency{Symbol: "ETH", Decimals: 18},
+			}},
+		},
+	}
+}
+
+func newTestConstructionChecker(url string, cfg *Config) *ConstructionChecker {
+	client := clients.NewMeshClient(url)
+	c := NewConstructionChecker(client, client, cfg)
+	c.PollInterval = time.Millisecond
+	return c
+}
+
+func TestConstructionChecker_MeetsEndConditions(t *testing.T) {
+	srv, l := startLedgerServer(t, 0)
+	defer srv.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	result, err := newTestConstructionChecker(srv.URL, constructionConfig(t, srv.URL, key, 2, 3)).Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, result.CreatedAccounts, 2)
+	require.Len(t, result.Transfers, 3)
+	from := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	for i, tx := range result.Transfers {
+		assert.Equal(t, from, tx.From)
+		assert.Contains(t, result.CreatedAccounts, tx.To)
+		assert.Equal(t, 1, tx.Broadcasts)
+		assert.Positive(t, tx.Block.Index)
+		if i > 0 {
+			assert.Greater(t, tx.Block.Index, result.Transfers[i-1].Block.Index)
+		}
+	}
+	assert.Equal(t, uint64(3), l.nonce(from))
+}
+
+func TestConstructionChecker_TransfersToSelfWithoutNewAccounts(t *testing.T) {
+	srv, _ := startLedgerServer(t, 0)
+	defer srv.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	result, err := newTestConstructionChecker(srv.URL, constructionConfig(t, srv.URL, key, 0, 1)).Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, 1)
+	assert.Equal(t, result.Transfers[0].From, result.Transfers[0].To)
+}
+
+func TestConstructionChecker_Rebroadcasts(t *testing.T) {
+	srv, _ := startLedgerServer(t, 1)
+	defer srv.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	t.Run("confirms a rebroadcast transaction", func(t *testing.T) {
+		cfg := constructionConfig(t, srv.URL, key, 1, 1)
+		cfg.Construction.BroadcastLimit = 2
+		result, err := newTestConstructionChecker(srv.URL, cfg).Run(context.Background())
+		require.NoError(t, err)
+		require.Len(t, result.Transfers, 1)
+		assert.Equal(t, 2, result.Transfers[0].Broadcasts)
+	})
+
+	t.Run("gives up after the broadcast limit", func(t *testing.T) {
+		result, err := newTestConstructionChecker(srv.URL, constructionConfig(t, srv.URL, key, 1, 1)).Run(context.Background())
+		require.True(t, errors.Is(err, ErrNotConfirmed), "got %v", err)
+		assert.Empty(t, result.Transfers)
+	})
+}
+
+func TestConstructionChecker_RejectsMismatchedPrefundedAccount(t *testing.T) {
+	srv, _ := startLedgerServer(t, 0)
+	defer srv.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	cfg := constructionConfig(t, srv.URL, key, 0, 1)
+	cfg.Construction.PrefundedAccounts[0].Account.Address = "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
+	_, err = newTestConstructionChecker(srv.URL, cfg).Run(context.Background())
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "privkey derives"), err.Error())
+}
@@ -0,0 +1,395 @@
+package meshcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+
+	"github.com/rutishh0/testingquant/internal/clients"
+)
+
+// ErrReconciliation is returned by DataChecker.Run when a computed balance
+// does not match /account/balance and reconciliation errors are not ignored.
+var ErrReconciliation = errors.New("balance reconciliation failed")
+
+// Discrepancy is a balance computed from operations that differs from the
+// one served by /account/balance.
+type Discrepancy struct {
+	Block    *rotypes.BlockIdentifier
+	Account  *rotypes.AccountIdentifier
+	Currency *rotypes.Currency
+	Computed string
+	Live     string
+}
+
+func (d *Discrepancy) String() string {
+	return fmt.Sprintf("%s %s at block %d (%s): computed %s, live %s",
+		d.Account.Address, d.Currency.Symbol, d.Block.Index, d.Block.Hash, d.Computed, d.Live)
+}
+
+// DataResult summarizes a check:data run.
+type DataResult struct {
+	StartIndex   int64
+	EndIndex     int64
+	Blocks       int64
+	Transactions int64
+	Operations   int64
+	// Accounts is the number of account and currency pairs tracked.
+	Accounts        int
+	Reconciliations int64
+	Discrepancies   []*Discrepancy
+}
+
+// DataChecker implements rosetta-cli check:data: it syncs the configured
+// block range, validates every block and its parent linkage, tracks balances
+// from successful operations and reconciles them against /account/balance.
+type DataChecker struct {
+	client clients.MeshAPIV2
+	cfg    *Config
+	// Logf, when set, receives block and reconciliation logs as enabled by
+	// data.log_blocks and data.log_reconciliation.
+	Logf func(format string, args ...interface{})
+}
+
+// NewDataChecker creates a data checker for cfg's network on client.
+func NewDataChecker(client clients.MeshAPIV2, cfg *Config) *DataChecker {
+	return &DataChecker{client: client, cfg: cfg}
+}
+
+// dataRun is the state of one Run.
+type dataRun struct {
+	*DataChecker
+	assr        *asserter.Asserter
+	result      *DataResult
+	balances    *balanceTracker
+	exempt      map[string]bool
+	interesting []*AccountCurrency
+	// before identifies the block preceding the range; balances of accounts
+	// first seen during the sync are read there. Nil when the range starts
+	// at genesis.
+	before *rotypes.BlockIdentifier
+}
+
+// Run checks the configured range. Invalid blocks, broken parent links and
+// failed requests stop the run with an error; balance discrepancies are
+// collected in the result and, unless data.ignore_reconciliation_error is
+// set, stop it with ErrReconciliation.
+func (d *DataChecker) Run(ctx context.Context) (*DataResult, error) {
+	network := &rotypes.NetworkRequest{NetworkIdentifier: d.cfg.Network}
+	status, err := d.client.NetworkStatus(ctx, network)
+	if err != nil {
+		return nil, fmt.Errorf("network status: %w", err)
+	}
+	options, err := d.client.NetworkOptions(ctx, network)
+	if err != nil {
+		return nil, fmt.Errorf("network options: %w", err)
+	}
+	assr, err := asserter.NewClientWithResponses(d.cfg.Network, status, options, "")
+	if err != nil {
+		return nil, fmt.Errorf("network responses are invalid: %w", err)
+	}
+
+	start, end := d.cfg.Data.StartIndex, status.CurrentBlockIdentifier.Index
+	if start < status.GenesisBlockIdentifier.Index {
+		start = status.GenesisBlockIdentifier.Index
+	}
+	if d.cfg.Data.EndIndex != nil && *d.cfg.Data.EndIndex < end {
+		end = *d.cfg.Data.EndIndex
+	}
+	if start > end {
+		return nil, fmt.Errorf("start index %d is past end index %d", start, end)
+	}
+
+	run := &dataRun{
+		DataChecker: d,
+		assr:        assr,
+		result:      &DataResult{StartIndex: start, EndIndex: end},
+		balances:    newBalanceTracker(),
+		exempt:      map[string]bool{},
+	}
+	if err := run.loadAccounts(start == status.GenesisBlockIdentifier.Index); err != nil {
+		return nil, err
+	}
+
+	err = run.sync(ctx, start, end, start > status.GenesisBlockIdentifier.Index)
+	run.result.Accounts = run.balances.len()
+	return run.result, err
+}
+
+// sync checks blocks start through end, then reconciles every tracked
+// balance at end.
+func (r *dataRun) sync(ctx context.Context, start, end int64, afterGenesis bool) error {
+	var parent *rotypes.BlockIdentifier
+	if afterGenesis {
+		prev, err := r.fetchBlock(ctx, start-1)
+		if err != nil {
+			return err
+		}
+		parent, r.before = prev.BlockIdentifier, prev.BlockIdentifier
+		for _, a := range r.interesting {
+			base, err := r.startingBalance(ctx, a.Account, a.Currency)
+			if err != nil {
+				return err
+			}
+			r.balances.set(a.Account, a.Currency, base)
+		}
+	}
+
+	var last *rotypes.BlockIdentifier
+	for index := start; index <= end; index++ {
+		block, err := r.fetchBlock(ctx, index)
+		if err != nil {
+			return err
+		}
+		if err := r.checkBlock(block, index, parent); err != nil {
+			return err
+		}
+		touched, err := r.applyBlock(ctx, block)
+		if err != nil {
+			return err
+		}
+		if r.cfg.Data.LogBlocks {
+			r.logf("block %d %s: %d transactions", index, block.BlockIdentifier.Hash, len(block.Transactions))
+		}
+
+		accounts := append(touched, r.interesting...)
+		if f := r.cfg.Data.InactiveReconciliationFrequency; f > 0 && (index-start+1)%f == 0 {
+			accounts = r.balances.accounts()
+		}
+		if err := r.reconcile(ctx, block.BlockIdentifier, accounts); err != nil {
+			return err
+		}
+		parent, last = block.BlockIdentifier, block.BlockIdentifier
+	}
+
+	// Inactive reconciliation: every tracked balance must still match at the
+	// end of the range.
+	return r.reconcile(ctx, last, r.balances.accounts())
+}
+
+// loadAccounts reads the exempt, interesting and, when the run starts at
+// genesis, bootstrap accounts.
+func (r *dataRun) loadAccounts(fromGenesis bool) error {
+	exempt, err := r.cfg.ExemptAccounts()
+	if err != nil {
+		return err
+	}
+	for _, e := range exempt {
+		r.exempt[accountCurrencyKey(e.Account, e.Currency)] = true
+	}
+	if r.interesting, err = r.cfg.InterestingAccounts(); err != nil {
+		return err
+	}
+	if !fromGenesis {
+		// Bootstrap balances describe the state before genesis; later
+		// ranges read starting balances from /account/balance instead.
+		return nil
+	}
+	bootstrap, err := r.cfg.BootstrapBalances()
+	if err != nil {
+		return err
+	}
+	for _, b := range bootstrap {
+		value, ok := new(big.Int).SetString(b.Value, 10)
+		if !ok {
+			return fmt.Errorf("bootstrap balance %q of %s is not an integer", b.Value, b.Account.Address)
+		}
+		r.balances.set(b.Account, b.Currency, value)
+	}
+	for _, a := range r.interesting {
+		if !r.balances.tracked(a.Account, a.Currency) {
+			r.balances.set(a.Account, a.Currency, new(big.Int))
+		}
+	}
+	return nil
+}
+
+func (r *dataRun) fetchBlock(ctx context.Context, index int64) (*rotypes.Block, error) {
+	resp, err := r.client.Block(ctx, &rotypes.BlockRequest{
+		NetworkIdentifier: r.cfg.Network,
+		BlockIdentifier:   &rotypes.PartialBlockIdentifier{Index: &index},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch block %d: %w", index, err)
+	}
+	if resp.Block == nil {
+		return nil, fmt.Errorf("block %d is missing", index)
+	}
+	block := resp.Block
+	for _, id := range resp.OtherTransactions {
+		tx, err := r.client.BlockTransaction(ctx, &rotypes.BlockTransactionRequest{
+			NetworkIdentifier:     r.cfg.Network,
+			BlockIdentifier:       block.BlockIdentifier,
+			TransactionIdentifier: id,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetch transaction %s of block %d: %w", id.Hash, index, err)
+		}
+		block.Transactions = append(block.Transactions, tx.Transaction)
+	}
+	return block, nil
+}
+
+// checkBlock validates block with the asserter and checks that it is the
+// requested height and links to parent.
+func (r *dataRun) checkBlock(block *rotypes.Block, index int64, parent *rotypes.BlockIdentifier) error {
+	if err := r.assr.Block(block); err != nil {
+		return fmt.Errorf("block %d is invalid: %w", index, err)
+	}
+	if block.BlockIdentifier.Index != index {
+		return fmt.Errorf("requested block %d but got block %d", index, block.BlockIdentifier.Index)
+	}
+	if parent != nil && rotypes.Hash(block.ParentBlockIdentifier) != rotypes.Hash(parent) {
+		return fmt.Errorf("block %d parent is %d (%s), expected %d (%s)", index,
+			block.ParentBlockIdentifier.Index, block.ParentBlockIdentifier.Hash, parent.Index, parent.Hash)
+	}
+	return nil
+}
+
+// applyBlock adds the block's successful balance-changing operations to the
+// tracked balances and returns the accounts they touched.
+func (r *dataRun) applyBlock(ctx context.Context, block *rotypes.Block) ([]*AccountCurrency, error) {
+	var touched []*AccountCurrency
+	seen := map[string]bool{}
+	for _, tx := range block.Transactions {
+		r.result.Transactions++
+		for _, op := range tx.Operations {
+			r.result.Operations++
+			if r.cfg.Data.BalanceTrackingDisabled || op.Account == nil || op.Amount == nil {
+				continue
+			}
+			successful, err := r.assr.OperationSuccessful(op)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d of %s: %w", op.OperationIdentifier.Index, tx.TransactionIdentifier.Hash, err)
+			}
+			if !successful {
+				continue
+			}
+			if !r.balances.tracked(op.Account, op.Amount.Currency) {
+				base, err := r.startingBalance(ctx, op.Account, op.Amount.Currency)
+				if err != nil {
+					return nil, err
+				}
+				r.balances.set(op.Account, op.Amount.Currency, base)
+			}
+			if err := r.balances.add(op.Account, op.Amount); err != nil {
+				return nil, fmt.Errorf("operation %d of %s: %w", op.OperationIdentifier.Index, tx.TransactionIdentifier.Hash, err)
+			}
+			key := accountCurrencyKey(op.Account, op.Amount.Currency)
+			if !seen[key] {
+				seen[key] = true
+				touched = append(touched, &AccountCurrency{Account: op.Account, Currency: op.Amount.Currency})
+			}
+		}
+	}
+	r.result.Blocks++
+	return touched, nil
+}
+
+// startingBalance is the balance of an account before the range: zero from
+// genesis, otherwise its balance at the preceding block.
+func (r *dataRun) startingBalance(ctx context.Context, account *rotypes.AccountIdentifier, currency *rotypes.Currency) (*big.Int, error) {
+	if r.before == nil {
+		return new(big.Int), nil
+	}
+	return r.liveBalance(ctx, r.before, account, currency)
+}
+
+func (r *dataRun) liveBalance(ctx context.Context, block *rotypes.BlockIdentifier, account *rotypes.AccountIdentifier, currency *rotypes.Currency) (*big.Int, error) {
+	resp, err := r.client.AccountBalance(ctx, &rotypes.AccountBalanceRequest{
+		NetworkIdentifier: r.cfg.Network,
+		AccountIdentifier: account,
+		BlockIdentifier:   &rotypes.PartialBlockIdentifier{Index: &block.Index, Hash: &block.Hash},
+		Currencies:        []*rotypes.Currency{currency},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("balance of %s at block %d: %w", account.Address, block.Index, err)
+	}
+	if resp.BlockIdentifier != nil && rotypes.Hash(resp.BlockIdentifier) != rotypes.Hash(block) {
+		return nil, fmt.Errorf("balance of %s requested at block %d was served at block %d", account.Address, block.Index, resp.BlockIdentifier.Index)
+	}
+	for _, amount := range resp.Balances {
+		if rotypes.Hash(amount.Currency) == rotypes.Hash(currency) {
+			return rotypes.AmountValue(amount)
+		}
+	}
+	// Accounts that never held the currency may omit it
+	return new(big.Int), nil
+}
+
+// reconcile compares the tracked balances of accounts with /account/balance
+// at block, using data.active_reconciliation_concurrency workers.
+func (r *dataRun) reconcile(ctx context.Context, block *rotypes.BlockIdentifier, accounts []*AccountCurrency) error {
+	if r.cfg.Data.ReconciliationDisabled || r.cfg.Data.BalanceTrackingDisabled || block == nil {
+		return nil
+	}
+	workers := r.cfg.Data.ActiveReconciliationConcurrency
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		done     = map[string]bool{}
+		sem      = make(chan struct{}, workers)
+	)
+	for _, a := range accounts {
+		key := accountCurrencyKey(a.Account, a.Currency)
+		if r.exempt[key] || done[key] {
+			continue
+		}
+		done[key] = true
+		computed := r.balances.get(a.Account, a.Currency)
+		if computed == nil {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(a *AccountCurrency, computed *big.Int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			live, err := r.liveBalance(ctx, block, a.Account, a.Currency)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			r.result.Reconciliations++
+			if live.Cmp(computed) == 0 {
+				return
+			}
+			d := &Discrepancy{Block: block, Account: a.Account, Currency: a.Currency, Computed: computed.String(), Live: live.String()}
+			r.result.Discrepancies = append(r.result.Discrepancies, d)
+			if r.cfg.Data.LogReconciliation {
+				r.logf("reconciliation failed: %s", d)
+			}
+		}(a, computed)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if len(r.result.Discrepancies) > 0 && !r.cfg.Data.IgnoreReconciliationError {
+		return fmt.Errorf("%w: %s", ErrReconciliation, r.result.Discrepancies[0])
+	}
+	return nil
+}
+
+func (d *DataChecker) logf(format string, args ...interface{}) {
+	if d.Logf != nil {
+		d.Logf(format, args...)
+	}
+}
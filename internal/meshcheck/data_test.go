@@ -0,0 +1,255 @@
+package meshcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/server"
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rutishh0/mesh-server/services"
+	"github.com/rutishh0/testingquant/internal/clients"
+)
+
+var sepolia = &rotypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"}
+
+// rewriteFunc edits the decoded JSON response of a Mesh request.
+type rewriteFunc func(path string, request, response map[string]interface{})
+
+// startMeshServer serves a 40 block simulated Sepolia chain through the
+// mesh-server services. rewrite, when set, may edit every response.
+func startMeshServer(t *testing.T, rewrite rewriteFunc) *httptest.Server {
+	t.Helper()
+	networks, err := services.NewNetworks(&services.Network{
+		Identifier: sepolia,
+		ChainID:    big.NewInt(11155111),
+		Currency:   &rotypes.Currency{Symbol: "ETH", Decimals: 18},
+		Mode:       services.ModeMock,
+		Sim:        &services.SimConfig{Blocks: 40},
+	})
+	require.NoError(t, err)
+	assr, err := asserter.NewServer(services.OperationTypes, true, networks.Identifiers(), services.CallMethods, false, "")
+	require.NoError(t, err)
+
+	router := server.NewRouter(
+		server.NewNetworkAPIController(services.NewNetworkAPIService(networks), assr),
+		server.NewBlockAPIController(services.NewBlockAPIService(networks), assr),
+		server.NewAccountAPIController(services.NewAccountAPIService(networks), assr),
+		server.NewConstructionAPIController(services.NewConstructionAPIService(networks), assr),
+	)
+	if rewrite == nil {
+		return httptest.NewServer(router)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := readAll(r)
+		r.Body = readCloser(body)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+
+		var request, response map[string]interface{}
+		_ = json.Unmarshal(body, &request)
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &response) != nil {
+			w.WriteHeader(rec.Code)
+			w.Write(rec.Body.Bytes())
+			return
+		}
+		rewrite(r.URL.Path, request, response)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func readAll(r *http.Request) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(r.Body)
+	return buf.Bytes(), err
+}
+
+type nopCloser struct{ *bytes.Reader }
+
+func (nopCloser) Close() error { return nil }
+
+func readCloser(b []byte) nopCloser { return nopCloser{bytes.NewReader(b)} }
+
+// testConfig returns a data config for url with the account lists written
+// to a temporary directory.
+func testConfig(t *testing.T, url string, start, end int64, exempt []*AccountCurrency) *Config {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name string, v interface{}) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, b, 0o600))
+		return path
+	}
+	if exempt == nil {
+		exempt = []*AccountCurrency{}
+	}
+	return &Config{
+		Network:   sepolia,
+		OnlineURL: url,
+		Data: DataConfig{
+			StartIndex:                      start,
+			EndIndex:                        &end,
+			ActiveReconciliationConcurrency: 4,
+			InactiveReconciliationFrequency: 10,
+			ExemptAccounts:                  write("exempt_accounts.json", exempt),
+			InterestingAccounts:             write("interesting_accounts.json", []*AccountCurrency{}),
+			BootstrapBalances:               write("bootstrap_balances.json", []*BootstrapBalance{}),
+		},
+	}
+}
+
+func TestDataChecker_ReconcilesSimulatedChain(t *testing.T) {
+	srv := startMeshServer(t, nil)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name       string
+		start, end int64
+	}{
+		{"from genesis", 0, 30},
+		{"from the middle of the chain", 12, 25},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig(t, srv.URL, tc.start, tc.end, nil)
+			result, err := NewDataChecker(clients.NewMeshClient(srv.URL), cfg).Run(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.end-tc.start+1, result.Blocks)
+			assert.Positive(t, result.Transactions)
+			assert.Positive(t, result.Accounts)
+			assert.Positive(t, result.Reconciliations)
+			assert.Empty(t, result.Discrepancies)
+		})
+	}
+}
+
+func TestDataChecker_EndIndexDefaultsToHead(t *testing.T) {
+	srv := startMeshServer(t, nil)
+	defer srv.Close()
+
+	cfg := testConfig(t, srv.URL, 35, 0, nil)
+	cfg.Data.EndIndex = nil
+	result, err := NewDataChecker(clients.NewMeshClient(srv.URL), cfg).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), result.EndIndex)
+}
+
+func TestDataChecker_ReportsDiscrepancies(t *testing.T) {
+	var skewed string
+	// Serve one wei more for the skewed account once the chain is 5 blocks deep
+	srv := startMeshServer(t, func(path string, request, response map[string]interface{}) {
+		if path != "/account/balance" {
+			return
+		}
+		account := request["account_identifier"].(map[string]interface{})["address"].(string)
+		block := response["block_identifier"].(map[string]interface{})
+		if account != skewed || block["index"].(float64) < 5 {
+			return
+		}
+		for _, b := range response["balances"].([]interface{}) {
+			amount := b.(map[string]interface{})
+			value, _ := new(big.Int).SetString(amount["value"].(string), 10)
+			amount["value"] = value.Add(value, big.NewInt(1)).String()
+		}
+	})
+	defer srv.Close()
+	client := clients.NewMeshClient(srv.URL)
+
+	// Skew the first account funded in the genesis block
+	index := int64(0)
+	genesis, err := client.Block(context.Background(), &rotypes.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &rotypes.PartialBlockIdentifier{Index: &index},
+	})
+	require.NoError(t, err)
+	skewed = genesis.Block.Transactions[0].Operations[0].Account.Address
+
+	t.Run("stops at the first discrepancy", func(t *testing.T) {
+		result, err := NewDataChecker(client, testConfig(t, srv.URL, 0, 20, nil)).Run(context.Background())
+		require.True(t, errors.Is(err, ErrReconciliation), "got %v", err)
+		require.Len(t, result.Discrepancies, 1)
+		d := result.Discrepancies[0]
+		assert.Equal(t, skewed, d.Account.Address)
+		live, _ := new(big.Int).SetString(d.Live, 10)
+		computed, _ := new(big.Int).SetString(d.Computed, 10)
+		assert.Equal(t, big.NewInt(1), new(big.Int).Sub(live, computed))
+	})
+
+	t.Run("collects discrepancies when errors are ignored", func(t *testing.T) {
+		cfg := testConfig(t, srv.URL, 0, 20, nil)
+		cfg.Data.IgnoreReconciliationError = true
+		result, err := NewDataChecker(client, cfg).Run(context.Background())
+		require.NoError(t, err)
+		assert.Greater(t, len(result.Discrepancies), 1)
+		for _, d := range result.Discrepancies {
+			assert.Equal(t, skewed, d.Account.Address)
+		}
+	})
+
+	t.Run("skips exempt accounts", func(t *testing.T) {
+		cfg := testConfig(t, srv.URL, 0, 20, []*AccountCurrency{{
+			Account:  &rotypes.AccountIdentifier{Address: skewed},
+			Currency: &rotypes.Currency{Symbol: "ETH", Decimals: 18},
+		}})
+		result, err := NewDataChecker(client, cfg).Run(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, result.Discrepancies)
+	})
+}
+
+func TestDataChecker_DetectsBrokenParentLink(t *testing.T) {
+	srv := startMeshServer(t, func(path string, request, response map[string]interface{}) {
+		if path != "/block" {
+			return
+		}
+		block := response["block"].(map[string]interface{})
+		if block["block_identifier"].(map[string]interface{})["index"].(float64) == 7 {
+			block["parent_block_identifier"].(map[string]interface{})["hash"] = "0xdead"
+		}
+	})
+	defer srv.Close()
+
+	_, err := NewDataChecker(clients.NewMeshClient(srv.URL), testConfig(t, srv.URL, 0, 10, nil)).Run(context.Background())
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "block 7 parent"), err.Error())
+}
+
+func TestLoadConfig_ResolvesAccountFilesNextToConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "exempt_accounts.json"),
+		[]byte(`[{"account_identifier": {"address": "0xabc"}, "currency": {"symbol": "ETH", "decimals": 18}}]`), 0o600))
+	path := filepath.Join(dir, "mesh-cli-config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"network": {"blockchain": "Ethereum", "network": "Sepolia"},
+		"online_url": "http://localhost:8080/mesh",
+		"data": {"start_index": 3, "exempt_accounts": "config/exempt_accounts.json"}
+	}`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cfg.Data.StartIndex)
+	assert.Nil(t, cfg.Data.EndIndex)
+
+	exempt, err := cfg.ExemptAccounts()
+	require.NoError(t, err)
+	require.Len(t, exempt, 1)
+	assert.Equal(t, "0xabc", exempt[0].Account.Address)
+
+	_, err = LoadConfig(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
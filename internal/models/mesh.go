@@ -16,17 +16,3 @@ type MeshNetwork struct {
 type MeshNetworksResponse struct {
 	Networks []MeshNetwork `json:"networks"`
 }
-
-// MeshBalance represents the balance of a single asset in an account
-type MeshBalance struct {
-	Value    string `json:"value"`
-	Currency struct {
-		Symbol   string `json:"symbol"`
-		Decimals int    `json:"decimals"`
-	} `json:"currency"`
-}
-
-// MeshBalanceResponse represents the response for an account's balance
-type MeshBalanceResponse struct {
-	Balances []MeshBalance `json:"balances"`
-}
\ No newline at end of file
@@ -0,0 +1,127 @@
+package overledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/rutishh0/mesh-server/fees"
+)
+
+// Urgency is how quickly a transaction should be included. It is sent as the
+// urgency of the Overledger preparation request and selects the fees the
+// transaction is signed with. Levels are priced by the Mesh server's fee
+// policy, so both sign alike.
+type Urgency = fees.Urgency
+
+const (
+	UrgencySlow   = fees.Slow
+	UrgencyNormal = fees.Normal
+	UrgencyFast   = fees.Fast
+)
+
+// Urgencies lists the urgency levels from slowest to fastest
+var Urgencies = fees.Urgencies
+
+// ParseUrgency validates an urgency level; an empty one is normal
+func ParseUrgency(s string) (Urgency, error) {
+	u := Urgency(strings.ToLower(strings.TrimSpace(s)))
+	if u == "" {
+		return UrgencyNormal, nil
+	}
+	if !u.Valid() {
+		return "", fmt.Errorf("invalid urgency %q: must be slow, normal or fast", s)
+	}
+	return u, nil
+}
+
+// GasFees are the EIP-1559 gas fields of a transaction, in wei
+type GasFees struct {
+	GasLimit             string `json:"gasLimit"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         string `json:"maxFeePerGas"`
+}
+
+// FeeCall is the transaction whose gas is estimated
+type FeeCall struct {
+	From  string
+	To    string
+	Value *big.Int
+	Data  []byte
+}
+
+// FeeQuote is the chain's current pricing of a transaction
+type FeeQuote struct {
+	GasLimit uint64
+	// BaseFeePerGas is the base fee of the next block
+	BaseFeePerGas *big.Int
+	// Tips holds the priority fee per gas of each urgency level
+	Tips map[Urgency]*big.Int
+}
+
+// Fees returns the gas fields to sign with at urgency u
+func (q *FeeQuote) Fees(u Urgency) GasFees {
+	tip := q.Tips[u]
+	return GasFees{
+		GasLimit:             strconv.FormatUint(q.GasLimit, 10),
+		MaxPriorityFeePerGas: tip.String(),
+		MaxFeePerGas:         fees.MaxFee(u, q.BaseFeePerGas, tip).String(),
+	}
+}
+
+// FeeEstimator prices EIP-1559 transactions from eth_feeHistory,
+// eth_maxPriorityFeePerGas and eth_estimateGas on each network's node
+type FeeEstimator struct {
+	rpc *nodeRPC
+}
+
+// NewFeeEstimator returns a FeeEstimator for urls, a map from Overledger
+// location network to comma-separated JSON-RPC endpoints tried in order
+func NewFeeEstimator(urls map[string]string) *FeeEstimator {
+	return &FeeEstimator{rpc: newNodeRPC(urls)}
+}
+
+// Estimate quotes call on network. It returns ErrNoRPC when the network has
+// no endpoint configured.
+func (e *FeeEstimator) Estimate(ctx context.Context, network string, call *FeeCall) (*FeeQuote, error) {
+	var history fees.History
+	if err := e.rpc.call(ctx, network, "eth_feeHistory", fees.HistoryParams(), &history); err != nil {
+		return nil, err
+	}
+	baseFee := history.NextBaseFee()
+	if baseFee == nil {
+		return nil, fmt.Errorf("fee history of %s has no base fee", network)
+	}
+	// Levels recent blocks give no tip for use the node's suggestion
+	tips, err := history.Tips(func() (*big.Int, error) {
+		var s hexutil.Big
+		if err := e.rpc.call(ctx, network, "eth_maxPriorityFeePerGas", []interface{}{}, &s); err != nil {
+			return nil, err
+		}
+		return s.ToInt(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	quote := &FeeQuote{BaseFeePerGas: baseFee, Tips: tips}
+
+	tx := map[string]interface{}{"to": call.To}
+	if call.From != "" {
+		tx["from"] = call.From
+	}
+	if call.Value != nil {
+		tx["value"] = (*hexutil.Big)(call.Value)
+	}
+	if len(call.Data) > 0 {
+		tx["data"] = hexutil.Bytes(call.Data)
+	}
+	var gas hexutil.Uint64
+	if err := e.rpc.call(ctx, network, "eth_estimateGas", []interface{}{tx}, &gas); err != nil {
+		return nil, err
+	}
+	quote.GasLimit = uint64(gas)
+	return quote, nil
+}
@@ -0,0 +1,116 @@
+package overledger
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gwei = 1_000_000_000
+
+// startFeeNode serves canned JSON-RPC results by method and records the
+// params each method was called with
+func startFeeNode(t *testing.T, results map[string]interface{}) (*httptest.Server, map[string][]json.RawMessage) {
+	t.Helper()
+	calls := map[string][]json.RawMessage{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		calls[req.Method] = append(calls[req.Method], req.Params)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		if result, ok := results[req.Method]; ok {
+			resp["result"] = result
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, calls
+}
+
+func TestFeeEstimator_PricesEachUrgency(t *testing.T) {
+	srv, calls := startFeeNode(t, map[string]interface{}{
+		"eth_feeHistory": map[string]interface{}{
+			"oldestBlock":   "0x10",
+			"baseFeePerGas": []string{"0x1", "0x2", "0x3b9aca00"},
+			"reward": [][]string{
+				{"0x3b9aca00", "0x77359400", "0xb2d05e00"},
+				{"0x3b9aca00", "0x3b9aca00", "0x12a05f200"},
+			},
+		},
+		"eth_estimateGas": "0x5498",
+	})
+	e := NewFeeEstimator(map[string]string{sepolia: srv.URL})
+
+	quote, err := e.Estimate(context.Background(), sepolia, &FeeCall{From: alice, To: bob, Value: big.NewInt(gwei), Data: []byte{0, 1}})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(21656), quote.GasLimit)
+	assert.Equal(t, big.NewInt(gwei), quote.BaseFeePerGas, "the next block's base fee")
+
+	// Tips are the median of each percentile column; max fees leave 1.25x,
+	// 2x and 3x the base fee of headroom
+	assert.Equal(t, GasFees{GasLimit: "21656", MaxPriorityFeePerGas: "1000000000", MaxFeePerGas: "2250000000"}, quote.Fees(UrgencySlow))
+	assert.Equal(t, GasFees{GasLimit: "21656", MaxPriorityFeePerGas: "2000000000", MaxFeePerGas: "4000000000"}, quote.Fees(UrgencyNormal))
+	assert.Equal(t, GasFees{GasLimit: "21656", MaxPriorityFeePerGas: "5000000000", MaxFeePerGas: "8000000000"}, quote.Fees(UrgencyFast))
+
+	require.Len(t, calls["eth_feeHistory"], 1)
+	assert.JSONEq(t, `["0xa", "latest", [10, 50, 90]]`, string(calls["eth_feeHistory"][0]))
+	require.Len(t, calls["eth_estimateGas"], 1)
+	assert.JSONEq(t, `[{"from": "`+alice+`", "to": "`+bob+`", "value": "0x3b9aca00", "data": "0x0001"}]`, string(calls["eth_estimateGas"][0]))
+	assert.Empty(t, calls["eth_maxPriorityFeePerGas"], "the node is not asked when blocks carry tips")
+}
+
+func TestFeeEstimator_FallsBackToNodeTip(t *testing.T) {
+	srv, calls := startFeeNode(t, map[string]interface{}{
+		"eth_feeHistory": map[string]interface{}{
+			"baseFeePerGas": []string{"0x64", "0x64"},
+			// Quiet testnet blocks: nobody tips at the lower percentiles
+			"reward": [][]string{{"0x0", "0x0", "0x3b9aca00"}},
+		},
+		"eth_maxPriorityFeePerGas": "0x77359400",
+		"eth_estimateGas":          "0x5208",
+	})
+	e := NewFeeEstimator(map[string]string{sepolia: srv.URL})
+
+	quote, err := e.Estimate(context.Background(), sepolia, &FeeCall{To: bob})
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2*gwei), quote.Tips[UrgencySlow])
+	assert.Equal(t, big.NewInt(2*gwei), quote.Tips[UrgencyNormal])
+	assert.Equal(t, big.NewInt(2*gwei), quote.Tips[UrgencyFast], "no level tips less than a slower one")
+	assert.Len(t, calls["eth_maxPriorityFeePerGas"], 1)
+	assert.JSONEq(t, `[{"to": "`+bob+`"}]`, string(calls["eth_estimateGas"][0]))
+}
+
+func TestFeeEstimator_Errors(t *testing.T) {
+	e := NewFeeEstimator(map[string]string{})
+	_, err := e.Estimate(context.Background(), amoy, &FeeCall{To: bob})
+	assert.ErrorIs(t, err, ErrNoRPC)
+
+	srv, _ := startFeeNode(t, map[string]interface{}{
+		"eth_feeHistory": map[string]interface{}{"baseFeePerGas": []string{"0x64"}, "reward": [][]string{{"0x1", "0x2", "0x3"}}},
+	})
+	e = NewFeeEstimator(map[string]string{sepolia: srv.URL})
+	_, err = e.Estimate(context.Background(), sepolia, &FeeCall{To: bob})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "eth_estimateGas")
+}
+
+func TestParseUrgency(t *testing.T) {
+	for in, want := range map[string]Urgency{"": UrgencyNormal, "slow": UrgencySlow, " Fast ": UrgencyFast} {
+		u, err := ParseUrgency(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, u)
+	}
+	_, err := ParseUrgency("asap")
+	assert.Error(t, err)
+}
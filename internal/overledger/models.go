@@ -104,6 +104,8 @@ type TransactionPrepareResponse struct {
 	PreparationTransactionSearchResponse PreparationTransactionSearchResponse `json:"preparationTransactionSearchResponse"`
 	GatewayFee                          GatewayFee                          `json:"gatewayFee,omitempty"`
 	DltData                             DltData                             `json:"dltData"`
+	// RequestID is set when Overledger returns the requestId at the top level
+	RequestID string `json:"requestId,omitempty"`
 }
 
 // PreparationTransactionSearchResponse contains preparation details
@@ -173,6 +175,8 @@ type SandboxSigningRequest struct {
 	DltFee    GatewayFee `json:"dltFee"`
 	TransactionSigningResponderName string `json:"transactionSigningResponderName"`
 	NativeData NativeData `json:"nativeData"`
+	// FromAddress is the transaction sender; it is not part of the signing body
+	FromAddress string `json:"-"`
 }
 
 // NativeData represents the native transaction data for signing
@@ -207,6 +211,7 @@ type TransactionRequest struct {
 	MaxFeePerGas string                `json:"maxFeePerGas,omitempty"`
 	MaxPriorityFeePerGas string        `json:"maxPriorityFeePerGas,omitempty"`
 	Nonce       *int                   `json:"nonce,omitempty"`
+	Urgency     string                 `json:"urgency,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -225,6 +230,32 @@ type TransactionResponse struct {
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// FeeEstimateRequest describes a payment whose fees are estimated before it
+// is submitted
+type FeeEstimateRequest struct {
+	NetworkID   string `json:"networkId" binding:"required"`
+	FromAddress string `json:"fromAddress"`
+	ToAddress   string `json:"toAddress" binding:"required"`
+	Amount      string `json:"amount"`
+}
+
+// FeeEstimate is the fees of a payment at one urgency level
+type FeeEstimate struct {
+	Urgency Urgency `json:"urgency"`
+	GasFees
+	// MaxFee is gasLimit * maxFeePerGas in the network's native unit
+	MaxFee string `json:"maxFee"`
+}
+
+// FeeEstimateResponse represents the response from the fee estimate endpoint
+type FeeEstimateResponse struct {
+	NetworkID     string        `json:"networkId"`
+	Location      Location      `json:"location"`
+	Unit          string        `json:"unit"`
+	BaseFeePerGas string        `json:"baseFeePerGas"`
+	Estimates     []FeeEstimate `json:"estimates"`
+}
+
 // TransactionStatusResponse represents the response from transaction status endpoint
 type TransactionStatusResponse struct {
 	TransactionID string                 `json:"transactionId"`
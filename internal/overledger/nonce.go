@@ -0,0 +1,231 @@
+package overledger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NonceSource reports the next nonce of an address from the chain
+type NonceSource interface {
+	PendingNonce(ctx context.Context, network, address string) (uint64, error)
+}
+
+// rpcNonceSource reads eth_getTransactionCount(address, "pending") from the
+// JSON-RPC endpoints of each Overledger location network
+type rpcNonceSource struct {
+	rpc *nodeRPC
+}
+
+// NewRPCNonceSource returns a NonceSource for urls, a map from Overledger
+// location network (e.g. "ethereum sepolia testnet") to comma-separated
+// JSON-RPC endpoints tried in order
+func NewRPCNonceSource(urls map[string]string) NonceSource {
+	return &rpcNonceSource{rpc: newNodeRPC(urls)}
+}
+
+func (s *rpcNonceSource) PendingNonce(ctx context.Context, network, address string) (uint64, error) {
+	var count string
+	if err := s.rpc.call(ctx, network, "eth_getTransactionCount", []interface{}{address, "pending"}, &count); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimPrefix(count, "0x"), 16, 64)
+}
+
+// nonceAccount is the nonce state of one address on one network
+type nonceAccount struct {
+	// Next is the lowest nonce never handed out
+	Next uint64 `json:"next"`
+	// Released are nonces below Next whose submissions failed; they are
+	// handed out again before Next so no gap is left on chain
+	Released []uint64 `json:"released,omitempty"`
+
+	synced bool
+}
+
+// NonceManager hands out nonces per network and address. Each account is
+// seeded from the chain's pending transaction count the first time it is
+// used, even when the persisted state is ahead of it; networks without an RPC
+// endpoint continue from the persisted state.
+// Reservations are made under a mutex, so concurrent transfers from the same
+// account get distinct nonces, and the state is written atomically to a file
+// after every change.
+type NonceManager struct {
+	mu       sync.Mutex
+	source   NonceSource
+	path     string
+	accounts map[string]*nonceAccount
+}
+
+// NewNonceManager loads the nonce state persisted at path, which may not
+// exist yet. An empty path keeps the state in memory only.
+func NewNonceManager(path string, source NonceSource) (*NonceManager, error) {
+	m := &NonceManager{source: source, path: path, accounts: map[string]*nonceAccount{}}
+	if path == "" {
+		return m, nil
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nonce state: %w", err)
+	}
+	if err := json.Unmarshal(b, &m.accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse nonce state %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func nonceKey(network, address string) string {
+	return network + "/" + strings.ToLower(address)
+}
+
+// Reserve returns the next nonce for address on network. The nonce stays
+// reserved until it is released.
+func (m *NonceManager) Reserve(ctx context.Context, network, address string) (uint64, error) {
+	if err := m.sync(ctx, network, address); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acct := m.accounts[nonceKey(network, address)]
+	var nonce uint64
+	if len(acct.Released) > 0 {
+		nonce, acct.Released = acct.Released[0], acct.Released[1:]
+	} else {
+		nonce = acct.Next
+		acct.Next++
+	}
+	return nonce, m.save()
+}
+
+// Release returns a nonce whose transaction was not submitted so it is
+// handed out again.
+func (m *NonceManager) Release(network, address string, nonce uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acct, ok := m.accounts[nonceKey(network, address)]
+	if !ok || nonce >= acct.Next {
+		return nil
+	}
+	if nonce == acct.Next-1 {
+		acct.Next--
+	} else {
+		for _, n := range acct.Released {
+			if n == nonce {
+				return nil
+			}
+		}
+		acct.Released = append(acct.Released, nonce)
+		sort.Slice(acct.Released, func(i, j int) bool { return acct.Released[i] < acct.Released[j] })
+	}
+	// Trailing released nonces fold back into Next
+	for len(acct.Released) > 0 && acct.Released[len(acct.Released)-1] == acct.Next-1 {
+		acct.Released = acct.Released[:len(acct.Released)-1]
+		acct.Next--
+	}
+	return m.save()
+}
+
+// Resync replaces the state of address on network with the chain's pending
+// transaction count, as needed after a "nonce too low" rejection.
+func (m *NonceManager) Resync(ctx context.Context, network, address string) error {
+	if m.source == nil {
+		return fmt.Errorf("failed to resync nonce: %w: %s", ErrNoRPC, network)
+	}
+	n, err := m.source.PendingNonce(ctx, network, address)
+	if err != nil {
+		return fmt.Errorf("failed to resync nonce: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts[nonceKey(network, address)] = &nonceAccount{Next: n, synced: true}
+	return m.save()
+}
+
+// sync seeds the state of address on network from the chain the first time
+// it is used in this process. The node is asked without holding m.mu, so a
+// slow node does not hold up other accounts.
+func (m *NonceManager) sync(ctx context.Context, network, address string) error {
+	key := nonceKey(network, address)
+	m.mu.Lock()
+	acct, ok := m.accounts[key]
+	synced := ok && acct.synced
+	m.mu.Unlock()
+	if synced {
+		return nil
+	}
+
+	var pending uint64
+	fetched := false
+	if m.source != nil {
+		n, err := m.source.PendingNonce(ctx, network, address)
+		switch {
+		case err == nil:
+			pending, fetched = n, true
+		case !errors.Is(err, ErrNoRPC):
+			return fmt.Errorf("failed to sync nonce for %s on %s: %w", address, network, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acct, ok = m.accounts[key]
+	if ok && acct.synced {
+		// another transfer synced the account first
+		return nil
+	}
+	if !ok {
+		acct = &nonceAccount{}
+	}
+	if fetched {
+		// Nothing this process reserved is in flight yet, so the chain is
+		// right whichever way it differs: nonces a previous run reserved
+		// but never broadcast are handed out again, and released nonces are
+		// either used on chain or at or above the pending count
+		acct.Next = pending
+		acct.Released = nil
+	}
+	acct.synced = true
+	m.accounts[key] = acct
+	return nil
+}
+
+// save writes the state to a temporary file and renames it over m.path. The
+// caller holds m.mu.
+func (m *NonceManager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(m.accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to persist nonce state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to persist nonce state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to persist nonce state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("failed to persist nonce state: %w", err)
+	}
+	return nil
+}
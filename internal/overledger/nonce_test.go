@@ -0,0 +1,279 @@
+package overledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sepolia = "ethereum sepolia testnet"
+	amoy    = "polygon amoy testnet"
+	alice   = "0x1234567890abcdef1234567890abcdef12345678"
+	bob     = "0xabcdef1234567890abcdef1234567890abcdef12"
+)
+
+// stubNode serves eth_getTransactionCount from a pending count per address
+type stubNode struct {
+	mu      sync.Mutex
+	pending map[string]uint64
+	calls   int
+}
+
+func startStubNode(t *testing.T, pending map[string]uint64) (*httptest.Server, *stubNode) {
+	t.Helper()
+	node := &stubNode{pending: pending}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "eth_getTransactionCount", req.Method)
+		require.Equal(t, "pending", req.Params[1])
+
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		node.calls++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  fmt.Sprintf("0x%x", node.pending[req.Params[0]]),
+		})
+	}))
+	return srv, node
+}
+
+func (n *stubNode) set(address string, pending uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending[address] = pending
+}
+
+func TestNonceManager_SeedsPerNetworkAndAddress(t *testing.T) {
+	srv, node := startStubNode(t, map[string]uint64{alice: 5, bob: 2})
+	defer srv.Close()
+	m, err := NewNonceManager("", NewRPCNonceSource(map[string]string{sepolia: srv.URL}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, want := range []uint64{5, 6, 7} {
+		n, err := m.Reserve(ctx, sepolia, alice)
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	n, err := m.Reserve(ctx, sepolia, bob)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), n)
+	assert.Equal(t, 2, node.calls, "each account is synced once")
+
+	// Addresses are case-insensitive; networks without an RPC count locally
+	n, err = m.Reserve(ctx, sepolia, "0x1234567890ABCDEF1234567890ABCDEF12345678")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), n)
+	n, err = m.Reserve(ctx, amoy, alice)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), n)
+}
+
+func TestNonceManager_ConcurrentReservationsAreDistinct(t *testing.T) {
+	srv, _ := startStubNode(t, map[string]uint64{alice: 10, bob: 0})
+	defer srv.Close()
+	m, err := NewNonceManager(filepath.Join(t.TempDir(), "nonces.json"), NewRPCNonceSource(map[string]string{sepolia: srv.URL}))
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	got := map[string][]uint64{}
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		address := []string{alice, bob}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := m.Reserve(context.Background(), sepolia, address)
+			assert.NoError(t, err)
+			mu.Lock()
+			got[address] = append(got[address], n)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for address, first := range map[string]uint64{alice: 10, bob: 0} {
+		nonces := got[address]
+		sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+		require.Len(t, nonces, 20)
+		for i, n := range nonces {
+			assert.Equal(t, first+uint64(i), n, address)
+		}
+	}
+}
+
+func TestNonceManager_ReleaseHandsNoncesOutAgain(t *testing.T) {
+	m, err := NewNonceManager("", nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_, err := m.Reserve(ctx, sepolia, alice)
+		require.NoError(t, err)
+	}
+
+	// A gap is filled before new nonces are handed out
+	require.NoError(t, m.Release(sepolia, alice, 1))
+	n, err := m.Reserve(ctx, sepolia, alice)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+	n, err = m.Reserve(ctx, sepolia, alice)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), n)
+
+	// Releasing the newest nonces rewinds, folding in released ones below
+	require.NoError(t, m.Release(sepolia, alice, 3))
+	require.NoError(t, m.Release(sepolia, alice, 4))
+	n, err = m.Reserve(ctx, sepolia, alice)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), n)
+
+	// Unknown nonces and accounts are ignored
+	require.NoError(t, m.Release(sepolia, alice, 99))
+	require.NoError(t, m.Release(amoy, bob, 0))
+}
+
+func TestNonceManager_PersistsAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nonces.json")
+	ctx := context.Background()
+
+	m, err := NewNonceManager(path, nil)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := m.Reserve(ctx, amoy, alice)
+		require.NoError(t, err)
+	}
+	require.NoError(t, m.Release(amoy, alice, 0))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary files are left behind")
+
+	reloaded, err := NewNonceManager(path, nil)
+	require.NoError(t, err)
+	for _, want := range []uint64{0, 3} {
+		n, err := reloaded.Reserve(ctx, amoy, alice)
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = NewNonceManager(path, nil)
+	assert.Error(t, err)
+}
+
+func TestNonceManager_SyncsPersistedStateWithChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	srv, node := startStubNode(t, map[string]uint64{alice: 0})
+	defer srv.Close()
+	source := NewRPCNonceSource(map[string]string{sepolia: srv.URL})
+	ctx := context.Background()
+
+	m, err := NewNonceManager(path, source)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := m.Reserve(ctx, sepolia, alice)
+		require.NoError(t, err)
+	}
+	require.NoError(t, m.Release(sepolia, alice, 1))
+
+	// Another wallet instance sent transactions meanwhile; the chain wins
+	node.set(alice, 6)
+	m, err = NewNonceManager(path, source)
+	require.NoError(t, err)
+	n, err := m.Reserve(ctx, sepolia, alice)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), n)
+
+	// Resync replaces local state after a "nonce too low" rejection
+	node.set(alice, 9)
+	require.NoError(t, m.Resync(ctx, sepolia, alice))
+	n, err = m.Reserve(ctx, sepolia, alice)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), n)
+
+	// Nonce 9 was reserved but never broadcast: after a restart the chain
+	// still expects it, and it is not skipped
+	m, err = NewNonceManager(path, source)
+	require.NoError(t, err)
+	n, err = m.Reserve(ctx, sepolia, alice)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), n)
+
+	assert.ErrorIs(t, m.Resync(ctx, amoy, alice), ErrNoRPC)
+}
+
+// blockingSource holds PendingNonce for alice until release is closed
+type blockingSource struct {
+	started, release chan struct{}
+}
+
+func (s *blockingSource) PendingNonce(ctx context.Context, network, address string) (uint64, error) {
+	if address == alice {
+		close(s.started)
+		<-s.release
+	}
+	return 3, nil
+}
+
+func TestNonceManager_SlowNodeDoesNotBlockOtherAccounts(t *testing.T) {
+	source := &blockingSource{started: make(chan struct{}), release: make(chan struct{})}
+	m, err := NewNonceManager("", source)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	done := make(chan uint64)
+	go func() {
+		n, err := m.Reserve(ctx, sepolia, alice)
+		assert.NoError(t, err)
+		done <- n
+	}()
+	<-source.started
+	other := make(chan uint64)
+	go func() {
+		n, err := m.Reserve(ctx, sepolia, bob)
+		assert.NoError(t, err)
+		other <- n
+	}()
+	select {
+	case n := <-other:
+		assert.Equal(t, uint64(3), n)
+		close(source.release)
+	case <-time.After(time.Second):
+		close(source.release)
+		<-other
+		t.Error("bob waited for alice's node call")
+	}
+	assert.Equal(t, uint64(3), <-done)
+}
+
+func TestNonceManager_NodeErrorsStopReservation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "error": map[string]interface{}{"code": -32000, "message": "header not found"}})
+	}))
+	defer srv.Close()
+
+	m, err := NewNonceManager("", NewRPCNonceSource(map[string]string{sepolia: srv.URL}))
+	require.NoError(t, err)
+	_, err = m.Reserve(context.Background(), sepolia, alice)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "header not found")
+}
@@ -0,0 +1,95 @@
+package overledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNoRPC is returned when no JSON-RPC endpoint is configured for a network
+var ErrNoRPC = errors.New("no rpc endpoint configured for network")
+
+// nodeRPC calls the JSON-RPC endpoints of each Overledger location network
+type nodeRPC struct {
+	urls       map[string][]string
+	httpClient *http.Client
+}
+
+// newNodeRPC takes a map from Overledger location network (e.g. "ethereum
+// sepolia testnet") to comma-separated JSON-RPC endpoints tried in order
+func newNodeRPC(urls map[string]string) *nodeRPC {
+	r := &nodeRPC{urls: map[string][]string{}, httpClient: &http.Client{Timeout: 10 * time.Second}}
+	for network, list := range urls {
+		for _, u := range strings.Split(list, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				r.urls[network] = append(r.urls[network], u)
+			}
+		}
+	}
+	return r
+}
+
+// call sends method to the endpoints of network in order and decodes the
+// result of the first one that answers into out
+func (r *nodeRPC) call(ctx context.Context, network, method string, params []interface{}, out interface{}) error {
+	urls := r.urls[network]
+	if len(urls) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoRPC, network)
+	}
+	errs := []string{}
+	for _, u := range urls {
+		err := r.callOne(ctx, u, method, params, out)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", u, err))
+	}
+	return fmt.Errorf("%s failed: %s", method, strings.Join(errs, " | "))
+}
+
+func (r *nodeRPC) callOne(ctx context.Context, url, method string, params []interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+
+	var decoded struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if decoded.Error != nil {
+		return errors.New(decoded.Error.Message)
+	}
+	if err := json.Unmarshal(decoded.Result, out); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
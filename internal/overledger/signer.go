@@ -0,0 +1,313 @@
+package overledger
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/rutishh0/testingquant/internal/config"
+)
+
+// Signer signs the native data of a prepared transaction and returns the
+// signed transaction Overledger executes
+type Signer interface {
+	Sign(ctx context.Context, req *SandboxSigningRequest) (string, error)
+}
+
+// Signers selectable with OVERLEDGER_SIGNER
+const (
+	SignerSandbox = "sandbox"
+	SignerLocal   = "local"
+	SignerRemote  = "remote"
+)
+
+// newSigner returns the signer cfg selects for c; the sandbox is the default
+func newSigner(cfg *config.Config, c *Client) (Signer, error) {
+	switch kind := strings.ToLower(strings.TrimSpace(cfg.OverledgerSigner)); kind {
+	case "", SignerSandbox:
+		return &sandboxSigner{client: c}, nil
+	case SignerLocal:
+		return LoadLocalSigner(cfg.OverledgerSignerKeyFile, cfg.OverledgerSignerPassphrase)
+	case SignerRemote:
+		if cfg.OverledgerSignerURL == "" {
+			return nil, errors.New("the remote signer needs OVERLEDGER_SIGNER_URL")
+		}
+		return NewRemoteSigner(cfg.OverledgerSignerURL, cfg.OverledgerSignerToken), nil
+	default:
+		return nil, fmt.Errorf("unknown signer %q: must be sandbox, local or remote", kind)
+	}
+}
+
+// unavailableSigner stands in for a configured signer that failed to load.
+// It fails every signature rather than let another signer sign.
+type unavailableSigner struct {
+	err error
+}
+
+func (s unavailableSigner) Sign(ctx context.Context, req *SandboxSigningRequest) (string, error) {
+	return "", fmt.Errorf("signer unavailable: %w", s.err)
+}
+
+// sandboxSigner signs with the Overledger transaction signing sandbox at the
+// host of the configured base URL, using the key named by the request's keyId
+type sandboxSigner struct {
+	client *Client
+}
+
+func (s *sandboxSigner) Sign(ctx context.Context, req *SandboxSigningRequest) (string, error) {
+	var resp SandboxSigningResponse
+	headers := map[string]string{"API-Version": "3.0.0"}
+	if err := s.client.doRequest("POST", s.client.rootURL()+"/api/transaction-signing-sandbox", req, &resp, headers); err != nil {
+		return "", err
+	}
+	return resp.signed()
+}
+
+// signed returns the signed transaction of a signing response
+func (r *SandboxSigningResponse) signed() (string, error) {
+	if r.SignedTransaction != "" {
+		return r.SignedTransaction, nil
+	}
+	if r.Signature != "" {
+		return r.Signature, nil
+	}
+	return "", fmt.Errorf("signing response did not contain signed transaction or signature")
+}
+
+// RemoteSigner posts signing requests to an HTTP signing service. The service
+// takes the sandbox's request body and answers like it, with {"signed": "0x..."}.
+type RemoteSigner struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// NewRemoteSigner returns a signer for the service at url. A non-empty token
+// is sent as a bearer token.
+func NewRemoteSigner(url, token string) *RemoteSigner {
+	return &RemoteSigner{url: url, token: token, httpClient: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (s *RemoteSigner) Sign(ctx context.Context, req *SandboxSigningRequest) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	if s.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach remote signer: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("remote signer error: %d - %s", resp.StatusCode, string(respBody))
+	}
+	var out SandboxSigningResponse
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return out.signed()
+}
+
+// LocalSigner signs EVM transactions in process with a secp256k1 key,
+// producing the RLP-encoded EIP-1559 transaction of the native data
+type LocalSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocalSigner returns a signer for key
+func NewLocalSigner(key *ecdsa.PrivateKey) *LocalSigner {
+	return &LocalSigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// LoadLocalSigner reads the key at path: a PEM secp256k1 private key, in
+// SEC 1 ("EC PRIVATE KEY") or PKCS #8 ("PRIVATE KEY") form, or an encrypted
+// JSON keystore unlocked with passphrase
+func LoadLocalSigner(path, passphrase string) (*LocalSigner, error) {
+	if path == "" {
+		return nil, errors.New("the local signer needs OVERLEDGER_SIGNER_KEY_FILE")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	if bytes.Contains(b, []byte("-----BEGIN")) {
+		key, err := parsePEMKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+		}
+		return NewLocalSigner(key), nil
+	}
+	k, err := keystore.DecryptKey(b, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return NewLocalSigner(k.PrivateKey), nil
+}
+
+// Address returns the address the signer signs for
+func (s *LocalSigner) Address() string {
+	return s.address.Hex()
+}
+
+func (s *LocalSigner) Sign(ctx context.Context, req *SandboxSigningRequest) (string, error) {
+	// The sender decides, whatever keyId names
+	if !common.IsHexAddress(req.FromAddress) || common.HexToAddress(req.FromAddress) != s.address {
+		return "", fmt.Errorf("local key %s cannot sign for %s", s.address.Hex(), req.FromAddress)
+	}
+	tx, err := req.NativeData.transaction()
+	if err != nil {
+		return "", err
+	}
+	signed, err := ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(tx.ChainId()), s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	return hexutil.Encode(raw), nil
+}
+
+// transaction returns the unsigned EIP-1559 transaction of native data
+func (n *NativeData) transaction() (*ethtypes.Transaction, error) {
+	if n.ChainID <= 0 {
+		return nil, errors.New("native data has no EVM chainId")
+	}
+	if !common.IsHexAddress(n.To) {
+		return nil, fmt.Errorf("invalid to address %q", n.To)
+	}
+	if n.Nonce < 0 {
+		return nil, fmt.Errorf("invalid nonce %d", n.Nonce)
+	}
+	gas, err := strconv.ParseUint(n.Gas, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas %q", n.Gas)
+	}
+	amounts := map[string]*big.Int{}
+	for name, v := range map[string]string{"value": n.Value, "maxFeePerGas": n.MaxFeePerGas, "maxPriorityFeePerGas": n.MaxPriorityFeePerGas} {
+		amount, ok := new(big.Int).SetString(v, 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s %q", name, v)
+		}
+		amounts[name] = amount
+	}
+	data, err := hexutil.Decode("0x" + strings.TrimPrefix(n.Data, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %w", err)
+	}
+	to := common.HexToAddress(n.To)
+	return ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(n.ChainID)),
+		Nonce:     uint64(n.Nonce),
+		GasTipCap: amounts["maxPriorityFeePerGas"],
+		GasFeeCap: amounts["maxFeePerGas"],
+		Gas:       gas,
+		To:        &to,
+		Value:     amounts["value"],
+		Data:      data,
+	}), nil
+}
+
+// OIDs of EC public keys and the secp256k1 curve
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// ecPrivateKey is a SEC 1 EC private key; crypto/x509 does not parse
+// secp256k1 keys
+type ecPrivateKey struct {
+	Version    int
+	PrivateKey []byte
+	Curve      asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey  asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkcs8 is a PKCS #8 private key wrapping a SEC 1 one
+type pkcs8 struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// parsePEMKey returns the first secp256k1 private key in PEM data, skipping
+// blocks such as "EC PARAMETERS"
+func parsePEMKey(data []byte) (*ecdsa.PrivateKey, error) {
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no EC PRIVATE KEY or PRIVATE KEY block")
+		}
+		data = rest
+		switch block.Type {
+		case "EC PRIVATE KEY":
+			return parseSEC1Key(block.Bytes, nil)
+		case "PRIVATE KEY":
+			var p pkcs8
+			if _, err := asn1.Unmarshal(block.Bytes, &p); err != nil {
+				return nil, fmt.Errorf("invalid PKCS #8 key: %w", err)
+			}
+			if !p.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+				return nil, fmt.Errorf("PKCS #8 key is not an EC key (%s)", p.Algorithm.Algorithm)
+			}
+			var curve asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(p.Algorithm.Parameters.FullBytes, &curve); err != nil {
+				return nil, fmt.Errorf("invalid PKCS #8 curve: %w", err)
+			}
+			return parseSEC1Key(p.PrivateKey, curve)
+		}
+	}
+}
+
+// parseSEC1Key parses a SEC 1 private key on curve, or on the curve it names
+// when curve is nil
+func parseSEC1Key(der []byte, curve asn1.ObjectIdentifier) (*ecdsa.PrivateKey, error) {
+	var k ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, fmt.Errorf("invalid EC private key: %w", err)
+	}
+	if curve == nil {
+		curve = k.Curve
+	}
+	if !curve.Equal(oidSecp256k1) {
+		return nil, fmt.Errorf("key is not on secp256k1 (curve %s)", curve)
+	}
+	if len(k.PrivateKey) > 32 {
+		return nil, errors.New("invalid secp256k1 private key length")
+	}
+	return crypto.ToECDSA(common.LeftPadBytes(k.PrivateKey, 32))
+}
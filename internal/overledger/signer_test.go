@@ -0,0 +1,236 @@
+package overledger
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rutishh0/testingquant/internal/config"
+)
+
+func signingRequestFor(from string) *SandboxSigningRequest {
+	return &SandboxSigningRequest{
+		KeyID:       from,
+		FromAddress: from,
+		RequestID:   "req-1",
+		NativeData: NativeData{
+			Chain:                "testnet",
+			Data:                 messageData(transactionMessage),
+			ChainID:              11155111,
+			Gas:                  "21656",
+			MaxPriorityFeePerGas: "2000000000",
+			MaxFeePerGas:         "4000000000",
+			To:                   bob,
+			Nonce:                7,
+			Hardfork:             "london",
+			Value:                "1000000000000000",
+		},
+	}
+}
+
+func TestLocalSigner_SignsNativeData(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewLocalSigner(key)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	assert.Equal(t, from.Hex(), signer.Address())
+
+	signed, err := signer.Sign(context.Background(), signingRequestFor(from.Hex()))
+	require.NoError(t, err)
+
+	raw, err := hexutil.Decode(signed)
+	require.NoError(t, err)
+	tx := new(ethtypes.Transaction)
+	require.NoError(t, tx.UnmarshalBinary(raw))
+	assert.Equal(t, uint8(ethtypes.DynamicFeeTxType), tx.Type())
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(tx.ChainId()), tx)
+	require.NoError(t, err)
+	assert.Equal(t, from, sender)
+	assert.Equal(t, big.NewInt(11155111), tx.ChainId())
+	assert.Equal(t, uint64(7), tx.Nonce())
+	assert.Equal(t, uint64(21656), tx.Gas())
+	assert.Equal(t, big.NewInt(2_000_000_000), tx.GasTipCap())
+	assert.Equal(t, big.NewInt(4_000_000_000), tx.GasFeeCap())
+	assert.Equal(t, common.HexToAddress(bob), *tx.To())
+	assert.Equal(t, big.NewInt(1_000_000_000_000_000), tx.Value())
+	assert.Equal(t, "0x"+messageData(transactionMessage), hexutil.Encode(tx.Data()))
+
+	// A sandbox key name still signs for the key's own sender
+	req := signingRequestFor(from.Hex())
+	req.KeyID = "sandbox-key"
+	_, err = signer.Sign(context.Background(), req)
+	assert.NoError(t, err)
+}
+
+func TestLocalSigner_Rejects(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewLocalSigner(key)
+	from := signer.Address()
+
+	_, err = signer.Sign(context.Background(), signingRequestFor(alice))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot sign for "+alice)
+
+	// A non-address keyId does not skip the sender check
+	req := signingRequestFor(alice)
+	req.KeyID = "sandbox-key"
+	_, err = signer.Sign(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot sign for "+alice)
+
+	_, err = signer.Sign(context.Background(), &SandboxSigningRequest{KeyID: from, NativeData: signingRequestFor(from).NativeData})
+	assert.Error(t, err, "no sender")
+
+	for name, mutate := range map[string]func(n *NativeData){
+		"no EVM chainId": func(n *NativeData) { n.ChainID = 0 },
+		"invalid to":     func(n *NativeData) { n.To = "rXRPL" },
+		"invalid gas":    func(n *NativeData) { n.Gas = "" },
+		"invalid value":  func(n *NativeData) { n.Value = "0.001" },
+		"invalid data":   func(n *NativeData) { n.Data = "0xzz" },
+	} {
+		req := signingRequestFor(from)
+		mutate(&req.NativeData)
+		_, err := signer.Sign(context.Background(), req)
+		assert.Error(t, err, name)
+	}
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+// sec1Key encodes key as an OpenSSL "EC PRIVATE KEY", with the curve named
+// unless it is carried by an enclosing PKCS #8 key
+func sec1Key(t *testing.T, key []byte, named bool) []byte {
+	t.Helper()
+	k := ecPrivateKey{Version: 1, PrivateKey: key}
+	if named {
+		k.Curve = oidSecp256k1
+	}
+	der, err := asn1.Marshal(k)
+	require.NoError(t, err)
+	return der
+}
+
+func TestLoadLocalSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	want := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	raw := crypto.FromECDSA(key)
+
+	t.Run("SEC 1 PEM after EC PARAMETERS", func(t *testing.T) {
+		params, err := asn1.Marshal(oidSecp256k1)
+		require.NoError(t, err)
+		data := append(pem.EncodeToMemory(&pem.Block{Type: "EC PARAMETERS", Bytes: params}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1Key(t, raw, true)})...)
+		signer, err := LoadLocalSigner(writeFile(t, "key.pem", data), "")
+		require.NoError(t, err)
+		assert.Equal(t, want, signer.Address())
+	})
+
+	t.Run("PKCS 8 PEM", func(t *testing.T) {
+		curve, err := asn1.Marshal(oidSecp256k1)
+		require.NoError(t, err)
+		der, err := asn1.Marshal(pkcs8{
+			Algorithm:  pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: curve}},
+			PrivateKey: sec1Key(t, raw, false),
+		})
+		require.NoError(t, err)
+		signer, err := LoadLocalSigner(writeFile(t, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), "")
+		require.NoError(t, err)
+		assert.Equal(t, want, signer.Address())
+	})
+
+	t.Run("encrypted keystore", func(t *testing.T) {
+		id, err := uuid.NewRandom()
+		require.NoError(t, err)
+		ks := &keystore.Key{Id: id, Address: crypto.PubkeyToAddress(key.PublicKey), PrivateKey: key}
+		data, err := keystore.EncryptKey(ks, "secret", keystore.LightScryptN, keystore.LightScryptP)
+		require.NoError(t, err)
+		path := writeFile(t, "keystore.json", data)
+
+		signer, err := LoadLocalSigner(path, "secret")
+		require.NoError(t, err)
+		assert.Equal(t, want, signer.Address())
+
+		_, err = LoadLocalSigner(path, "wrong")
+		assert.Error(t, err)
+	})
+
+	t.Run("other curves", func(t *testing.T) {
+		k := ecPrivateKey{Version: 1, PrivateKey: raw, Curve: asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}}
+		der, err := asn1.Marshal(k)
+		require.NoError(t, err)
+		_, err = LoadLocalSigner(writeFile(t, "p256.pem", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "secp256k1")
+	})
+}
+
+func TestRemoteSigner(t *testing.T) {
+	var got SandboxSigningRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "unauthorized"}`))
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		json.NewEncoder(w).Encode(map[string]string{"signed": "0x02f8"})
+	}))
+	defer srv.Close()
+
+	signed, err := NewRemoteSigner(srv.URL, "s3cret").Sign(context.Background(), signingRequestFor(alice))
+	require.NoError(t, err)
+	assert.Equal(t, "0x02f8", signed)
+	want := *signingRequestFor(alice)
+	want.FromAddress = ""
+	assert.Equal(t, want, got)
+
+	_, err = NewRemoteSigner(srv.URL, "").Sign(context.Background(), signingRequestFor(alice))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestNewClient_SignerFromConfig(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyFile := writeFile(t, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1Key(t, crypto.FromECDSA(key), true)}))
+
+	assert.IsType(t, &sandboxSigner{}, NewClient(&config.Config{}).signer)
+	assert.IsType(t, &LocalSigner{}, NewClient(&config.Config{OverledgerSigner: "local", OverledgerSignerKeyFile: keyFile}).signer)
+	assert.IsType(t, &RemoteSigner{}, NewClient(&config.Config{OverledgerSigner: "remote", OverledgerSignerURL: "http://signer"}).signer)
+
+	// A signer that fails to load fails closed instead of falling back
+	for _, cfg := range []*config.Config{
+		{OverledgerSigner: "local"},
+		{OverledgerSigner: "local", OverledgerSignerKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{OverledgerSigner: "remote"},
+		{OverledgerSigner: "hsm"},
+	} {
+		_, err := NewClient(cfg).SignTransaction(signingRequestFor(alice))
+		require.Error(t, err, cfg.OverledgerSigner)
+		assert.Contains(t, err.Error(), "signer unavailable")
+	}
+}
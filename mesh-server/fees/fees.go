@@ -0,0 +1,125 @@
+// Package fees prices EIP-1559 transactions at an urgency level from a node's
+// eth_feeHistory. It holds no RPC client: callers fetch the history and the
+// node's tip suggestion themselves.
+package fees
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Urgency is how quickly a transaction should be included.
+type Urgency string
+
+const (
+	Slow   Urgency = "slow"
+	Normal Urgency = "normal"
+	Fast   Urgency = "fast"
+)
+
+// Urgencies lists the urgency levels from slowest to fastest.
+var Urgencies = []Urgency{Slow, Normal, Fast}
+
+// HistoryBlocks is the number of recent blocks tips are sampled from.
+const HistoryBlocks = 10
+
+// policy prices an urgency level: the tip is the given reward percentile of
+// recent blocks, and the max fee leaves headroom for the next base fee to grow
+// to num/den of its value.
+type policy struct {
+	percentile int
+	num, den   int64
+}
+
+var policies = map[Urgency]policy{
+	Slow:   {percentile: 10, num: 5, den: 4},
+	Normal: {percentile: 50, num: 2, den: 1},
+	Fast:   {percentile: 90, num: 3, den: 1},
+}
+
+// Valid reports whether u is a known urgency level.
+func (u Urgency) Valid() bool {
+	_, ok := policies[u]
+	return ok
+}
+
+// HistoryParams returns the eth_feeHistory params that sample the reward
+// percentile of every urgency level, in Urgencies order.
+func HistoryParams() []interface{} {
+	percentiles := make([]int, len(Urgencies))
+	for i, u := range Urgencies {
+		percentiles[i] = policies[u].percentile
+	}
+	return []interface{}{hexutil.Uint64(HistoryBlocks), "latest", percentiles}
+}
+
+// History is the result of eth_feeHistory called with HistoryParams.
+type History struct {
+	BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+	Reward        [][]*hexutil.Big `json:"reward"`
+}
+
+// NextBaseFee returns the base fee of the block after the sampled range, or
+// nil when the history has none.
+func (h *History) NextBaseFee() *big.Int {
+	if len(h.BaseFeePerGas) == 0 || h.BaseFeePerGas[len(h.BaseFeePerGas)-1] == nil {
+		return nil
+	}
+	return h.BaseFeePerGas[len(h.BaseFeePerGas)-1].ToInt()
+}
+
+// Tips returns the priority fee per gas of each urgency level: the median of
+// its percentile over the sampled blocks. Levels the blocks give no tip for
+// use suggested, which is called at most once, and no level tips less than a
+// slower one.
+func (h *History) Tips(suggested func() (*big.Int, error)) (map[Urgency]*big.Int, error) {
+	tips := map[Urgency]*big.Int{}
+	var fallback *big.Int
+	floor := new(big.Int)
+	for i, u := range Urgencies {
+		tip := h.medianReward(i)
+		if tip.Sign() == 0 {
+			if fallback == nil {
+				s, err := suggested()
+				if err != nil {
+					return nil, err
+				}
+				fallback = s
+			}
+			tip = new(big.Int).Set(fallback)
+		}
+		if tip.Cmp(floor) < 0 {
+			tip = new(big.Int).Set(floor)
+		}
+		tips[u] = tip
+		floor = tip
+	}
+	return tips, nil
+}
+
+// medianReward returns the median over the sampled blocks of the reward at
+// percentile index i, or zero when the blocks have no rewards.
+func (h *History) medianReward(i int) *big.Int {
+	values := []*big.Int{}
+	for _, block := range h.Reward {
+		if i < len(block) && block[i] != nil {
+			values = append(values, block[i].ToInt())
+		}
+	}
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+	sort.Slice(values, func(a, b int) bool { return values[a].Cmp(values[b]) < 0 })
+	return new(big.Int).Set(values[len(values)/2])
+}
+
+// MaxFee returns the max fee per gas at urgency u: tip plus room for baseFee
+// to grow before the transaction is included.
+func MaxFee(u Urgency, baseFee, tip *big.Int) *big.Int {
+	p := policies[u]
+	maxFee := new(big.Int).Mul(baseFee, big.NewInt(p.num))
+	maxFee.Quo(maxFee, big.NewInt(p.den))
+	return maxFee.Add(maxFee, tip)
+}
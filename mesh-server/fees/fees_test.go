@@ -0,0 +1,67 @@
+package fees
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func history(t *testing.T, raw string) *History {
+	t.Helper()
+	var h History
+	require.NoError(t, json.Unmarshal([]byte(raw), &h))
+	return &h
+}
+
+func TestHistoryParams(t *testing.T) {
+	raw, err := json.Marshal(HistoryParams())
+	require.NoError(t, err)
+	assert.JSONEq(t, `["0xa", "latest", [10, 50, 90]]`, string(raw))
+}
+
+func TestTips_MedianOfEachPercentile(t *testing.T) {
+	h := history(t, `{
+		"baseFeePerGas": ["0x1", "0x64"],
+		"reward": [["0x1", "0x14", "0x1e"], ["0x3", "0x28", "0x32"], ["0x2", "0xa", "0x28"]]
+	}`)
+	assert.Equal(t, big.NewInt(100), h.NextBaseFee())
+
+	tips, err := h.Tips(func() (*big.Int, error) {
+		t.Fatal("the node is not asked when blocks carry tips")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[Urgency]*big.Int{Slow: big.NewInt(2), Normal: big.NewInt(20), Fast: big.NewInt(40)}, tips)
+}
+
+func TestTips_SuggestedAndFloor(t *testing.T) {
+	// Quiet blocks: nobody tips at the lower percentiles
+	h := history(t, `{"baseFeePerGas": ["0x64"], "reward": [["0x0", "0x0", "0x5"]]}`)
+	asked := 0
+	tips, err := h.Tips(func() (*big.Int, error) {
+		asked++
+		return big.NewInt(10), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, asked)
+	assert.Equal(t, big.NewInt(10), tips[Slow])
+	assert.Equal(t, big.NewInt(10), tips[Normal])
+	assert.Equal(t, big.NewInt(10), tips[Fast], "no level tips less than a slower one")
+
+	_, err = (&History{}).Tips(func() (*big.Int, error) { return nil, errors.New("down") })
+	assert.EqualError(t, err, "down")
+	assert.Nil(t, (&History{}).NextBaseFee())
+}
+
+func TestMaxFee(t *testing.T) {
+	base, tip := big.NewInt(100), big.NewInt(7)
+	assert.Equal(t, big.NewInt(132), MaxFee(Slow, base, tip))
+	assert.Equal(t, big.NewInt(207), MaxFee(Normal, base, tip))
+	assert.Equal(t, big.NewInt(307), MaxFee(Fast, base, tip))
+	assert.True(t, Fast.Valid())
+	assert.False(t, Urgency("asap").Valid())
+}
@@ -1,20 +1,28 @@
 package main
 
 import (
+    "context"
+    "errors"
     "log"
     "net/http"
     "os"
+    "os/signal"
     "strings"
+    "syscall"
+    "time"
 
     "github.com/coinbase/rosetta-sdk-go/asserter"
     "github.com/coinbase/rosetta-sdk-go/server"
-    "github.com/coinbase/rosetta-sdk-go/types"
     "github.com/gin-contrib/cors"
     "github.com/gin-gonic/gin"
     "github.com/joho/godotenv"
     "github.com/rutishh0/mesh-server/services"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish on
+// shutdown.
+const shutdownTimeout = 10 * time.Second
+
 func getPort() string {
     p := os.Getenv("PORT")
     if p == "" {
@@ -26,35 +34,58 @@ func getPort() string {
 // NewBlockchainRouter creates a Mux http.Handler from a collection
 // of server controllers.
 func NewBlockchainRouter(
-	network *types.NetworkIdentifier,
+	networks *services.Networks,
 	asserter *asserter.Asserter,
 ) http.Handler {
-    // Initialize Ethereum RPC client from environment (INFURA_RPC_URL or ETH_RPC_URL)
-    rpc, rpcErr := services.NewEthRPCFromEnv()
-    if rpcErr != nil {
-        log.Printf("Mesh RPC not configured or unreachable, using mock fallback: %v", rpcErr)
-        rpc = nil
-    }
-
-	networkAPIService := services.NewNetworkAPIService(network, rpc)
+	networkAPIService := services.NewNetworkAPIService(networks)
 	networkAPIController := server.NewNetworkAPIController(
 		networkAPIService,
 		asserter,
 	)
 
-	blockAPIService := services.NewBlockAPIService(network, rpc)
+	blockAPIService := services.NewBlockAPIService(networks)
 	blockAPIController := server.NewBlockAPIController(
 		blockAPIService,
 		asserter,
 	)
 
-	accountAPIService := services.NewAccountAPIService(network, rpc)
+	accountAPIService := services.NewAccountAPIService(networks)
 	accountAPIController := server.NewAccountAPIController(
 		accountAPIService,
 		asserter,
 	)
 
-	return server.NewRouter(networkAPIController, blockAPIController, accountAPIController)
+	constructionAPIService := services.NewConstructionAPIService(networks)
+	constructionAPIController := server.NewConstructionAPIController(
+		constructionAPIService,
+		asserter,
+	)
+
+	mempoolAPIService := services.NewMempoolAPIService(networks)
+	mempoolAPIController := server.NewMempoolAPIController(
+		mempoolAPIService,
+		asserter,
+	)
+
+	callAPIService := services.NewCallAPIService(networks)
+	callAPIController := server.NewCallAPIController(
+		callAPIService,
+		asserter,
+	)
+
+	eventsAPIService := services.NewEventsAPIService(networks)
+	eventsAPIController := server.NewEventsAPIController(
+		eventsAPIService,
+		asserter,
+	)
+
+	searchAPIService := services.NewSearchAPIService(networks)
+	searchAPIController := server.NewSearchAPIController(
+		searchAPIService,
+		asserter,
+	)
+
+	return services.WithSourceHeader(server.NewRouter(networkAPIController, blockAPIController, accountAPIController, constructionAPIController, mempoolAPIController, callAPIController, eventsAPIController, searchAPIController))
 }
 
 func main() {
@@ -65,28 +96,44 @@ func main() {
 		}
 	}
 
-	network := &types.NetworkIdentifier{
-        Blockchain: "Ethereum",
-        Network:    "Sepolia",
+	// Networks, their RPC endpoints and token lists come from
+	// MESH_NETWORKS_FILE, or the built-in defaults when it is unset.
+	networks, err := services.LoadNetworksFromEnv()
+	if err != nil {
+		log.Fatal(err)
 	}
 
+	// The pollers and indexers run until SIGINT or SIGTERM, which also
+	// starts the shutdown below.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Head pollers feed /events/blocks for as long as the server runs.
+	networks.StartEventPollers(ctx)
+	// Indexers follow their chains for /search/transactions.
+	networks.StartIndexers(ctx)
+
 	// The asserter automatically rejects incorrectly formatted
 	// requests.
 	asserter, err := asserter.NewServer(
-		[]string{"Transfer", "Reward", "Fee"},
-		false,
-		[]*types.NetworkIdentifier{network},
-		nil,
+		services.OperationTypes,
+		// /account/balance serves past blocks; check:data reconciles at them
+		true,
+		networks.Identifiers(),
+		services.CallMethods,
 		false,
 		"",
 	)
 	if err != nil {
+		stop()
+		if closeErr := networks.Close(); closeErr != nil {
+			log.Printf("⚠️  Closing indexes: %v", closeErr)
+		}
 		log.Fatal(err)
 	}
 
 	// Create the main router handler then apply the logger and Cors
 	// middlewares in sequence.
-	router := NewBlockchainRouter(network, asserter)
+	router := NewBlockchainRouter(networks, asserter)
 	
 	// Create Gin router for additional endpoints
 	ginRouter := gin.Default()
@@ -101,6 +148,11 @@ func main() {
 			"service": "coinbase-mesh-server",
 		})
 	})
+
+	// Block cache hit, miss, eviction and reorg counts per network
+	ginRouter.GET("/cache/stats", func(c *gin.Context) {
+		c.JSON(http.StatusOK, networks.CacheStats())
+	})
 	
     // Mount the Mesh API router at /mesh with path rewriting so the wrapped
     // Rosetta router receives paths like /network/list (without the /mesh
@@ -114,11 +166,38 @@ func main() {
 	
     port := getPort()
     log.Printf("🚀 Starting Coinbase Mesh Server on port %s", port)
-    log.Printf("📊 Network: %s %s", network.Blockchain, network.Network)
+    for _, id := range networks.Identifiers() {
+        log.Printf("📊 Network: %s %s", id.Blockchain, id.Network)
+    }
     log.Printf("🔗 Mesh API available at: http://localhost:%s/mesh", port)
     log.Printf("🏥 Health check available at: http://localhost:%s/health", port)
+    log.Printf("📈 Cache stats available at: http://localhost:%s/cache/stats", port)
     
-    if err := ginRouter.Run(":" + port); err != nil {
+	srv := &http.Server{Addr: ":" + port, Handler: ginRouter}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		err = nil
+	}
+
+	// Shut down explicitly, as deferred calls would not run after log.Fatal;
+	// in-flight requests finish first, then the pollers and indexers stop
+	// and the indexes close.
+	log.Printf("Shutting down Coinbase Mesh Server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+		log.Printf("⚠️  Server shutdown: %v", shutdownErr)
+	}
+	stop()
+	if closeErr := networks.Close(); closeErr != nil {
+		log.Printf("⚠️  Closing indexes: %v", closeErr)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal("❌ Failed to start server:", err)
 	}
 }
\ No newline at end of file
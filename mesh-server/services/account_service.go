@@ -2,33 +2,19 @@ package services
 
 import (
     "context"
-    "os"
+    "fmt"
 
     "github.com/coinbase/rosetta-sdk-go/types"
 )
 
 // AccountAPIService implements the Account API interface
 type AccountAPIService struct {
-	network *types.NetworkIdentifier
-    rpc     *EthRPCClient
-    live    bool
+	networks *Networks
 }
 
 // NewAccountAPIService creates a new AccountAPIService
-func NewAccountAPIService(network *types.NetworkIdentifier, rpc *EthRPCClient) *AccountAPIService {
-    live := false
-    if rpc != nil {
-        if v := os.Getenv("MESH_LIVE"); v == "false" || v == "0" {
-            live = false
-        } else {
-            live = true
-        }
-    }
-	return &AccountAPIService{
-		network: network,
-        rpc:     rpc,
-        live:    live,
-	}
+func NewAccountAPIService(networks *Networks) *AccountAPIService {
+	return &AccountAPIService{networks: networks}
 }
 
 // AccountBalance implements the /account/balance endpoint
@@ -36,107 +22,148 @@ func (s *AccountAPIService) AccountBalance(
 	ctx context.Context,
 	request *types.AccountBalanceRequest,
 ) (*types.AccountBalanceResponse, *types.Error) {
-    // Live path when RPC is available and live mode enabled
-    if s.rpc != nil && s.live {
-        accountAddress := request.AccountIdentifier.Address
-        if accountAddress == "" {
-            return nil, &types.Error{Code: 1, Message: "Account address is required", Retriable: false}
+    n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+    if rErr != nil {
+        return nil, rErr
+    }
+
+    accountAddress := request.AccountIdentifier.Address
+    if accountAddress == "" {
+        return nil, &types.Error{Code: 1, Message: "Account address is required", Retriable: false}
+    }
+
+    // Resolve the requested currencies before touching the node so
+    // unknown ones fail fast rather than falling back to mock data
+    var tokens []*Token
+    currencies := request.Currencies
+    if len(currencies) == 0 {
+        currencies = []*types.Currency{n.Currency}
+        for _, t := range n.Tokens.Tokens() {
+            currencies = append(currencies, t.Currency())
+        }
+    }
+    for _, c := range currencies {
+        if c.Symbol == n.Currency.Symbol && c.Decimals == n.Currency.Decimals && c.Metadata["contract_address"] == nil {
+            tokens = append(tokens, nil)
+            continue
         }
-        
-        // Determine block number to query
-        blockTag := "latest"
-        if request.BlockIdentifier != nil {
-            if request.BlockIdentifier.Index != nil {
+        t, ok := n.Tokens.ForCurrency(c)
+        if !ok {
+            return nil, wrapErr(ErrUnsupportedCurrency, fmt.Errorf("unknown currency %s", types.PrintStruct(c)))
+        }
+        tokens = append(tokens, &t)
+    }
+
+    // Live path unless the network serves mock data only
+    if n.mode() != ModeMock {
+        // Resolve the block first so every balance is read at the same height
+        var blk rpcBlock
+        var err error
+        if cached, ok := n.Cache.block(request.BlockIdentifier); ok {
+            blk.Number = int64ToHex(cached.Block.BlockIdentifier.Index)
+            blk.Hash = cached.Block.BlockIdentifier.Hash
+        } else if request.BlockIdentifier != nil && request.BlockIdentifier.Hash != nil {
+            err = n.RPC.call(ctx, "eth_getBlockByHash", []interface{}{*request.BlockIdentifier.Hash, false}, &blk)
+        } else {
+            blockTag := "latest"
+            if request.BlockIdentifier != nil && request.BlockIdentifier.Index != nil {
                 blockTag = int64ToHex(*request.BlockIdentifier.Index)
-            } else if request.BlockIdentifier.Hash != nil {
-                blockTag = *request.BlockIdentifier.Hash
             }
+            err = n.RPC.call(ctx, "eth_getBlockByNumber", []interface{}{blockTag, false}, &blk)
         }
-        
-        // Get ETH balance
-        var balanceHex string
-        if err := s.rpc.call("eth_getBalance", []interface{}{accountAddress, blockTag}, &balanceHex); err == nil {
-            // Get block info
-            var blk rpcBlock
-            _ = s.rpc.call("eth_getBlockByNumber", []interface{}{blockTag, false}, &blk)
-            
-            // Parse block identifier
-            blockIdx := int64(0)
-            if blk.Number != "" {
-                blockIdx, _ = hexToInt64(blk.Number)
-            }
-            blockHash := blk.Hash
-            if blockHash == "" && blockTag != "latest" {
-                blockHash = blockTag
-            }
-            
-            currentBlock := &types.BlockIdentifier{Index: blockIdx, Hash: blockHash}
-            
-            // Convert balance
-            balanceWei := hexToBigIntMust(balanceHex)
-            
+        var rErr *types.Error
+        if err != nil {
+            rErr = n.liveFailure(ErrNetwork, err)
+        } else if blk.Number == "" {
+            rErr = n.liveFailure(ErrBlockNotFound, fmt.Errorf("block %s not found", types.PrintStruct(request.BlockIdentifier)))
+        } else if balances, err := s.balances(ctx, n, accountAddress, blk.Number, tokens); err != nil {
+            rErr = n.liveFailure(ErrNetwork, err)
+        } else {
+            blockIdx, _ := hexToInt64(blk.Number)
             return &types.AccountBalanceResponse{
-                BlockIdentifier: currentBlock,
-                Balances: []*types.Amount{
-                    {
-                        Value:    balanceWei,
-                        Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-                    },
-                },
-                Metadata: map[string]interface{}{},
+                BlockIdentifier: &types.BlockIdentifier{Index: blockIdx, Hash: blk.Hash},
+                Balances:        balances,
+                Metadata:        withSource(ctx, SourceLive, nil),
             }, nil
         }
-        // fall back to mock on error
+        if rErr != nil {
+            return nil, rErr
+        }
+        // hybrid: fall back to mock below
     }
 
-	// Mock account balance data
-	accountAddress := request.AccountIdentifier.Address
-	if accountAddress == "" {
-		return nil, &types.Error{
-			Code:      1,
-			Message:   "Account address is required",
-			Retriable: false,
-		}
-	}
-
-	// Mock current block
-	currentBlock := &types.BlockIdentifier{
-		Index: 1000000,
-		Hash:  "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+	// Mock balances from the simulated chain, which only moves the native
+	// currency
+	blockID, native, ok := n.simulated().Balance(accountAddress, request.BlockIdentifier)
+	if !ok {
+		return nil, wrapErr(ErrBlockNotFound, fmt.Errorf("block %s not found", types.PrintStruct(request.BlockIdentifier)))
 	}
-
-	// Mock balances
-	balances := []*types.Amount{
-		{
-			Value:    "5000000000000000000", // 5 ETH in wei
-			Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-		},
-		{
-			Value:    "100000000", // 100 USDC (6 decimals)
-			Currency: &types.Currency{Symbol: "USDC", Decimals: 6},
-		},
-		{
-			Value:    "1000000000000000000000", // 1000 USDT (18 decimals)
-			Currency: &types.Currency{Symbol: "USDT", Decimals: 18},
-		},
+	balances := make([]*types.Amount, 0, len(tokens))
+	for _, t := range tokens {
+		if t == nil {
+			balances = append(balances, &types.Amount{Value: native.String(), Currency: n.Currency})
+		} else {
+			balances = append(balances, &types.Amount{Value: "0", Currency: t.Currency()})
+		}
 	}
 
 	return &types.AccountBalanceResponse{
-		BlockIdentifier: currentBlock,
+		BlockIdentifier: blockID,
 		Balances:        balances,
-		Metadata: map[string]interface{}{
-			"sequence_number": "42",
-			"account_type":    "contract",
-		},
+		Metadata:        withSource(ctx, SourceMock, nil),
 	}, nil
 }
 
-// AccountCoins implements the /account/coins endpoint
+// balances reads the native balance (nil entries) or ERC-20 balanceOf for
+// each token at the given block number.
+func (s *AccountAPIService) balances(ctx context.Context, n *Network, address, blockNumber string, tokens []*Token) ([]*types.Amount, error) {
+    // one batch covers the native balance and every token
+    results := make([]string, len(tokens))
+    calls := make([]*rpcCall, len(tokens))
+    for i, t := range tokens {
+        if t == nil {
+            calls[i] = &rpcCall{Method: "eth_getBalance", Params: []interface{}{address, blockNumber}, Out: &results[i]}
+            continue
+        }
+        call := map[string]interface{}{"to": t.Address, "data": balanceOfCallData(address)}
+        calls[i] = &rpcCall{Method: "eth_call", Params: []interface{}{call, blockNumber}, Out: &results[i]}
+    }
+    if err := n.RPC.batch(ctx, calls); err != nil {
+        return nil, err
+    }
+
+    out := make([]*types.Amount, 0, len(tokens))
+    for i, t := range tokens {
+        if calls[i].Err != nil {
+            return nil, calls[i].Err
+        }
+        if t == nil {
+            out = append(out, &types.Amount{Value: hexToBigIntMust(results[i]), Currency: n.Currency})
+            continue
+        }
+        // contracts without code return "0x"
+        value := "0"
+        if results[i] != "0x" {
+            value = hexToBigIntMust(results[i])
+        }
+        out = append(out, &types.Amount{Value: value, Currency: t.Currency()})
+    }
+    return out, nil
+}
+
+// AccountCoins implements the /account/coins endpoint. Only UTXO networks
+// have coins; they are served from the simulated chain at its head.
 func (s *AccountAPIService) AccountCoins(
 	ctx context.Context,
 	request *types.AccountCoinsRequest,
 ) (*types.AccountCoinsResponse, *types.Error) {
-	// Mock account coins data
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	if n.model() != ModelUTXO {
+		return nil, wrapErr(ErrCoinsUnsupported, fmt.Errorf("%s is account-based; use /account/balance", networkKey(n.Identifier)))
+	}
 	accountAddress := request.AccountIdentifier.Address
 	if accountAddress == "" {
 		return nil, &types.Error{
@@ -146,40 +173,25 @@ func (s *AccountAPIService) AccountCoins(
 		}
 	}
 
-	// Mock current block
-	currentBlock := &types.BlockIdentifier{
-		Index: 1000000,
-		Hash:  "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
-	}
-
-	// Mock coins
-	coins := []*types.Coin{
-		{
-			CoinIdentifier: &types.CoinIdentifier{
-				Identifier: "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef:0",
-			},
-			Amount: &types.Amount{
-				Value:    "5000000000000000000", // 5 ETH in wei
-				Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-			},
-		},
-		{
-			CoinIdentifier: &types.CoinIdentifier{
-				Identifier: "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890:0",
-			},
-			Amount: &types.Amount{
-				Value:    "100000000", // 100 USDC (6 decimals)
-				Currency: &types.Currency{Symbol: "USDC", Decimals: 6},
-			},
-		},
+	sim := n.simulated()
+	coins := []*types.Coin{}
+	for _, coin := range sim.Coins(accountAddress) {
+		if len(request.Currencies) == 0 || containsCurrency(request.Currencies, coin.Amount.Currency) {
+			coins = append(coins, coin)
+		}
 	}
-
 	return &types.AccountCoinsResponse{
-		BlockIdentifier: currentBlock,
-		Coins:          coins,
-		Metadata: map[string]interface{}{
-			"sequence_number": "42",
-			"account_type":    "contract",
-		},
+		BlockIdentifier: sim.Head().BlockIdentifier,
+		Coins:           coins,
+		Metadata:        withSource(ctx, SourceMock, nil),
 	}, nil
-}
\ No newline at end of file
+}
+
+func containsCurrency(currencies []*types.Currency, c *types.Currency) bool {
+	for _, want := range currencies {
+		if types.Hash(want) == types.Hash(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountCoins_PerModel(t *testing.T) {
+	networks := testNetworks(t, nil)
+	s := NewAccountAPIService(networks)
+	ctx := context.Background()
+	sim := networks.list[0].simulated()
+	account := &types.AccountIdentifier{Address: sim.Accounts()[0]}
+
+	// account-based networks have balances, not coins
+	_, rErr := s.AccountCoins(ctx, &types.AccountCoinsRequest{NetworkIdentifier: sepolia, AccountIdentifier: account})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrCoinsUnsupported.Code, rErr.Code)
+	assert.Equal(t, ErrCoinsUnsupported.Message, rErr.Message)
+
+	networks = testNetworks(t, nil)
+	networks.list[0].Model = ModelUTXO
+	s = NewAccountAPIService(networks)
+	sim = networks.list[0].simulated()
+	resp, rErr := s.AccountCoins(ctx, &types.AccountCoinsRequest{NetworkIdentifier: sepolia, AccountIdentifier: account})
+	require.Nil(t, rErr)
+	assert.Equal(t, sim.Head().BlockIdentifier, resp.BlockIdentifier)
+	assert.Equal(t, sim.Coins(account.Address), resp.Coins)
+	assert.NotEmpty(t, resp.Coins)
+	assert.Equal(t, SourceMock, resp.Metadata["source"])
+
+	resp, rErr = s.AccountCoins(ctx, &types.AccountCoinsRequest{
+		NetworkIdentifier: sepolia,
+		AccountIdentifier: account,
+		Currencies:        []*types.Currency{{Symbol: "USDC", Decimals: 6}},
+	})
+	require.Nil(t, rErr)
+	assert.Empty(t, resp.Coins)
+
+	// coins can be searched for on UTXO networks
+	coin := sim.Coins(account.Address)[0].CoinIdentifier
+	found, rErr := NewSearchAPIService(networks).SearchTransactions(ctx, &types.SearchTransactionsRequest{NetworkIdentifier: sepolia, CoinIdentifier: coin})
+	require.Nil(t, rErr)
+	assert.Equal(t, int64(1), found.TotalCount, "an unspent coin appears in the transaction creating it")
+}
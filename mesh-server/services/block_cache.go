@@ -0,0 +1,335 @@
+package services
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	defaultCacheBlocks = 1024
+	// defaultConfirmations is two epochs, after which post-Merge Ethereum
+	// blocks are finalized.
+	defaultConfirmations = 64
+)
+
+// CacheConfig sizes a network's block cache. Blocks is the number of blocks
+// kept, with their receipts and transactions; a negative value disables the
+// cache. Confirmations is the depth below the head at which a block is
+// treated as final.
+type CacheConfig struct {
+	Blocks        int   `json:"blocks,omitempty"`
+	Confirmations int64 `json:"confirmations,omitempty"`
+}
+
+// CacheStats counts cache activity since start-up.
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Reorgs    int64 `json:"reorgs"`
+	Blocks    int   `json:"blocks"`
+}
+
+// cachedBlock holds everything cached for one block hash.
+type cachedBlock struct {
+	index    int64
+	hash     string
+	parent   string
+	block    *types.BlockResponse
+	receipts []*rpcReceipt
+	txs      map[string]*types.Transaction // by lower-cased hash
+	elem     *list.Element
+}
+
+// BlockCache is an LRU of parsed blocks, their receipts and transactions,
+// keyed by block hash. Hashes identify content, so lookups by hash are always
+// safe; lookups by index are only answered for blocks at least Confirmations
+// deep. Blocks whose parent hash contradicts a cached neighbour reveal a
+// reorg and evict the stale side. A nil *BlockCache caches nothing.
+type BlockCache struct {
+	size          int
+	confirmations int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *cachedBlock, most recent first
+	byHash  map[string]*cachedBlock
+	byIndex map[int64]*cachedBlock
+	head    int64
+
+	hits, misses, evictions, reorgs atomic.Int64
+}
+
+// NewBlockCache returns a cache for cfg, or nil when cfg disables caching.
+func NewBlockCache(cfg CacheConfig) *BlockCache {
+	if cfg.Blocks < 0 {
+		return nil
+	}
+	if cfg.Blocks == 0 {
+		cfg.Blocks = defaultCacheBlocks
+	}
+	if cfg.Confirmations <= 0 {
+		cfg.Confirmations = defaultConfirmations
+	}
+	return &BlockCache{
+		size:          cfg.Blocks,
+		confirmations: cfg.Confirmations,
+		lru:           list.New(),
+		byHash:        map[string]*cachedBlock{},
+		byIndex:       map[int64]*cachedBlock{},
+	}
+}
+
+// Stats reports hit, miss, eviction and reorg counts.
+func (c *BlockCache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	blocks := len(c.byHash)
+	c.mu.Unlock()
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Reorgs:    c.reorgs.Load(),
+		Blocks:    blocks,
+	}
+}
+
+// observeHead records the highest block seen, which sets the confirmation
+// depth of everything below it.
+func (c *BlockCache) observeHead(index int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if index > c.head {
+		c.head = index
+	}
+	c.mu.Unlock()
+}
+
+// final reports whether index is deep enough to be served by index.
+func (c *BlockCache) final(index int64) bool {
+	return c.head-index >= c.confirmations
+}
+
+// block answers a block request from the cache. Requests by hash are always
+// eligible; requests by index only once the height is final. Requests for
+// the head, or for a recent height, are not counted since they must go to
+// the node to learn which block is canonical.
+func (c *BlockCache) block(id *types.PartialBlockIdentifier) (*types.BlockResponse, bool) {
+	if c == nil || id == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var e *cachedBlock
+	switch {
+	case id.Hash != nil:
+		e = c.byHash[strings.ToLower(*id.Hash)]
+		if e != nil && id.Index != nil && *id.Index != e.index {
+			e = nil
+		}
+	case id.Index != nil && c.final(*id.Index):
+		e = c.byIndex[*id.Index]
+	default:
+		return nil, false
+	}
+	return c.hitBlock(e)
+}
+
+// blockByHash returns the cached block with the given hash.
+func (c *BlockCache) blockByHash(hash string) (*types.BlockResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hitBlock(c.byHash[strings.ToLower(hash)])
+}
+
+// hitBlock counts a lookup of e and returns a copy of its block. Callers
+// hold c.mu.
+func (c *BlockCache) hitBlock(e *cachedBlock) (*types.BlockResponse, bool) {
+	if e == nil || e.block == nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.touch(e)
+	c.hits.Add(1)
+	return copyBlockResponse(e.block), true
+}
+
+// receipts returns the cached receipts of a block.
+func (c *BlockCache) receipts(hash string) ([]*rpcReceipt, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.byHash[strings.ToLower(hash)]; ok && e.receipts != nil {
+		c.touch(e)
+		c.hits.Add(1)
+		return e.receipts, true
+	}
+	c.misses.Add(1)
+	return nil, false
+}
+
+// transaction returns a cached transaction of the block with the given hash.
+func (c *BlockCache) transaction(blockHash, txHash string) (*types.Transaction, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.byHash[strings.ToLower(blockHash)]; ok {
+		if tx, ok := e.txs[strings.ToLower(txHash)]; ok {
+			c.touch(e)
+			c.hits.Add(1)
+			return copyTransaction(tx), true
+		}
+	}
+	c.misses.Add(1)
+	return nil, false
+}
+
+// putBlock caches a parsed block and indexes its transactions. Entries are
+// copied in and out, so callers may set metadata on what they pass or get.
+func (c *BlockCache) putBlock(resp *types.BlockResponse) {
+	if c == nil {
+		return
+	}
+	resp = copyBlockResponse(resp)
+	b := resp.Block
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entry(b.BlockIdentifier.Index, b.BlockIdentifier.Hash, b.ParentBlockIdentifier.Hash)
+	e.block = resp
+	for _, tx := range b.Transactions {
+		e.txs[strings.ToLower(tx.TransactionIdentifier.Hash)] = copyTransaction(tx)
+	}
+}
+
+// putReceipts caches the receipts of a block.
+func (c *BlockCache) putReceipts(index int64, hash, parent string, receipts []*rpcReceipt) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entry(index, hash, parent).receipts = receipts
+}
+
+// putTransaction caches one transaction of a block.
+func (c *BlockCache) putTransaction(index int64, blockHash, parent string, tx *types.Transaction) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entry(index, blockHash, parent).txs[strings.ToLower(tx.TransactionIdentifier.Hash)] = copyTransaction(tx)
+}
+
+// entry returns the entry for a block, creating it and checking it against
+// its cached neighbours. Callers hold c.mu.
+func (c *BlockCache) entry(index int64, hash, parent string) *cachedBlock {
+	hash, parent = strings.ToLower(hash), strings.ToLower(parent)
+	if e, ok := c.byHash[hash]; ok {
+		c.touch(e)
+		return e
+	}
+	if index > c.head {
+		c.head = index
+	}
+
+	// A different block at this height, a parent that is not the cached
+	// block below, or a cached child that does not build on this block all
+	// mean the chain reorganized: drop the side that is no longer canonical.
+	if old, ok := c.byIndex[index]; ok && old.hash != hash {
+		c.reorgs.Add(1)
+		c.evictFrom(index)
+	}
+	if below, ok := c.byIndex[index-1]; ok && parent != "" && below.hash != parent {
+		c.reorgs.Add(1)
+		c.evictFrom(index - 1)
+	}
+	if above, ok := c.byIndex[index+1]; ok && above.parent != "" && above.parent != hash {
+		c.reorgs.Add(1)
+		c.evictFrom(index + 1)
+	}
+
+	e := &cachedBlock{index: index, hash: hash, parent: parent, txs: map[string]*types.Transaction{}}
+	e.elem = c.lru.PushFront(e)
+	c.byHash[hash] = e
+	c.byIndex[index] = e
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back().Value.(*cachedBlock))
+		c.evictions.Add(1)
+	}
+	return e
+}
+
+// reorg evicts every block at or above index, which the head poller found
+// is no longer canonical.
+func (c *BlockCache) reorg(index int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reorgs.Add(1)
+	c.evictFrom(index)
+}
+
+// evictFrom removes every indexed block at or above index.
+func (c *BlockCache) evictFrom(index int64) {
+	for i, e := range c.byIndex {
+		if i >= index {
+			c.remove(e)
+			c.evictions.Add(1)
+		}
+	}
+}
+
+func (c *BlockCache) remove(e *cachedBlock) {
+	c.lru.Remove(e.elem)
+	delete(c.byHash, e.hash)
+	if c.byIndex[e.index] == e {
+		delete(c.byIndex, e.index)
+	}
+}
+
+func (c *BlockCache) touch(e *cachedBlock) {
+	c.lru.MoveToFront(e.elem)
+}
+
+// copyBlockResponse copies a block down to its metadata map. Transactions are
+// shared; the cache never hands them out without copying them too.
+func copyBlockResponse(r *types.BlockResponse) *types.BlockResponse {
+	b := *r.Block
+	b.Metadata = copyMetadata(b.Metadata)
+	return &types.BlockResponse{Block: &b, OtherTransactions: r.OtherTransactions}
+}
+
+func copyTransaction(tx *types.Transaction) *types.Transaction {
+	out := *tx
+	out.Metadata = copyMetadata(tx.Metadata)
+	return &out
+}
+
+func copyMetadata(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheBlock(index int64, hash, parent string) *types.BlockResponse {
+	return &types.BlockResponse{Block: &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Index: index, Hash: hash},
+		ParentBlockIdentifier: &types.BlockIdentifier{Index: index - 1, Hash: parent},
+		Transactions: []*types.Transaction{{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "0xtx" + hash[2:]},
+		}},
+	}}
+}
+
+func TestBlockCache_ServesIndexOnlyOnceFinal(t *testing.T) {
+	c := NewBlockCache(CacheConfig{Confirmations: 2})
+	c.putBlock(cacheBlock(10, "0xa10", "0xa9"))
+
+	idx := int64(10)
+	_, ok := c.block(&types.PartialBlockIdentifier{Index: &idx})
+	assert.False(t, ok, "block at the head is not final")
+	hash := "0xA10"
+	_, ok = c.block(&types.PartialBlockIdentifier{Hash: &hash})
+	assert.True(t, ok, "hashes identify content and are always served")
+
+	c.observeHead(12)
+	resp, ok := c.block(&types.PartialBlockIdentifier{Index: &idx})
+	require.True(t, ok)
+	assert.Equal(t, "0xa10", resp.Block.BlockIdentifier.Hash)
+
+	// callers may set metadata without touching the cached copy
+	resp.Block.Metadata = map[string]interface{}{"source": "live"}
+	again, _ := c.blockByHash("0xa10")
+	assert.Nil(t, again.Block.Metadata)
+
+	assert.Equal(t, CacheStats{Hits: 3, Blocks: 1}, c.Stats())
+}
+
+func TestBlockCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewBlockCache(CacheConfig{Blocks: 2})
+	c.putBlock(cacheBlock(1, "0xa1", "0xa0"))
+	c.putBlock(cacheBlock(2, "0xa2", "0xa1"))
+	_, ok := c.blockByHash("0xa1")
+	require.True(t, ok)
+	c.putBlock(cacheBlock(3, "0xa3", "0xa2"))
+
+	_, ok = c.blockByHash("0xa2")
+	assert.False(t, ok)
+	_, ok = c.blockByHash("0xa1")
+	assert.True(t, ok)
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.Evictions)
+	assert.Equal(t, 2, stats.Blocks)
+}
+
+func TestBlockCache_ReorgEvictsStaleBranch(t *testing.T) {
+	c := NewBlockCache(CacheConfig{})
+	for i := int64(1); i <= 5; i++ {
+		c.putBlock(cacheBlock(i, fmt.Sprintf("0xa%d", i), fmt.Sprintf("0xa%d", i-1)))
+		c.putReceipts(i, fmt.Sprintf("0xa%d", i), fmt.Sprintf("0xa%d", i-1), []*rpcReceipt{})
+	}
+
+	// block 4 is replaced by one building on a different block 3
+	c.putBlock(cacheBlock(4, "0xb4", "0xb3"))
+
+	for _, gone := range []string{"0xa3", "0xa4", "0xa5"} {
+		_, ok := c.blockByHash(gone)
+		assert.False(t, ok, gone)
+		_, ok = c.receipts(gone)
+		assert.False(t, ok, gone)
+	}
+	for _, kept := range []string{"0xa1", "0xa2", "0xb4"} {
+		_, ok := c.blockByHash(kept)
+		assert.True(t, ok, kept)
+	}
+	_, ok := c.transaction("0xa5", "0xtxa5")
+	assert.False(t, ok)
+	assert.Equal(t, int64(2), c.Stats().Reorgs)
+
+	// a cached child that does not build on a newly seen block is stale too
+	c.putBlock(cacheBlock(3, "0xc3", "0xa2"))
+	_, ok = c.blockByHash("0xb4")
+	assert.False(t, ok)
+	assert.Equal(t, int64(3), c.Stats().Reorgs)
+}
+
+func TestBlock_ServedFromCache(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getBlockByNumber": liveBlock(),
+		"eth_getBlockReceipts": liveReceipts(),
+	}, nil)
+	networks := testNetworks(t, rpc)
+	n := networks.list[0]
+	n.Mode = ModeLive
+	n.Cache = NewBlockCache(CacheConfig{Confirmations: 10})
+	s := NewBlockAPIService(networks)
+	ctx := context.Background()
+
+	idx := int64(16)
+	first, rErr := s.Block(ctx, &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.Nil(t, rErr)
+
+	// a recent block is re-read by number to check it is still canonical,
+	// but its receipts are not fetched again
+	n.RPC = newStubRPC(t, map[string]interface{}{"eth_getBlockByNumber": liveBlock()}, nil)
+	again, rErr := s.Block(ctx, &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, first.Block.Transactions, again.Block.Transactions)
+
+	// by hash the node is not asked at all
+	n.RPC = newStubRPC(t, map[string]interface{}{}, nil)
+	hash := "0xb10"
+	_, rErr = s.Block(ctx, &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Hash: &hash},
+	})
+	require.Nil(t, rErr)
+	tx, rErr := s.BlockTransaction(ctx, &types.BlockTransactionRequest{
+		NetworkIdentifier:     sepolia,
+		BlockIdentifier:       &types.BlockIdentifier{Index: idx, Hash: hash},
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "0xok"},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, SourceLive, tx.Transaction.Metadata["source"])
+
+	stats := networks.CacheStats()["Ethereum/Sepolia"]
+	assert.Equal(t, int64(3), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses, "first block and its receipts")
+}
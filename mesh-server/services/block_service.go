@@ -3,35 +3,29 @@ package services
 import (
     "context"
     "encoding/json"
-    "os"
+    "fmt"
+    "math/big"
+    "strings"
 
     "github.com/coinbase/rosetta-sdk-go/types"
 )
 
 // BlockAPIService implements the Block API interface
 type BlockAPIService struct {
-	network *types.NetworkIdentifier
-    rpc     *EthRPCClient
-    live    bool
+	networks *Networks
 }
 
 // NewBlockAPIService creates a new BlockAPIService
-func NewBlockAPIService(network *types.NetworkIdentifier, rpc *EthRPCClient) *BlockAPIService {
-    live := false
-    if rpc != nil {
-        if v := os.Getenv("MESH_LIVE"); v == "false" || v == "0" {
-            live = false
-        } else {
-            live = true
-        }
-    }
-	return &BlockAPIService{
-		network: network,
-        rpc:     rpc,
-        live:    live,
-	}
+func NewBlockAPIService(networks *Networks) *BlockAPIService {
+	return &BlockAPIService{networks: networks}
 }
 
+// Operation statuses advertised in /network/options.
+const (
+    statusSuccess = "SUCCESS"
+    statusFailure = "FAILURE"
+)
+
 // helper to get *string
 func strPtr(s string) *string { return &s }
 
@@ -40,105 +34,68 @@ func (s *BlockAPIService) Block(
 	ctx context.Context,
 	request *types.BlockRequest,
 ) (*types.BlockResponse, *types.Error) {
-    // Live path
-    if s.rpc != nil && s.live {
+    n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+    if rErr != nil {
+        return nil, rErr
+    }
+
+    if n.mode() != ModeMock {
+        // Blocks requested by hash, or deep enough to be final, are served
+        // from the cache
+        if resp, ok := n.Cache.block(request.BlockIdentifier); ok {
+            resp.Block.Metadata = withSource(ctx, SourceLive, resp.Block.Metadata)
+            return resp, nil
+        }
         var blk rpcBlock
-        // Resolve by index or hash
-        if request.BlockIdentifier != nil {
-            if request.BlockIdentifier.Index != nil {
-                if err := s.rpc.call("eth_getBlockByNumber", []interface{}{int64ToHex(*request.BlockIdentifier.Index), true}, &blk); err != nil {
-                    // fall through to mock
-                } else {
-                    return s.blockToRosetta(&blk)
-                }
-            } else if request.BlockIdentifier.Hash != nil {
-                if err := s.rpc.call("eth_getBlockByHash", []interface{}{*request.BlockIdentifier.Hash, true}, &blk); err != nil {
-                    // fall through to mock
-                } else {
-                    return s.blockToRosetta(&blk)
+        var err error
+        // Resolve by index or hash, or fetch latest
+        switch {
+        case request.BlockIdentifier != nil && request.BlockIdentifier.Index != nil:
+            err = n.RPC.call(ctx, "eth_getBlockByNumber", []interface{}{int64ToHex(*request.BlockIdentifier.Index), true}, &blk)
+        case request.BlockIdentifier != nil && request.BlockIdentifier.Hash != nil:
+            err = n.RPC.call(ctx, "eth_getBlockByHash", []interface{}{*request.BlockIdentifier.Hash, true}, &blk)
+        default:
+            err = n.RPC.call(ctx, "eth_getBlockByNumber", []interface{}{"latest", true}, &blk)
+        }
+        var rErr *types.Error
+        switch {
+        case err != nil:
+            rErr = n.liveFailure(ErrNetwork, err)
+        case blk.Hash == "":
+            rErr = n.liveFailure(ErrBlockNotFound, fmt.Errorf("block %s not found", types.PrintStruct(request.BlockIdentifier)))
+        default:
+            // a recent block seen before is reused once the node confirms it
+            // is still canonical at this height
+            var resp *types.BlockResponse
+            ok := false
+            if request.BlockIdentifier == nil || request.BlockIdentifier.Hash == nil {
+                resp, ok = n.Cache.blockByHash(blk.Hash)
+            }
+            if !ok {
+                var err error
+                if resp, err = s.blockToRosetta(ctx, n, &blk); err != nil {
+                    rErr = n.liveFailure(ErrNetwork, err)
+                    break
                 }
+                n.Cache.putBlock(resp)
             }
+            resp.Block.Metadata = withSource(ctx, SourceLive, resp.Block.Metadata)
+            return resp, nil
         }
-        // If no identifier provided, fetch latest
-        if err := s.rpc.call("eth_getBlockByNumber", []interface{}{"latest", true}, &blk); err == nil {
-            return s.blockToRosetta(&blk)
+        if rErr != nil {
+            return nil, rErr
         }
-        // fall back to mock below on any error
+        // hybrid: fall back to mock below
     }
 
-	// Mock block data
-	blockIndex := int64(1000000)
-	if request.BlockIdentifier.Index != nil {
-		blockIndex = *request.BlockIdentifier.Index
-	}
-
-	blockHash := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
-	if request.BlockIdentifier.Hash != nil {
-		blockHash = *request.BlockIdentifier.Hash
-	}
-
-	block := &types.Block{
-		BlockIdentifier: &types.BlockIdentifier{
-			Index: blockIndex,
-			Hash:  blockHash,
-		},
-		ParentBlockIdentifier: &types.BlockIdentifier{
-			Index: blockIndex - 1,
-			Hash:  "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
-		},
-		Timestamp: 1640995200000, // Mock timestamp
-		Transactions: []*types.Transaction{
-			{
-				TransactionIdentifier: &types.TransactionIdentifier{
-					Hash: "0xtx1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
-				},
-				Operations: []*types.Operation{
-					{
-						OperationIdentifier: &types.OperationIdentifier{
-							Index: 0,
-						},
-                        Type:   "Transfer",
-                        Status: strPtr("SUCCESS"),
-						Account: &types.AccountIdentifier{
-							Address: "0x1234567890abcdef1234567890abcdef1234567890",
-						},
-						Amount: &types.Amount{
-							Value:    "1000000000000000000", // 1 ETH in wei
-							Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-						},
-					},
-					{
-						OperationIdentifier: &types.OperationIdentifier{
-							Index: 1,
-						},
-                        Type:   "Transfer",
-                        Status: strPtr("SUCCESS"),
-						Account: &types.AccountIdentifier{
-							Address: "0xabcdef1234567890abcdef1234567890abcdef1234",
-						},
-						Amount: &types.Amount{
-							Value:    "-1000000000000000000", // -1 ETH in wei
-							Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-						},
-					},
-				},
-			},
-		},
-		Metadata: map[string]interface{}{
-			"gas_used":     "21000",
-			"gas_limit":    "21000",
-			"base_fee_per_gas": "20000000000",
-		},
+	// Mock block data from the simulated chain
+	block, ok := n.simulated().Block(request.BlockIdentifier)
+	if !ok {
+		return nil, wrapErr(ErrBlockNotFound, fmt.Errorf("block %s not found", types.PrintStruct(request.BlockIdentifier)))
 	}
+	block.Metadata = withSource(ctx, SourceMock, block.Metadata)
 
-	return &types.BlockResponse{
-		Block: block,
-		OtherTransactions: []*types.TransactionIdentifier{
-			{
-				Hash: "0xtxabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
-			},
-		},
-	}, nil
+	return &types.BlockResponse{Block: block}, nil
 }
 
 // BlockTransaction implements the /block/transaction endpoint
@@ -146,59 +103,34 @@ func (s *BlockAPIService) BlockTransaction(
 	ctx context.Context,
 	request *types.BlockTransactionRequest,
 ) (*types.BlockTransactionResponse, *types.Error) {
-    if s.rpc != nil && s.live {
+    n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+    if rErr != nil {
+        return nil, rErr
+    }
+
+    if n.mode() != ModeMock {
         txHash := request.TransactionIdentifier.Hash
         if txHash == "" {
             return nil, &types.Error{Code: 1, Message: "Transaction hash is required", Retriable: false}
         }
-        var tx rpcTx
-        if err := s.rpc.call("eth_getTransactionByHash", []interface{}{txHash}, &tx); err == nil && tx.Hash != "" {
-            // fetch receipt for fee data
-            var rc rpcReceipt
-            _ = s.rpc.call("eth_getTransactionReceipt", []interface{}{txHash}, &rc)
-            // Build rosetta transaction
-            ops := []*types.Operation{}
-            // value transfer if present
-            if tx.Value != "" {
-                // from (debit)
-                ops = append(ops, &types.Operation{
-                    OperationIdentifier: &types.OperationIdentifier{Index: 0},
-                    Type:   "Transfer",
-                    Status: strPtr("SUCCESS"),
-                    Account: &types.AccountIdentifier{Address: tx.From},
-                    Amount: &types.Amount{Value: "-" + hexToBigIntMust(tx.Value), Currency: &types.Currency{Symbol: "ETH", Decimals: 18}},
-                })
-                // to (credit)
-                ops = append(ops, &types.Operation{
-                    OperationIdentifier: &types.OperationIdentifier{Index: 1},
-                    Type:   "Transfer",
-                    Status: strPtr("SUCCESS"),
-                    Account: &types.AccountIdentifier{Address: tx.To},
-                    Amount: &types.Amount{Value: hexToBigIntMust(tx.Value), Currency: &types.Currency{Symbol: "ETH", Decimals: 18}},
-                })
-            }
-            // fee if available
-            if rc.GasUsed != "" && rc.EffectiveGasPrice != "" {
-                fee := mulHexBigToString(rc.GasUsed, rc.EffectiveGasPrice)
-                ops = append(ops, &types.Operation{
-                    OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
-                    Type:   "Fee",
-                    Status: strPtr("SUCCESS"),
-                    Account: &types.AccountIdentifier{Address: tx.From},
-                    Amount: &types.Amount{Value: "-" + fee, Currency: &types.Currency{Symbol: "ETH", Decimals: 18}},
-                })
+        if request.BlockIdentifier != nil {
+            if transaction, ok := n.Cache.transaction(request.BlockIdentifier.Hash, txHash); ok {
+                transaction.Metadata = withSource(ctx, SourceLive, transaction.Metadata)
+                return &types.BlockTransactionResponse{Transaction: transaction}, nil
             }
-            return &types.BlockTransactionResponse{
-                Transaction: &types.Transaction{
-                    TransactionIdentifier: &types.TransactionIdentifier{Hash: tx.Hash},
-                    Operations:           ops,
-                },
-            }, nil
         }
-        // fall back to mock on error
+        transaction, rErr := s.liveTransaction(ctx, n, txHash)
+        if rErr != nil {
+            return nil, rErr
+        }
+        if transaction != nil {
+            transaction.Metadata = withSource(ctx, SourceLive, transaction.Metadata)
+            return &types.BlockTransactionResponse{Transaction: transaction}, nil
+        }
+        // hybrid: fall back to mock below
     }
 
-	// Mock transaction data
+	// Mock transaction data from the simulated chain
 	txHash := request.TransactionIdentifier.Hash
 	if txHash == "" {
 		return nil, &types.Error{
@@ -208,69 +140,61 @@ func (s *BlockAPIService) BlockTransaction(
 		}
 	}
 
-	transaction := &types.Transaction{
-		TransactionIdentifier: &types.TransactionIdentifier{
-			Hash: txHash,
-		},
-		Operations: []*types.Operation{
-			{
-				OperationIdentifier: &types.OperationIdentifier{
-					Index: 0,
-				},
-                Type:   "Transfer",
-                Status: strPtr("SUCCESS"),
-				Account: &types.AccountIdentifier{
-					Address: "0x1234567890abcdef1234567890abcdef1234567890",
-				},
-				Amount: &types.Amount{
-					Value:    "1000000000000000000", // 1 ETH in wei
-					Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-				},
-			},
-			{
-				OperationIdentifier: &types.OperationIdentifier{
-					Index: 1,
-				},
-                Type:   "Transfer",
-                Status: strPtr("SUCCESS"),
-				Account: &types.AccountIdentifier{
-					Address: "0xabcdef1234567890abcdef1234567890abcdef1234",
-				},
-				Amount: &types.Amount{
-					Value:    "-1000000000000000000", // -1 ETH in wei
-					Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-				},
-			},
-			{
-				OperationIdentifier: &types.OperationIdentifier{
-					Index: 2,
-				},
-                Type:   "Fee",
-                Status: strPtr("SUCCESS"),
-				Account: &types.AccountIdentifier{
-					Address: "0x1234567890abcdef1234567890abcdef1234567890",
-				},
-				Amount: &types.Amount{
-					Value:    "-21000000000000000", // 0.021 ETH fee
-					Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
-				},
-			},
-		},
-		Metadata: map[string]interface{}{
-			"gas_used":     "21000",
-			"gas_price":    "20000000000",
-			"gas_limit":    "21000",
-			"nonce":        "42",
-		},
+	transaction, blockID, ok := n.simulated().Transaction(txHash)
+	if !ok || (request.BlockIdentifier != nil && request.BlockIdentifier.Hash != "" && request.BlockIdentifier.Hash != blockID.Hash) {
+		return nil, wrapErr(ErrTransactionNotFound, fmt.Errorf("transaction %s not found", txHash))
 	}
+	transaction.Metadata = withSource(ctx, SourceMock, transaction.Metadata)
 
 	return &types.BlockTransactionResponse{
 		Transaction: transaction,
 	}, nil
 }
 
-// blockToRosetta converts rpcBlock to Rosetta BlockResponse
-func (s *BlockAPIService) blockToRosetta(blk *rpcBlock) (*types.BlockResponse, *types.Error) {
+// liveTransaction reads a mined transaction from the node. The receipt gives
+// status and gas used; the block header gives the base fee and fee recipient
+// needed to split the fee. It returns nil, nil when a hybrid network should
+// fall back to mock data.
+func (s *BlockAPIService) liveTransaction(ctx context.Context, n *Network, txHash string) (*types.Transaction, *types.Error) {
+    // the transaction and its receipt in one round trip
+    var tx rpcTx
+    var rc rpcReceipt
+    calls := []*rpcCall{
+        {Method: "eth_getTransactionByHash", Params: []interface{}{txHash}, Out: &tx},
+        {Method: "eth_getTransactionReceipt", Params: []interface{}{txHash}, Out: &rc},
+    }
+    if err := n.RPC.batch(ctx, calls); err != nil {
+        return nil, n.liveFailure(ErrNetwork, err)
+    }
+    for _, call := range calls {
+        if call.Err != nil {
+            return nil, n.liveFailure(ErrNetwork, call.Err)
+        }
+    }
+    if tx.Hash == "" {
+        return nil, n.liveFailure(ErrTransactionNotFound, fmt.Errorf("transaction %s not found", txHash))
+    }
+    if rc.BlockHash == "" {
+        // still pending; /mempool/transaction serves it
+        return nil, n.liveFailure(ErrTransactionNotFound, fmt.Errorf("transaction %s is not mined", txHash))
+    }
+    var blk rpcBlock
+    if err := n.RPC.call(ctx, "eth_getBlockByHash", []interface{}{rc.BlockHash, false}, &blk); err != nil {
+        return nil, n.liveFailure(ErrNetwork, err)
+    }
+    internal, err := transactionInternalTransfers(ctx, n, tx.Hash)
+    if err != nil {
+        return nil, n.liveFailure(ErrNetwork, err)
+    }
+    transaction := minedTransaction(&tx, &rc, &blk, n, internal)
+    idx, _ := hexToInt64(blk.Number)
+    n.Cache.putTransaction(idx, blk.Hash, blk.ParentHash, transaction)
+    return transaction, nil
+}
+
+// blockToRosetta converts rpcBlock to Rosetta BlockResponse, reading the
+// receipts, rewards and internal transfers the block needs from the node
+func (s *BlockAPIService) blockToRosetta(ctx context.Context, n *Network, blk *rpcBlock) (*types.BlockResponse, error) {
     // parse index
     idx, _ := hexToInt64(blk.Number)
     // timestamp ms
@@ -278,36 +202,49 @@ func (s *BlockAPIService) blockToRosetta(blk *rpcBlock) (*types.BlockResponse, *
     if blk.Timestamp != "" {
         if v, err := hexToInt64(blk.Timestamp); err == nil { ts = v * 1000 }
     }
-    // parse transactions
-    txs := []*types.Transaction{}
+    var full []rpcTx
     if len(blk.Transactions) > 0 {
-        var full []rpcTx
-        if err := json.Unmarshal(blk.Transactions, &full); err == nil {
-            for i := range full {
-                tx := full[i]
-                ops := []*types.Operation{}
-                if tx.Value != "" {
-                    ops = append(ops, &types.Operation{
-                        OperationIdentifier: &types.OperationIdentifier{Index: 0},
-                        Type:   "Transfer",
-                        Status: strPtr("SUCCESS"),
-                        Account: &types.AccountIdentifier{Address: tx.From},
-                        Amount: &types.Amount{Value: "-" + hexToBigIntMust(tx.Value), Currency: &types.Currency{Symbol: "ETH", Decimals: 18}},
-                    })
-                    ops = append(ops, &types.Operation{
-                        OperationIdentifier: &types.OperationIdentifier{Index: 1},
-                        Type:   "Transfer",
-                        Status: strPtr("SUCCESS"),
-                        Account: &types.AccountIdentifier{Address: tx.To},
-                        Amount: &types.Amount{Value: hexToBigIntMust(tx.Value), Currency: &types.Currency{Symbol: "ETH", Decimals: 18}},
-                    })
-                }
-                txs = append(txs, &types.Transaction{
-                    TransactionIdentifier: &types.TransactionIdentifier{Hash: tx.Hash},
-                    Operations: ops,
-                })
+        if err := json.Unmarshal(blk.Transactions, &full); err != nil {
+            full = nil
+        }
+    }
+
+    // Rewards, receipts and traces are independent, so fetch them
+    // concurrently
+    var (
+        rewards  *types.Transaction
+        receipts []*rpcReceipt
+        internal map[string][]internalTransfer
+    )
+    err := parallel(ctx, 3, 3, func(ctx context.Context, i int) error {
+        var err error
+        switch i {
+        case 0:
+            if idx > 0 {
+                rewards, err = blockRewardTransaction(ctx, n, blk, idx)
+            }
+        case 1:
+            if len(full) > 0 {
+                receipts, err = s.blockReceipts(ctx, n, blk, full)
+            }
+        case 2:
+            if len(full) > 0 {
+                internal, err = blockInternalTransfers(ctx, n, blk, full)
             }
         }
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    // rewards and withdrawals go first, in a transaction named after the block
+    txs := []*types.Transaction{}
+    if rewards != nil {
+        txs = append(txs, rewards)
+    }
+    for i := range full {
+        txs = append(txs, minedTransaction(&full[i], receipts[i], blk, n, internal[strings.ToLower(full[i].Hash)]))
     }
 
     block := &types.Block{
@@ -319,6 +256,166 @@ func (s *BlockAPIService) blockToRosetta(blk *rpcBlock) (*types.BlockResponse, *
     return &types.BlockResponse{Block: block}, nil
 }
 
+// blockReceipts returns the receipt of every transaction in blk, in block
+// order. It uses eth_getBlockReceipts and falls back to batched
+// eth_getTransactionReceipt calls on nodes without it.
+func (s *BlockAPIService) blockReceipts(ctx context.Context, n *Network, blk *rpcBlock, txs []rpcTx) ([]*rpcReceipt, error) {
+    if receipts, ok := n.Cache.receipts(blk.Hash); ok && len(receipts) == len(txs) {
+        return receipts, nil
+    }
+    idx, _ := hexToInt64(blk.Number)
+
+    var receipts []*rpcReceipt
+    if err := n.RPC.call(ctx, "eth_getBlockReceipts", []interface{}{blk.Number}, &receipts); err == nil && len(receipts) == len(txs) {
+        for i := range receipts {
+            if receipts[i] == nil || !strings.EqualFold(receipts[i].TransactionHash, txs[i].Hash) {
+                return nil, fmt.Errorf("receipt %d of block %s does not match transaction %s", i, blk.Hash, txs[i].Hash)
+            }
+        }
+        n.Cache.putReceipts(idx, blk.Hash, blk.ParentHash, receipts)
+        return receipts, nil
+    }
+
+    receipts = make([]*rpcReceipt, len(txs))
+    calls := make([]*rpcCall, len(txs))
+    for i := range txs {
+        calls[i] = &rpcCall{Method: "eth_getTransactionReceipt", Params: []interface{}{txs[i].Hash}, Out: &receipts[i]}
+    }
+    if err := n.RPC.batch(ctx, calls); err != nil {
+        return nil, err
+    }
+    for i, call := range calls {
+        if call.Err != nil {
+            return nil, call.Err
+        }
+        if receipts[i] == nil {
+            return nil, fmt.Errorf("missing receipt for transaction %s", txs[i].Hash)
+        }
+    }
+    n.Cache.putReceipts(idx, blk.Hash, blk.ParentHash, receipts)
+    return receipts, nil
+}
+
+// minedTransaction maps a transaction included in blk to Rosetta. Metadata
+// carries the outcome (SUCCESS or FAILURE) and the receipt's gas figures, so
+// a reverted transaction is identifiable even though only its fee operations
+// remain.
+func minedTransaction(tx *rpcTx, rc *rpcReceipt, blk *rpcBlock, n *Network, internal []internalTransfer) *types.Transaction {
+    metadata := map[string]interface{}{
+        "status":              receiptStatus(rc),
+        "gas_used":            hexToBigIntMust(rc.GasUsed),
+        "effective_gas_price": hexToBigIntMust(rc.EffectiveGasPrice),
+    }
+    return &types.Transaction{
+        TransactionIdentifier: &types.TransactionIdentifier{Hash: tx.Hash},
+        Operations:            transactionOperations(tx, rc, blk, internal, n),
+        Metadata:              metadata,
+    }
+}
+
+// transactionOperations maps an RPC transaction to Rosetta operations in the
+// native currency of n. Pending transactions pass a nil receipt and block and
+// get only their value transfer, with no status.
+//
+// For mined transactions the receipt decides the outcome. A successful
+// transaction gets a Transfer pair for any non-zero value, a pair per
+// internal transfer found by tracing, and a pair per ERC-20 Transfer event of
+// a registered token. A reverted one moves no value,
+// so those operations are skipped and only fees are reported. Fees are
+// always charged and always SUCCESS: the sender pays the miner tip (credited
+// to the block's fee recipient) and, after London, a separate Fee debit for
+// the burned base fee.
+func transactionOperations(tx *rpcTx, rc *rpcReceipt, blk *rpcBlock, internal []internalTransfer, n *Network) []*types.Operation {
+    ops := []*types.Operation{}
+    var status *string
+    if rc != nil {
+        status = strPtr(receiptStatus(rc))
+    }
+
+    if rc == nil || *status == statusSuccess {
+        to := tx.To
+        if to == "" && rc != nil {
+            // contract creation: value moves to the new contract
+            to = rc.ContractAddress
+        }
+        if value, err := hexToBigInt(tx.Value); err == nil && value.Sign() > 0 && to != "" {
+            ops = appendTransfer(ops, tx.From, to, value, n.Currency, status, nil)
+        }
+        for _, t := range internal {
+            ops = appendTransfer(ops, t.From, t.To, t.Value, n.Currency, status, map[string]interface{}{"trace_type": t.Kind})
+        }
+        if rc != nil {
+            ops = tokenTransferOperations(ops, rc.Logs, n.Tokens, status)
+        }
+    }
+
+    if rc == nil || blk == nil {
+        return ops
+    }
+    return appendFeeOperations(ops, tx.From, rc, blk, n.Currency)
+}
+
+// appendFeeOperations splits the fee paid by sender into the tip earned by the
+// block's fee recipient and, for blocks with a base fee, the burned portion.
+// Blocks without a fee recipient report only the burned portion.
+func appendFeeOperations(ops []*types.Operation, sender string, rc *rpcReceipt, blk *rpcBlock, currency *types.Currency) []*types.Operation {
+    gasUsed, err1 := hexToBigInt(rc.GasUsed)
+    price, err2 := hexToBigInt(rc.EffectiveGasPrice)
+    if err1 != nil || err2 != nil {
+        return ops
+    }
+    total := new(big.Int).Mul(gasUsed, price)
+    burned := big.NewInt(0)
+    if baseFee, err := hexToBigInt(blk.BaseFeePerGas); err == nil {
+        burned.Mul(gasUsed, baseFee)
+    }
+    tip := new(big.Int).Sub(total, burned)
+
+    // The tip moves from sender to the fee recipient; without a known
+    // recipient the pair is skipped rather than left unbalanced.
+    if tip.Sign() > 0 && blk.Miner != "" {
+        debit := int64(len(ops))
+        ops = append(ops, &types.Operation{
+            OperationIdentifier: &types.OperationIdentifier{Index: debit},
+            Type:                "Fee",
+            Status:              strPtr(statusSuccess),
+            Account:             &types.AccountIdentifier{Address: sender},
+            Amount:              &types.Amount{Value: new(big.Int).Neg(tip).String(), Currency: currency},
+            Metadata:            map[string]interface{}{"fee_type": "tip"},
+        })
+        ops = append(ops, &types.Operation{
+            OperationIdentifier: &types.OperationIdentifier{Index: debit + 1},
+            RelatedOperations:   []*types.OperationIdentifier{{Index: debit}},
+            Type:                "Fee",
+            Status:              strPtr(statusSuccess),
+            Account:             &types.AccountIdentifier{Address: blk.Miner},
+            Amount:              &types.Amount{Value: tip.String(), Currency: currency},
+            Metadata:            map[string]interface{}{"fee_type": "tip"},
+        })
+    }
+    if burned.Sign() > 0 {
+        ops = append(ops, &types.Operation{
+            OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
+            Type:                "Fee",
+            Status:              strPtr(statusSuccess),
+            Account:             &types.AccountIdentifier{Address: sender},
+            Amount:              &types.Amount{Value: new(big.Int).Neg(burned).String(), Currency: currency},
+            Metadata:            map[string]interface{}{"fee_type": "burn"},
+        })
+    }
+    return ops
+}
+
+// receiptStatus maps a receipt to an operation status. Pre-Byzantium
+// receipts carry a state root instead of a status and are treated as
+// successful.
+func receiptStatus(rc *rpcReceipt) string {
+    if rc.Status == "0x0" {
+        return statusFailure
+    }
+    return statusSuccess
+}
+
 // helpers for math
 func hexToBigIntMust(h string) string {
     bi, err := hexToBigInt(h)
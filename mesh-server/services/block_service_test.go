@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSender = "0x1111111111111111111111111111111111111111"
+	testRecip  = "0x2222222222222222222222222222222222222222"
+	testMiner  = "0x3333333333333333333333333333333333333333"
+)
+
+func liveBlock() map[string]interface{} {
+	return map[string]interface{}{
+		"number":        "0x10",
+		"hash":          "0xb10",
+		"parentHash":    "0xb0f",
+		"timestamp":     "0x5",
+		"miner":         testMiner,
+		"baseFeePerGas": "0x7",
+		"transactions": []interface{}{
+			map[string]interface{}{"hash": "0xok", "from": testSender, "to": testRecip, "value": "0x64"},
+			map[string]interface{}{"hash": "0xreverted", "from": testSender, "to": testRecip, "value": "0x64"},
+			map[string]interface{}{"hash": "0xcall", "from": testSender, "to": testRecip, "value": "0x0"},
+		},
+	}
+}
+
+func liveReceipts() []interface{} {
+	receipt := func(hash, status string) map[string]interface{} {
+		return map[string]interface{}{
+			"transactionHash":   hash,
+			"status":            status,
+			"gasUsed":           "0x5208",
+			"effectiveGasPrice": "0xa",
+			"blockHash":         "0xb10",
+		}
+	}
+	return []interface{}{receipt("0xok", "0x1"), receipt("0xreverted", "0x0"), receipt("0xcall", "0x1")}
+}
+
+func opSummary(ops []*types.Operation) [][3]string {
+	out := [][3]string{}
+	for _, op := range ops {
+		out = append(out, [3]string{op.Type, *op.Status, op.Account.Address + " " + op.Amount.Value})
+	}
+	return out
+}
+
+func TestBlock_ReceiptsDriveStatusAndFees(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getBlockByNumber": liveBlock(),
+		"eth_getBlockReceipts": liveReceipts(),
+	}, nil)
+	s := NewBlockAPIService(testNetworks(t, rpc))
+
+	idx := int64(16)
+	resp, rErr := s.Block(context.Background(), &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.Nil(t, rErr)
+	txs := resp.Block.Transactions
+	require.Len(t, txs, 3)
+
+	// 21000 gas at 10 wei: 7 wei/gas burned, 3 wei/gas tip
+	fees := [][3]string{
+		{"Fee", "SUCCESS", testSender + " -63000"},
+		{"Fee", "SUCCESS", testMiner + " 63000"},
+		{"Fee", "SUCCESS", testSender + " -147000"},
+	}
+	assert.Equal(t, append([][3]string{
+		{"Transfer", "SUCCESS", testSender + " -100"},
+		{"Transfer", "SUCCESS", testRecip + " 100"},
+	}, fees...), opSummary(txs[0].Operations))
+	assert.Equal(t, fees, opSummary(txs[1].Operations), "reverted transfers only pay fees")
+	assert.Equal(t, "SUCCESS", txs[0].Metadata["status"])
+	assert.Equal(t, "FAILURE", txs[1].Metadata["status"])
+	assert.Equal(t, fees, opSummary(txs[2].Operations), "zero-value calls emit no transfer")
+	assert.Equal(t, "burn", txs[0].Operations[4].Metadata["fee_type"])
+}
+
+func TestAppendFeeOperations_NoFeeRecipient(t *testing.T) {
+	rc := &rpcReceipt{GasUsed: "0x5208", EffectiveGasPrice: "0xa"}
+
+	// 21000 gas at 10 wei with a 7 wei base fee: only the burn is reported
+	ops := appendFeeOperations(nil, testSender, rc, &rpcBlock{BaseFeePerGas: "0x7"}, ethCurrency)
+	assert.Equal(t, [][3]string{{"Fee", "SUCCESS", testSender + " -147000"}}, opSummary(ops))
+
+	ops = appendFeeOperations(nil, testSender, rc, &rpcBlock{BaseFeePerGas: "0x7", Miner: testMiner}, ethCurrency)
+	assert.Equal(t, [][3]string{
+		{"Fee", "SUCCESS", testSender + " -63000"},
+		{"Fee", "SUCCESS", testMiner + " 63000"},
+		{"Fee", "SUCCESS", testSender + " -147000"},
+	}, opSummary(ops))
+}
+
+func TestBlock_FallsBackToTransactionReceipts(t *testing.T) {
+	blk := liveBlock()
+	blk["transactions"] = blk["transactions"].([]interface{})[:1]
+	blk["baseFeePerGas"] = nil
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getBlockByNumber":      blk,
+		"eth_getTransactionReceipt": liveReceipts()[0],
+	}, nil)
+	s := NewBlockAPIService(testNetworks(t, rpc))
+
+	idx := int64(16)
+	resp, rErr := s.Block(context.Background(), &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.Nil(t, rErr)
+	// pre-London: the whole fee goes to the miner, nothing is burned
+	assert.Equal(t, [][3]string{
+		{"Transfer", "SUCCESS", testSender + " -100"},
+		{"Transfer", "SUCCESS", testRecip + " 100"},
+		{"Fee", "SUCCESS", testSender + " -210000"},
+		{"Fee", "SUCCESS", testMiner + " 210000"},
+	}, opSummary(resp.Block.Transactions[0].Operations))
+}
+
+func TestBlock_ProofOfWorkRewards(t *testing.T) {
+	const uncleMiner = "0x4444444444444444444444444444444444444444"
+	blk := liveBlock()
+	blk["number"] = "0x6f0f20" // 7278368, before Constantinople: 3 ETH
+	blk["difficulty"] = "0x1"
+	blk["uncles"] = []string{"0xuncle"}
+	blk["transactions"] = []interface{}{}
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getBlockByNumber":            blk,
+		"eth_getUncleByBlockHashAndIndex": map[string]interface{}{"number": "0x6f0f1e", "miner": uncleMiner},
+	}, nil)
+	networks := testNetworks(t, rpc)
+	rewards, err := parseRewardEras(mainnetRewards)
+	require.NoError(t, err)
+	networks.list[0].rewards = rewards
+	s := NewBlockAPIService(networks)
+
+	resp, rErr := s.Block(context.Background(), &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{},
+	})
+	require.Nil(t, rErr)
+	require.Len(t, resp.Block.Transactions, 1)
+	tx := resp.Block.Transactions[0]
+	assert.Equal(t, "0xb10", tx.TransactionIdentifier.Hash)
+	// uncle two blocks back earns 6/8 of 3 ETH; the miner adds 1/32 of 3 ETH
+	assert.Equal(t, [][3]string{
+		{"Reward", "SUCCESS", uncleMiner + " 2250000000000000000"},
+		{"Reward", "SUCCESS", testMiner + " 3093750000000000000"},
+	}, opSummary(tx.Operations))
+}
+
+func TestBlock_WithdrawalsAfterMerge(t *testing.T) {
+	blk := liveBlock()
+	blk["difficulty"] = "0x0"
+	blk["transactions"] = []interface{}{}
+	blk["withdrawals"] = []interface{}{
+		map[string]interface{}{"index": "0x1", "validatorIndex": "0x2a", "address": testRecip, "amount": "0x3b9aca00"},
+		map[string]interface{}{"index": "0x2", "validatorIndex": "0x2b", "address": testSender, "amount": "0x0"},
+	}
+	rpc := newStubRPC(t, map[string]interface{}{"eth_getBlockByNumber": blk}, nil)
+	networks := testNetworks(t, rpc)
+	rewards, err := parseRewardEras(sepoliaRewards)
+	require.NoError(t, err)
+	networks.list[0].rewards = rewards
+	s := NewBlockAPIService(networks)
+
+	resp, rErr := s.Block(context.Background(), &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{},
+	})
+	require.Nil(t, rErr)
+	require.Len(t, resp.Block.Transactions, 1)
+	ops := resp.Block.Transactions[0].Operations
+	// 1e9 Gwei is 1 ETH; zero-amount withdrawals are skipped
+	assert.Equal(t, [][3]string{{"Withdrawal", "SUCCESS", testRecip + " 1000000000000000000"}}, opSummary(ops))
+	assert.Equal(t, "42", ops[0].Metadata["validator_index"])
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Call methods served by /call.
+const (
+	// CallEthCall runs eth_call with ABI-encoded calldata.
+	// Parameters: to, data, optional from and a block (see callBlock).
+	// Result: {"data": "0x..."}.
+	CallEthCall = "eth_call"
+	// CallTransactionReceipt returns a receipt as the node reports it.
+	// Parameters: tx_hash. Result: the receipt object.
+	CallTransactionReceipt = "eth_getTransactionReceipt"
+	// CallGetCode returns contract bytecode.
+	// Parameters: address and a block. Result: {"code": "0x..."}.
+	CallGetCode = "eth_getCode"
+	// CallERC20Metadata reads an ERC-20 token's name, symbol and decimals.
+	// Parameters: address and a block. Result: whichever of name, symbol
+	// and decimals the contract implements.
+	CallERC20Metadata = "erc20_metadata"
+)
+
+// CallMethods lists the methods advertised in /network/options.
+var CallMethods = []string{CallEthCall, CallTransactionReceipt, CallGetCode, CallERC20Metadata}
+
+// ERC-20 metadata selectors.
+const (
+	erc20NameSelector     = "0x06fdde03"
+	erc20SymbolSelector   = "0x95d89b41"
+	erc20DecimalsSelector = "0x313ce567"
+)
+
+// CallAPIService implements the Call API interface
+type CallAPIService struct {
+	networks *Networks
+}
+
+// NewCallAPIService creates a new CallAPIService
+func NewCallAPIService(networks *Networks) *CallAPIService {
+	return &CallAPIService{networks: networks}
+}
+
+// Call implements the /call endpoint. Calls always read the node: there is
+// no mock contract state, so networks serving mock data reject them.
+func (s *CallAPIService) Call(
+	ctx context.Context,
+	request *types.CallRequest,
+) (*types.CallResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	if n.mode() == ModeMock {
+		return nil, wrapErr(ErrCallFailed, errors.New("network serves mock data and cannot execute calls"))
+	}
+
+	var resp *types.CallResponse
+	switch request.Method {
+	case CallEthCall:
+		resp, rErr = s.ethCall(ctx, n, request.Parameters)
+	case CallTransactionReceipt:
+		resp, rErr = s.transactionReceipt(ctx, n, request.Parameters)
+	case CallGetCode:
+		resp, rErr = s.getCode(ctx, n, request.Parameters)
+	case CallERC20Metadata:
+		resp, rErr = s.erc20Metadata(ctx, n, request.Parameters)
+	default:
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("unsupported call method %q", request.Method))
+	}
+	if rErr != nil {
+		return nil, rErr
+	}
+	markSource(ctx, SourceLive)
+	return resp, nil
+}
+
+func (s *CallAPIService) ethCall(ctx context.Context, n *Network, params map[string]interface{}) (*types.CallResponse, *types.Error) {
+	to, rErr := addressParam(params, "to")
+	if rErr != nil {
+		return nil, rErr
+	}
+	data, rErr := hexParam(params, "data")
+	if rErr != nil {
+		return nil, rErr
+	}
+	msg := map[string]interface{}{"to": to, "data": data}
+	if _, ok := params["from"]; ok {
+		from, rErr := addressParam(params, "from")
+		if rErr != nil {
+			return nil, rErr
+		}
+		msg["from"] = from
+	}
+	block, pinned, rErr := callBlock(params)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	var out string
+	if err := n.RPC.call(ctx, "eth_call", []interface{}{msg, block}, &out); err != nil {
+		return nil, callFailure(err)
+	}
+	return &types.CallResponse{Result: map[string]interface{}{"data": out}, Idempotent: pinned}, nil
+}
+
+func (s *CallAPIService) transactionReceipt(ctx context.Context, n *Network, params map[string]interface{}) (*types.CallResponse, *types.Error) {
+	hash, rErr := hashParam(params, "tx_hash")
+	if rErr != nil {
+		return nil, rErr
+	}
+	var receipt map[string]interface{}
+	if err := n.RPC.call(ctx, "eth_getTransactionReceipt", []interface{}{hash}, &receipt); err != nil {
+		return nil, callFailure(err)
+	}
+	if receipt == nil {
+		return nil, wrapErr(ErrTransactionNotFound, fmt.Errorf("no receipt for transaction %s", hash))
+	}
+	// a reorg can move the transaction to another block
+	return &types.CallResponse{Result: receipt, Idempotent: false}, nil
+}
+
+func (s *CallAPIService) getCode(ctx context.Context, n *Network, params map[string]interface{}) (*types.CallResponse, *types.Error) {
+	address, rErr := addressParam(params, "address")
+	if rErr != nil {
+		return nil, rErr
+	}
+	block, pinned, rErr := callBlock(params)
+	if rErr != nil {
+		return nil, rErr
+	}
+	var code string
+	if err := n.RPC.call(ctx, "eth_getCode", []interface{}{address, block}, &code); err != nil {
+		return nil, callFailure(err)
+	}
+	return &types.CallResponse{Result: map[string]interface{}{"code": code}, Idempotent: pinned}, nil
+}
+
+// erc20Metadata reads name, symbol and decimals in one batch. All three are
+// optional in ERC-20, so calls that revert are left out of the result; only
+// a contract implementing none of them is an error.
+func (s *CallAPIService) erc20Metadata(ctx context.Context, n *Network, params map[string]interface{}) (*types.CallResponse, *types.Error) {
+	address, rErr := addressParam(params, "address")
+	if rErr != nil {
+		return nil, rErr
+	}
+	block, pinned, rErr := callBlock(params)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	selectors := []string{erc20NameSelector, erc20SymbolSelector, erc20DecimalsSelector}
+	results := make([]hexutil.Bytes, len(selectors))
+	calls := make([]*rpcCall, len(selectors))
+	for i, sel := range selectors {
+		msg := map[string]interface{}{"to": address, "data": sel}
+		calls[i] = &rpcCall{Method: "eth_call", Params: []interface{}{msg, block}, Out: &results[i]}
+	}
+	if err := n.RPC.batch(ctx, calls); err != nil {
+		return nil, wrapErr(ErrNetwork, err)
+	}
+
+	result := map[string]interface{}{}
+	if name, ok := decodeABIString(results[0]); ok && calls[0].Err == nil {
+		result["name"] = name
+	}
+	if symbol, ok := decodeABIString(results[1]); ok && calls[1].Err == nil {
+		result["symbol"] = symbol
+	}
+	if len(results[2]) == 32 && calls[2].Err == nil {
+		if d := new(big.Int).SetBytes(results[2]); d.IsInt64() && d.Int64() <= 255 {
+			result["decimals"] = d.Int64()
+		}
+	}
+	if len(result) == 0 {
+		return nil, wrapErr(ErrCallFailed, fmt.Errorf("%s does not implement ERC-20 metadata", address))
+	}
+	return &types.CallResponse{Result: result, Idempotent: pinned}, nil
+}
+
+// callFailure maps a failed RPC call: errors returned by the node, such as a
+// revert or invalid parameters, fail the call for good; anything else is a
+// network error worth retrying.
+func callFailure(err error) *types.Error {
+	var rpcErr *rpcError
+	if errors.As(err, &rpcErr) {
+		return wrapErr(ErrCallFailed, err)
+	}
+	return wrapErr(ErrNetwork, err)
+}
+
+// callBlock selects the block a call reads state at: block_hash or
+// block_index when given, else the latest block. Only calls pinned to a
+// hash are idempotent; a number can be reorganized away.
+func callBlock(params map[string]interface{}) (interface{}, bool, *types.Error) {
+	if _, ok := params["block_hash"]; ok {
+		hash, rErr := hashParam(params, "block_hash")
+		if rErr != nil {
+			return nil, false, rErr
+		}
+		// EIP-1898 block reference
+		return map[string]interface{}{"blockHash": hash}, true, nil
+	}
+	if v, ok := params["block_index"]; ok {
+		index, ok := v.(float64)
+		if !ok || index < 0 || index != float64(int64(index)) {
+			return nil, false, wrapErr(ErrInvalidRequest, fmt.Errorf("block_index must be a non-negative integer, got %v", v))
+		}
+		return int64ToHex(int64(index)), false, nil
+	}
+	return "latest", false, nil
+}
+
+func stringParam(params map[string]interface{}, key string) (string, *types.Error) {
+	v, ok := params[key].(string)
+	if !ok || v == "" {
+		return "", wrapErr(ErrInvalidRequest, fmt.Errorf("parameter %s is required", key))
+	}
+	return v, nil
+}
+
+func addressParam(params map[string]interface{}, key string) (string, *types.Error) {
+	v, rErr := stringParam(params, key)
+	if rErr != nil {
+		return "", rErr
+	}
+	if !common.IsHexAddress(v) {
+		return "", wrapErr(ErrInvalidRequest, fmt.Errorf("parameter %s is not an address: %s", key, v))
+	}
+	return v, nil
+}
+
+func hexParam(params map[string]interface{}, key string) (string, *types.Error) {
+	v, rErr := stringParam(params, key)
+	if rErr != nil {
+		return "", rErr
+	}
+	if _, err := hexutil.Decode(v); err != nil {
+		return "", wrapErr(ErrInvalidRequest, fmt.Errorf("parameter %s: %w", key, err))
+	}
+	return v, nil
+}
+
+func hashParam(params map[string]interface{}, key string) (string, *types.Error) {
+	v, rErr := hexParam(params, key)
+	if rErr != nil {
+		return "", rErr
+	}
+	if len(v) != 2+2*common.HashLength {
+		return "", wrapErr(ErrInvalidRequest, fmt.Errorf("parameter %s is not a 32-byte hash: %s", key, v))
+	}
+	return v, nil
+}
+
+// decodeABIString decodes an ABI-encoded string return value. Some early
+// tokens return bytes32 instead, which is decoded up to its first zero byte.
+func decodeABIString(b []byte) (string, bool) {
+	if len(b) == 32 {
+		s := string(b)
+		if i := strings.IndexByte(s, 0); i >= 0 {
+			s = s[:i]
+		}
+		return s, s != ""
+	}
+	if len(b) < 64 {
+		return "", false
+	}
+	// offsets and lengths come from the contract; compare them without
+	// adding so a huge value cannot overflow past the bounds checks
+	offset := new(big.Int).SetBytes(b[:32])
+	if offset.Cmp(big.NewInt(int64(len(b)-32))) > 0 {
+		return "", false
+	}
+	start := int(offset.Int64()) + 32
+	length := new(big.Int).SetBytes(b[start-32 : start])
+	if length.Cmp(big.NewInt(int64(len(b)-start))) > 0 {
+		return "", false
+	}
+	return string(b[start : start+int(length.Int64())]), true
+}
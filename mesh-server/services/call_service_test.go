@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHash = "0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6"
+
+// abiString ABI-encodes s as a single string return value.
+func abiString(s string) string {
+	padded := common.RightPadBytes([]byte(s), (len(s)+31)/32*32)
+	return "0x" + word(32) + word(int64(len(s))) + common.Bytes2Hex(padded)
+}
+
+func word(n int64) string {
+	const zeros = "0000000000000000000000000000000000000000000000000000000000000000"
+	h := int64ToHex(n)[2:]
+	return zeros[len(h):] + h
+}
+
+func TestCall_EthCallPassesCalldataThrough(t *testing.T) {
+	var params []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int64             `json:"id"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		params = req.Params
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x" + word(42)})
+	}))
+	defer srv.Close()
+	s := NewCallAPIService(testNetworks(t, NewEthRPC(srv.URL)))
+
+	data := balanceOfCallData(testSender)
+	resp, rErr := s.Call(context.Background(), &types.CallRequest{
+		NetworkIdentifier: sepolia,
+		Method:            CallEthCall,
+		Parameters:        map[string]interface{}{"to": testToken, "data": data, "block_hash": testHash},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, "0x"+word(42), resp.Result["data"])
+	assert.True(t, resp.Idempotent, "pinned to a block hash")
+
+	require.Len(t, params, 2)
+	assert.JSONEq(t, `{"to": "`+testToken+`", "data": "`+data+`"}`, string(params[0]))
+	assert.JSONEq(t, `{"blockHash": "`+testHash+`"}`, string(params[1]))
+}
+
+func TestCall_ERC20Metadata(t *testing.T) {
+	// the stub answers every eth_call alike, so use a name that is also a
+	// valid symbol and read decimals from a second contract
+	rpc := newStubRPC(t, map[string]interface{}{"eth_call": abiString("Wrapped Ether")}, nil)
+	s := NewCallAPIService(testNetworks(t, rpc))
+	resp, rErr := s.Call(context.Background(), &types.CallRequest{
+		NetworkIdentifier: sepolia,
+		Method:            CallERC20Metadata,
+		Parameters:        map[string]interface{}{"address": testToken},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, map[string]interface{}{"name": "Wrapped Ether", "symbol": "Wrapped Ether"}, resp.Result)
+	assert.False(t, resp.Idempotent)
+
+	rpc = newStubRPC(t, map[string]interface{}{"eth_call": "0x" + word(6)}, nil)
+	s = NewCallAPIService(testNetworks(t, rpc))
+	resp, rErr = s.Call(context.Background(), &types.CallRequest{
+		NetworkIdentifier: sepolia,
+		Method:            CallERC20Metadata,
+		Parameters:        map[string]interface{}{"address": testToken, "block_index": float64(16)},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, int64(6), resp.Result["decimals"])
+}
+
+func TestCall_Errors(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{"eth_getTransactionReceipt": nil}, nil)
+	s := NewCallAPIService(testNetworks(t, rpc))
+	call := func(method string, params map[string]interface{}) *types.Error {
+		_, rErr := s.Call(context.Background(), &types.CallRequest{NetworkIdentifier: sepolia, Method: method, Parameters: params})
+		return rErr
+	}
+
+	rErr := call(CallEthCall, map[string]interface{}{"to": "not-an-address", "data": "0x"})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrInvalidRequest.Code, rErr.Code)
+
+	rErr = call(CallGetCode, map[string]interface{}{"address": testToken, "block_index": -1.0})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrInvalidRequest.Code, rErr.Code)
+
+	// the node rejects eth_call: a final error, not a network one
+	rErr = call(CallEthCall, map[string]interface{}{"to": testToken, "data": "0x"})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrCallFailed.Code, rErr.Code)
+	assert.False(t, rErr.Retriable)
+
+	rErr = call(CallTransactionReceipt, map[string]interface{}{"tx_hash": testHash})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrTransactionNotFound.Code, rErr.Code)
+
+	// mock networks have no contract state to call
+	s = NewCallAPIService(testNetworks(t, nil))
+	rErr = call(CallGetCode, map[string]interface{}{"address": testToken})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrCallFailed.Code, rErr.Code)
+}
+
+func TestDecodeABIString_Bytes32(t *testing.T) {
+	b := make([]byte, 32)
+	copy(b, "MKR")
+	s, ok := decodeABIString(b)
+	assert.True(t, ok)
+	assert.Equal(t, "MKR", s)
+}
+
+func TestDecodeABIString_RejectsOutOfRange(t *testing.T) {
+	word := func(hex string) []byte { return common.LeftPadBytes(common.FromHex(hex), 32) }
+	encode := func(words ...[]byte) []byte {
+		out := []byte{}
+		for _, w := range words {
+			out = append(out, w...)
+		}
+		return out
+	}
+	text := common.RightPadBytes([]byte("Token"), 32)
+
+	s, ok := decodeABIString(encode(word("0x20"), word("0x05"), text))
+	assert.True(t, ok)
+	assert.Equal(t, "Token", s)
+
+	for name, b := range map[string][]byte{
+		"offset near MaxInt64": encode(word("0x7fffffffffffffff"), word("0x05"), text),
+		"offset past the end":  encode(word("0x60"), word("0x05"), text),
+		"length near MaxInt64": encode(word("0x20"), word("0x7fffffffffffffff"), text),
+		"length past the end":  encode(word("0x20"), word("0x21"), text),
+		"offset over 64 bits":  encode(word("0x010000000000000000"), word("0x05"), text),
+	} {
+		s, ok := decodeABIString(b)
+		assert.False(t, ok, name)
+		assert.Empty(t, s, name)
+	}
+}
@@ -0,0 +1,680 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rutishh0/mesh-server/fees"
+)
+
+const (
+	// transferGasLimit is the intrinsic gas of a plain ETH transfer, used when
+	// eth_estimateGas is unavailable.
+	transferGasLimit = 21000
+
+	// defaultPriorityFee (1.5 gwei) is used when the node does not support
+	// eth_maxPriorityFeePerGas.
+	defaultPriorityFee = 1500000000
+)
+
+// ethCurrency is the native currency of the Ethereum networks.
+var ethCurrency = &types.Currency{Symbol: "ETH", Decimals: 18}
+
+// constructionOptions is produced by /construction/preprocess and consumed by
+// /construction/metadata.
+type constructionOptions struct {
+	From                 string          `json:"from"`
+	To                   string          `json:"to"`
+	Value                *hexutil.Big    `json:"value"`
+	GasLimit             *hexutil.Uint64 `json:"gas_limit,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"max_priority_fee_per_gas,omitempty"`
+	Urgency              string          `json:"urgency,omitempty"`
+}
+
+// constructionMetadata is returned by /construction/metadata and consumed by
+// /construction/payloads.
+type constructionMetadata struct {
+	Nonce                hexutil.Uint64 `json:"nonce"`
+	GasLimit             hexutil.Uint64 `json:"gas_limit"`
+	MaxFeePerGas         *hexutil.Big   `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"max_priority_fee_per_gas"`
+	ChainID              *hexutil.Big   `json:"chain_id"`
+}
+
+// unsignedTransaction is the JSON form of an EIP-1559 transaction before it
+// is signed. It carries From so /construction/parse can report the signer.
+type unsignedTransaction struct {
+	From                 string         `json:"from"`
+	To                   string         `json:"to"`
+	Value                *hexutil.Big   `json:"value"`
+	Data                 hexutil.Bytes  `json:"data"`
+	Nonce                hexutil.Uint64 `json:"nonce"`
+	GasLimit             hexutil.Uint64 `json:"gas_limit"`
+	MaxFeePerGas         *hexutil.Big   `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"max_priority_fee_per_gas"`
+	ChainID              *hexutil.Big   `json:"chain_id"`
+}
+
+func (u *unsignedTransaction) toTx() *ethtypes.Transaction {
+	to := common.HexToAddress(u.To)
+	return ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   u.ChainID.ToInt(),
+		Nonce:     uint64(u.Nonce),
+		GasTipCap: u.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: u.MaxFeePerGas.ToInt(),
+		Gas:       uint64(u.GasLimit),
+		To:        &to,
+		Value:     u.Value.ToInt(),
+		Data:      u.Data,
+	})
+}
+
+// ConstructionAPIService implements the Construction API interface for
+// EIP-1559 native currency transfers. Only /construction/metadata and
+// /construction/submit need the node; every other endpoint works offline.
+type ConstructionAPIService struct {
+	networks *Networks
+}
+
+// NewConstructionAPIService creates a new ConstructionAPIService
+func NewConstructionAPIService(networks *Networks) *ConstructionAPIService {
+	return &ConstructionAPIService{networks: networks}
+}
+
+// signer returns the EIP-1559 signer for the network's chain ID.
+func (n *Network) signer() ethtypes.Signer {
+	return ethtypes.NewLondonSigner(n.ChainID)
+}
+
+// ConstructionDerive implements the /construction/derive endpoint
+func (s *ConstructionAPIService) ConstructionDerive(
+	ctx context.Context,
+	request *types.ConstructionDeriveRequest,
+) (*types.ConstructionDeriveResponse, *types.Error) {
+	if _, rErr := s.networks.Lookup(request.NetworkIdentifier); rErr != nil {
+		return nil, rErr
+	}
+	if request.PublicKey == nil || request.PublicKey.CurveType != types.Secp256k1 {
+		return nil, wrapErr(ErrInvalidRequest, errors.New("public key must use the secp256k1 curve"))
+	}
+	address, err := addressFromPublicKey(request.PublicKey.Bytes)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	return &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{Address: address},
+	}, nil
+}
+
+// ConstructionPreprocess implements the /construction/preprocess endpoint
+func (s *ConstructionAPIService) ConstructionPreprocess(
+	ctx context.Context,
+	request *types.ConstructionPreprocessRequest,
+) (*types.ConstructionPreprocessResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	from, to, value, err := parseTransferOperations(request.Operations, n.Currency)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+
+	opts := constructionOptions{
+		From:  from,
+		To:    to,
+		Value: (*hexutil.Big)(value),
+	}
+	// Callers may pin fee parameters through request metadata.
+	if v, ok := request.Metadata["gas_limit"]; ok {
+		limit, err := decodeUint64(v)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("gas_limit: %w", err))
+		}
+		gl := hexutil.Uint64(limit)
+		opts.GasLimit = &gl
+	}
+	if v, ok := request.Metadata["max_fee_per_gas"]; ok {
+		fee, err := decodeBig(v)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("max_fee_per_gas: %w", err))
+		}
+		opts.MaxFeePerGas = (*hexutil.Big)(fee)
+	}
+	if v, ok := request.Metadata["max_priority_fee_per_gas"]; ok {
+		tip, err := decodeBig(v)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("max_priority_fee_per_gas: %w", err))
+		}
+		opts.MaxPriorityFeePerGas = (*hexutil.Big)(tip)
+	}
+	if v, ok := request.Metadata["urgency"]; ok {
+		urgency, _ := v.(string)
+		if !fees.Urgency(urgency).Valid() {
+			return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("urgency must be slow, normal or fast, got %v", v))
+		}
+		opts.Urgency = urgency
+	}
+
+	options, err := marshalToMap(opts)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	return &types.ConstructionPreprocessResponse{
+		Options: options,
+		RequiredPublicKeys: []*types.AccountIdentifier{
+			{Address: from},
+		},
+	}, nil
+}
+
+// ConstructionMetadata implements the /construction/metadata endpoint
+func (s *ConstructionAPIService) ConstructionMetadata(
+	ctx context.Context,
+	request *types.ConstructionMetadataRequest,
+) (*types.ConstructionMetadataResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	if n.RPC == nil {
+		return nil, wrapErr(ErrNetwork, fmt.Errorf("no rpc configured for %s", networkKey(n.Identifier)))
+	}
+
+	var opts constructionOptions
+	if err := unmarshalFromMap(request.Options, &opts); err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	if !common.IsHexAddress(opts.From) || !common.IsHexAddress(opts.To) || opts.Value == nil {
+		return nil, wrapErr(ErrInvalidRequest, errors.New("options must include from, to and value"))
+	}
+
+	urgency := fees.Urgency(opts.Urgency)
+	if urgency == "" {
+		urgency = fees.Normal
+	}
+	if !urgency.Valid() {
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("unknown urgency %q", opts.Urgency))
+	}
+
+	// Everything the node has to supply goes out in one batch; the gas
+	// estimate, fee history and tip suggestion are optional and have
+	// defaults.
+	var (
+		nonce     hexutil.Uint64
+		estimate  hexutil.Uint64
+		suggested hexutil.Big
+		head      rpcBlock
+		history   fees.History
+	)
+	nonceCall := &rpcCall{Method: "eth_getTransactionCount", Params: []interface{}{opts.From, "pending"}, Out: &nonce}
+	calls := []*rpcCall{nonceCall}
+	var estimateCall, tipCall, headCall *rpcCall
+	if opts.GasLimit == nil {
+		call := map[string]interface{}{"from": opts.From, "to": opts.To, "value": opts.Value}
+		estimateCall = &rpcCall{Method: "eth_estimateGas", Params: []interface{}{call}, Out: &estimate}
+		calls = append(calls, estimateCall)
+	}
+	if opts.MaxPriorityFeePerGas == nil {
+		tipCall = &rpcCall{Method: "eth_maxPriorityFeePerGas", Params: []interface{}{}, Out: &suggested}
+		calls = append(calls, tipCall)
+	}
+	if opts.MaxFeePerGas == nil {
+		headCall = &rpcCall{Method: "eth_getBlockByNumber", Params: []interface{}{"latest", false}, Out: &head}
+		calls = append(calls, headCall)
+	}
+	var historyCall *rpcCall
+	if tipCall != nil || headCall != nil {
+		historyCall = &rpcCall{Method: "eth_feeHistory", Params: fees.HistoryParams(), Out: &history}
+		calls = append(calls, historyCall)
+	}
+	if err := n.RPC.batch(ctx, calls); err != nil {
+		return nil, wrapErr(ErrNetwork, err)
+	}
+	if nonceCall.Err != nil {
+		return nil, wrapErr(ErrNetwork, nonceCall.Err)
+	}
+
+	gasLimit := hexutil.Uint64(transferGasLimit)
+	if opts.GasLimit != nil {
+		gasLimit = *opts.GasLimit
+	} else if estimateCall.Err == nil && estimate > 0 {
+		gasLimit = estimate
+	}
+
+	// The tip is the urgency's percentile of recent rewards, or the node's
+	// suggestion when recent blocks carry no tips at that percentile.
+	tip := opts.MaxPriorityFeePerGas.ToInt()
+	if tipCall != nil {
+		if historyCall.Err != nil {
+			history = fees.History{}
+		}
+		tips, _ := history.Tips(func() (*big.Int, error) {
+			if tipCall.Err == nil {
+				return suggested.ToInt(), nil
+			}
+			return big.NewInt(defaultPriorityFee), nil
+		})
+		tip = tips[urgency]
+	}
+
+	maxFee := opts.MaxFeePerGas.ToInt()
+	if headCall != nil {
+		// Fee history ends with the next block's base fee; the latest block's
+		// is used when the node does not support eth_feeHistory.
+		baseFee := history.NextBaseFee()
+		if historyCall.Err != nil || baseFee == nil {
+			if headCall.Err != nil {
+				return nil, wrapErr(ErrNetwork, headCall.Err)
+			}
+			var err error
+			baseFee, err = hexToBigInt(head.BaseFeePerGas)
+			if err != nil {
+				return nil, wrapErr(ErrNetwork, fmt.Errorf("latest block has no base fee: %w", err))
+			}
+		}
+		maxFee = fees.MaxFee(urgency, baseFee, tip)
+	}
+	if maxFee.Cmp(tip) < 0 {
+		return nil, wrapErr(ErrInvalidRequest, errors.New("max_fee_per_gas is below max_priority_fee_per_gas"))
+	}
+
+	meta, err := marshalToMap(constructionMetadata{
+		Nonce:                nonce,
+		GasLimit:             gasLimit,
+		MaxFeePerGas:         (*hexutil.Big)(maxFee),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tip),
+		ChainID:              (*hexutil.Big)(n.ChainID),
+	})
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+
+	suggestedFee := new(big.Int).Mul(maxFee, new(big.Int).SetUint64(uint64(gasLimit)))
+	markSource(ctx, SourceLive)
+	return &types.ConstructionMetadataResponse{
+		Metadata: meta,
+		SuggestedFee: []*types.Amount{
+			{Value: suggestedFee.String(), Currency: n.Currency},
+		},
+	}, nil
+}
+
+// ConstructionPayloads implements the /construction/payloads endpoint
+func (s *ConstructionAPIService) ConstructionPayloads(
+	ctx context.Context,
+	request *types.ConstructionPayloadsRequest,
+) (*types.ConstructionPayloadsResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	from, to, value, err := parseTransferOperations(request.Operations, n.Currency)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+
+	var meta constructionMetadata
+	if err := unmarshalFromMap(request.Metadata, &meta); err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	if meta.MaxFeePerGas == nil || meta.MaxPriorityFeePerGas == nil || meta.GasLimit == 0 {
+		return nil, wrapErr(ErrInvalidRequest, errors.New("metadata must include gas_limit, max_fee_per_gas and max_priority_fee_per_gas"))
+	}
+	if meta.ChainID != nil && meta.ChainID.ToInt().Cmp(n.ChainID) != 0 {
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("metadata chain_id %s does not match network chain id %s", meta.ChainID.ToInt(), n.ChainID))
+	}
+
+	unsigned := &unsignedTransaction{
+		From:                 from,
+		To:                   to,
+		Value:                (*hexutil.Big)(value),
+		Data:                 hexutil.Bytes{},
+		Nonce:                meta.Nonce,
+		GasLimit:             meta.GasLimit,
+		MaxFeePerGas:         meta.MaxFeePerGas,
+		MaxPriorityFeePerGas: meta.MaxPriorityFeePerGas,
+		ChainID:              (*hexutil.Big)(n.ChainID),
+	}
+	raw, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+
+	hash := n.signer().Hash(unsigned.toTx())
+	return &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: string(raw),
+		Payloads: []*types.SigningPayload{
+			{
+				AccountIdentifier: &types.AccountIdentifier{Address: from},
+				Bytes:             hash.Bytes(),
+				SignatureType:     types.EcdsaRecovery,
+			},
+		},
+	}, nil
+}
+
+// ConstructionCombine implements the /construction/combine endpoint
+func (s *ConstructionAPIService) ConstructionCombine(
+	ctx context.Context,
+	request *types.ConstructionCombineRequest,
+) (*types.ConstructionCombineResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	var unsigned unsignedTransaction
+	if err := json.Unmarshal([]byte(request.UnsignedTransaction), &unsigned); err != nil {
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("unable to decode unsigned transaction: %w", err))
+	}
+	if len(request.Signatures) != 1 {
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("expected 1 signature, got %d", len(request.Signatures)))
+	}
+	sig := request.Signatures[0]
+	if sig.SignatureType != types.EcdsaRecovery {
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("unsupported signature type %s", sig.SignatureType))
+	}
+
+	signed, err := unsigned.toTx().WithSignature(n.signer(), sig.Bytes)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	// The recovered sender must be the account the payload was built for.
+	sender, err := ethtypes.Sender(n.signer(), signed)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	if !strings.EqualFold(sender.Hex(), unsigned.From) {
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("signature recovers to %s, expected %s", sender.Hex(), unsigned.From))
+	}
+
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	return &types.ConstructionCombineResponse{
+		SignedTransaction: hexutil.Encode(raw),
+	}, nil
+}
+
+// ConstructionParse implements the /construction/parse endpoint
+func (s *ConstructionAPIService) ConstructionParse(
+	ctx context.Context,
+	request *types.ConstructionParseRequest,
+) (*types.ConstructionParseResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	var (
+		from    string
+		tx      *ethtypes.Transaction
+		signers []*types.AccountIdentifier
+	)
+	if request.Signed {
+		signed, err := decodeSignedTransaction(request.Transaction)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidRequest, err)
+		}
+		sender, err := ethtypes.Sender(n.signer(), signed)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidRequest, err)
+		}
+		tx = signed
+		from = sender.Hex()
+		signers = []*types.AccountIdentifier{{Address: from}}
+	} else {
+		var unsigned unsignedTransaction
+		if err := json.Unmarshal([]byte(request.Transaction), &unsigned); err != nil {
+			return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("unable to decode unsigned transaction: %w", err))
+		}
+		tx = unsigned.toTx()
+		from = unsigned.From
+	}
+	if tx.To() == nil {
+		return nil, wrapErr(ErrInvalidRequest, errors.New("contract creation is not supported"))
+	}
+
+	meta, err := marshalToMap(constructionMetadata{
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		GasLimit:             hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		ChainID:              (*hexutil.Big)(tx.ChainId()),
+	})
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+
+	return &types.ConstructionParseResponse{
+		Operations:               transferOperations(from, tx.To().Hex(), tx.Value(), n.Currency, nil),
+		AccountIdentifierSigners: signers,
+		Metadata:                 meta,
+	}, nil
+}
+
+// ConstructionHash implements the /construction/hash endpoint
+func (s *ConstructionAPIService) ConstructionHash(
+	ctx context.Context,
+	request *types.ConstructionHashRequest,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	if _, rErr := s.networks.Lookup(request.NetworkIdentifier); rErr != nil {
+		return nil, rErr
+	}
+	signed, err := decodeSignedTransaction(request.SignedTransaction)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+	return &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: signed.Hash().Hex()},
+	}, nil
+}
+
+// ConstructionSubmit implements the /construction/submit endpoint
+func (s *ConstructionAPIService) ConstructionSubmit(
+	ctx context.Context,
+	request *types.ConstructionSubmitRequest,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	if n.RPC == nil {
+		return nil, wrapErr(ErrNetwork, fmt.Errorf("no rpc configured for %s", networkKey(n.Identifier)))
+	}
+	signed, err := decodeSignedTransaction(request.SignedTransaction)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidRequest, err)
+	}
+
+	var txHash string
+	if err := n.RPC.call(ctx, "eth_sendRawTransaction", []interface{}{request.SignedTransaction}, &txHash); err != nil {
+		return nil, wrapErr(ErrBroadcastFailed, err)
+	}
+	if txHash == "" {
+		txHash = signed.Hash().Hex()
+	}
+	markSource(ctx, SourceLive)
+	return &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: txHash},
+	}, nil
+}
+
+// transferOperations builds the debit/credit pair describing a value transfer.
+// status is nil for construction responses and set for block data.
+func transferOperations(from, to string, value *big.Int, currency *types.Currency, status *string) []*types.Operation {
+	return appendTransfer(nil, from, to, value, currency, status, nil)
+}
+
+// appendTransfer appends a debit of value from `from` and a related credit to
+// `to`, indexed after the operations already in ops.
+func appendTransfer(
+	ops []*types.Operation,
+	from, to string,
+	value *big.Int,
+	currency *types.Currency,
+	status *string,
+	metadata map[string]interface{},
+) []*types.Operation {
+	debit := int64(len(ops))
+	return append(ops,
+		&types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: debit},
+			Type:                "Transfer",
+			Status:              status,
+			Account:             &types.AccountIdentifier{Address: from},
+			Amount:              &types.Amount{Value: new(big.Int).Neg(value).String(), Currency: currency},
+			Metadata:            metadata,
+		},
+		&types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: debit + 1},
+			RelatedOperations:   []*types.OperationIdentifier{{Index: debit}},
+			Type:                "Transfer",
+			Status:              status,
+			Account:             &types.AccountIdentifier{Address: to},
+			Amount:              &types.Amount{Value: value.String(), Currency: currency},
+			Metadata:            metadata,
+		},
+	)
+}
+
+// parseTransferOperations validates a debit/credit pair of Transfer
+// operations in the given native currency and returns the sender, recipient
+// and amount in base units.
+func parseTransferOperations(ops []*types.Operation, currency *types.Currency) (string, string, *big.Int, error) {
+	if len(ops) != 2 {
+		return "", "", nil, fmt.Errorf("expected 2 operations, got %d", len(ops))
+	}
+
+	var from, to string
+	var debit, credit *big.Int
+	for _, op := range ops {
+		if op.Type != "Transfer" {
+			return "", "", nil, fmt.Errorf("unsupported operation type %s", op.Type)
+		}
+		if op.Account == nil || !common.IsHexAddress(op.Account.Address) {
+			return "", "", nil, errors.New("operation account must be a hex address")
+		}
+		if op.Amount == nil || op.Amount.Currency == nil ||
+			op.Amount.Currency.Symbol != currency.Symbol || op.Amount.Currency.Decimals != currency.Decimals {
+			return "", "", nil, fmt.Errorf("operation amount must be denominated in %s", currency.Symbol)
+		}
+		v, ok := new(big.Int).SetString(op.Amount.Value, 10)
+		if !ok {
+			return "", "", nil, fmt.Errorf("invalid amount %q", op.Amount.Value)
+		}
+		if v.Sign() < 0 {
+			from, debit = common.HexToAddress(op.Account.Address).Hex(), new(big.Int).Neg(v)
+		} else {
+			to, credit = common.HexToAddress(op.Account.Address).Hex(), v
+		}
+	}
+	if debit == nil || credit == nil {
+		return "", "", nil, errors.New("operations must contain one debit and one credit")
+	}
+	if debit.Cmp(credit) != 0 {
+		return "", "", nil, errors.New("debit and credit amounts must match")
+	}
+	return from, to, credit, nil
+}
+
+// addressFromPublicKey derives the checksummed address of a compressed or
+// uncompressed secp256k1 public key.
+func addressFromPublicKey(b []byte) (string, error) {
+	switch len(b) {
+	case 33:
+		pk, err := crypto.DecompressPubkey(b)
+		if err != nil {
+			return "", fmt.Errorf("invalid compressed public key: %w", err)
+		}
+		return crypto.PubkeyToAddress(*pk).Hex(), nil
+	case 65:
+		pk, err := crypto.UnmarshalPubkey(b)
+		if err != nil {
+			return "", fmt.Errorf("invalid public key: %w", err)
+		}
+		return crypto.PubkeyToAddress(*pk).Hex(), nil
+	default:
+		return "", fmt.Errorf("unexpected public key length %d", len(b))
+	}
+}
+
+func decodeSignedTransaction(s string) (*ethtypes.Transaction, error) {
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("signed transaction must be 0x-prefixed hex: %w", err)
+	}
+	tx := new(ethtypes.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("unable to decode signed transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// decodeBig accepts a non-negative integer as a decimal string, 0x-prefixed
+// hex string or JSON number.
+func decodeBig(v interface{}) (*big.Int, error) {
+	switch t := v.(type) {
+	case string:
+		if strings.HasPrefix(t, "0x") {
+			return hexutil.DecodeBig(t)
+		}
+		n, ok := new(big.Int).SetString(t, 10)
+		if !ok || n.Sign() < 0 {
+			return nil, fmt.Errorf("invalid integer %q", t)
+		}
+		return n, nil
+	case float64:
+		if t < 0 || t != math.Trunc(t) || math.IsInf(t, 0) {
+			return nil, fmt.Errorf("invalid integer %v", t)
+		}
+		n, _ := big.NewFloat(t).Int(nil)
+		return n, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+func decodeUint64(v interface{}) (uint64, error) {
+	n, err := decodeBig(v)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("value %s out of range", n)
+	}
+	return n.Uint64(), nil
+}
+
+// marshalToMap converts a struct into the map form used by Rosetta options
+// and metadata fields.
+func marshalToMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func unmarshalFromMap(m map[string]interface{}, v interface{}) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStubRPC serves canned JSON-RPC results keyed by method name, to single
+// and batch requests alike, sending an *rpcError result as an error, and
+// records every raw transaction passed to eth_sendRawTransaction.
+func newStubRPC(t *testing.T, results map[string]interface{}, sent *[]string) *EthRPCClient {
+	t.Helper()
+	type request struct {
+		ID     int64             `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	answer := func(req request) map[string]interface{} {
+		if req.Method == "eth_sendRawTransaction" && sent != nil {
+			var raw string
+			require.NoError(t, json.Unmarshal(req.Params[0], &raw))
+			*sent = append(*sent, raw)
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if res, ok := results[req.Method]; ok {
+			// a func answers according to the request's parameters
+			if f, ok := res.(func(params []json.RawMessage) interface{}); ok {
+				res = f(req.Params)
+			}
+			if e, ok := res.(*rpcError); ok {
+				resp["error"] = e
+			} else {
+				resp["result"] = res
+			}
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		return resp
+	}
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			var reqs []request
+			require.NoError(t, json.Unmarshal(body, &reqs))
+			resps := make([]map[string]interface{}, 0, len(reqs))
+			// answer in reverse to exercise ID correlation
+			for i := len(reqs) - 1; i >= 0; i-- {
+				resps = append(resps, answer(reqs[i]))
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req request
+		require.NoError(t, json.Unmarshal(body, &req))
+		_ = json.NewEncoder(w).Encode(answer(req))
+	}))
+	t.Cleanup(srv.Close)
+	return NewEthRPC(srv.URL)
+}
+
+var sepolia = &types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"}
+
+// testNetworks serves Sepolia from rpc, or from mock data when rpc is nil.
+func testNetworks(t *testing.T, rpc *EthRPCClient, tokens ...Token) *Networks {
+	t.Helper()
+	registry, err := NewTokenRegistry(tokens)
+	require.NoError(t, err)
+	networks, err := NewNetworks(&Network{
+		Identifier: sepolia,
+		ChainID:    big.NewInt(11155111),
+		Currency:   ethCurrency,
+		RPC:        rpc,
+		Tokens:     registry,
+	})
+	require.NoError(t, err)
+	return networks
+}
+
+func TestConstructionFlow_RoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	to := "0x000000000000000000000000000000000000dEaD"
+
+	var sent []string
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getTransactionCount":  "0x7",
+		"eth_estimateGas":          "0x5208",
+		"eth_maxPriorityFeePerGas": "0x3b9aca00",
+		"eth_getBlockByNumber":     map[string]interface{}{"number": "0x10", "baseFeePerGas": "0x7"},
+		"eth_sendRawTransaction":   "0xabc",
+	}, &sent)
+
+	network := sepolia
+	s := NewConstructionAPIService(testNetworks(t, rpc))
+	ctx := context.Background()
+
+	derive, rErr := s.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: network,
+		PublicKey: &types.PublicKey{
+			Bytes:     crypto.CompressPubkey(&key.PublicKey),
+			CurveType: types.Secp256k1,
+		},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, from, derive.AccountIdentifier.Address)
+
+	ops := transferOperations(from, to, mustBig("1000000000000000"), ethCurrency, nil)
+
+	pre, rErr := s.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: network,
+		Operations:        ops,
+	})
+	require.Nil(t, rErr)
+	require.Len(t, pre.RequiredPublicKeys, 1)
+	assert.Equal(t, from, pre.RequiredPublicKeys[0].Address)
+
+	meta, rErr := s.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: network,
+		Options:           pre.Options,
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, "0x7", meta.Metadata["nonce"])
+	assert.Equal(t, "0x5208", meta.Metadata["gas_limit"])
+	// 2 * base fee + tip
+	assert.Equal(t, "0x3b9aca0e", meta.Metadata["max_fee_per_gas"])
+
+	payloads, rErr := s.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: network,
+		Operations:        ops,
+		Metadata:          meta.Metadata,
+	})
+	require.Nil(t, rErr)
+	require.Len(t, payloads.Payloads, 1)
+
+	parsedUnsigned, rErr := s.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: network,
+		Signed:            false,
+		Transaction:       payloads.UnsignedTransaction,
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, ops, parsedUnsigned.Operations)
+	assert.Empty(t, parsedUnsigned.AccountIdentifierSigners)
+
+	sig, err := crypto.Sign(payloads.Payloads[0].Bytes, key)
+	require.NoError(t, err)
+
+	combined, rErr := s.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   network,
+		UnsignedTransaction: payloads.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				SigningPayload: payloads.Payloads[0],
+				PublicKey:      &types.PublicKey{Bytes: crypto.CompressPubkey(&key.PublicKey), CurveType: types.Secp256k1},
+				SignatureType:  types.EcdsaRecovery,
+				Bytes:          sig,
+			},
+		},
+	})
+	require.Nil(t, rErr)
+
+	parsedSigned, rErr := s.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: network,
+		Signed:            true,
+		Transaction:       combined.SignedTransaction,
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, ops, parsedSigned.Operations)
+	require.Len(t, parsedSigned.AccountIdentifierSigners, 1)
+	assert.Equal(t, from, parsedSigned.AccountIdentifierSigners[0].Address)
+
+	hash, rErr := s.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: network,
+		SignedTransaction: combined.SignedTransaction,
+	})
+	require.Nil(t, rErr)
+	assert.Len(t, hash.TransactionIdentifier.Hash, 66)
+
+	submit, rErr := s.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: network,
+		SignedTransaction: combined.SignedTransaction,
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, "0xabc", submit.TransactionIdentifier.Hash)
+	assert.Equal(t, []string{combined.SignedTransaction}, sent)
+}
+
+func TestConstructionCombine_RejectsForeignSignature(t *testing.T) {
+	owner, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(owner.PublicKey).Hex()
+
+	network := sepolia
+	s := NewConstructionAPIService(testNetworks(t, nil))
+	ctx := context.Background()
+
+	payloads, rErr := s.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: network,
+		Operations:        transferOperations(from, "0x000000000000000000000000000000000000dEaD", mustBig("1"), ethCurrency, nil),
+		Metadata: map[string]interface{}{
+			"nonce":                    "0x0",
+			"gas_limit":                "0x5208",
+			"max_fee_per_gas":          "0x2",
+			"max_priority_fee_per_gas": "0x1",
+		},
+	})
+	require.Nil(t, rErr)
+
+	sig, err := crypto.Sign(payloads.Payloads[0].Bytes, other)
+	require.NoError(t, err)
+
+	_, rErr = s.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   network,
+		UnsignedTransaction: payloads.UnsignedTransaction,
+		Signatures:          []*types.Signature{{SignatureType: types.EcdsaRecovery, Bytes: sig}},
+	})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrInvalidRequest.Code, rErr.Code)
+}
+
+func TestConstructionMetadata_Urgency(t *testing.T) {
+	from := "0x1234567890AbcdEF1234567890aBcdef12345678"
+	to := "0x000000000000000000000000000000000000dEaD"
+	ops := transferOperations(from, to, mustBig("1000000000000000"), ethCurrency, nil)
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getTransactionCount":  "0x0",
+		"eth_estimateGas":          "0x5208",
+		"eth_maxPriorityFeePerGas": "0xa",
+		"eth_getBlockByNumber":     map[string]interface{}{"number": "0x10", "baseFeePerGas": "0x7"},
+		"eth_feeHistory": map[string]interface{}{
+			// next base fee 100 wei; nobody tips at the 10th percentile
+			"baseFeePerGas": []string{"0x50", "0x64"},
+			"reward":        [][]string{{"0x0", "0x14", "0x1e"}},
+		},
+	}, nil)
+	s := NewConstructionAPIService(testNetworks(t, rpc))
+	ctx := context.Background()
+
+	for urgency, want := range map[string][2]string{
+		// tip, max fee
+		"slow":   {"0xa", "0x87"},   // node tip, 1.25 * base + tip
+		"normal": {"0x14", "0xdc"},  // 50th percentile, 2 * base + tip
+		"fast":   {"0x1e", "0x14a"}, // 90th percentile, 3 * base + tip
+	} {
+		pre, rErr := s.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+			NetworkIdentifier: sepolia,
+			Operations:        ops,
+			Metadata:          map[string]interface{}{"urgency": urgency},
+		})
+		require.Nil(t, rErr, urgency)
+		meta, rErr := s.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{NetworkIdentifier: sepolia, Options: pre.Options})
+		require.Nil(t, rErr, urgency)
+		assert.Equal(t, want[0], meta.Metadata["max_priority_fee_per_gas"], urgency)
+		assert.Equal(t, want[1], meta.Metadata["max_fee_per_gas"], urgency)
+	}
+
+	_, rErr := s.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: sepolia,
+		Operations:        ops,
+		Metadata:          map[string]interface{}{"urgency": "asap"},
+	})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrInvalidRequest.Code, rErr.Code)
+}
+
+func TestConstructionPreprocess_FeeMetadata(t *testing.T) {
+	from := "0x1234567890AbcdEF1234567890aBcdef12345678"
+	ops := transferOperations(from, "0x000000000000000000000000000000000000dEaD", mustBig("1"), ethCurrency, nil)
+	s := NewConstructionAPIService(testNetworks(t, nil))
+	preprocess := func(fee interface{}) (*types.ConstructionPreprocessResponse, *types.Error) {
+		return s.ConstructionPreprocess(context.Background(), &types.ConstructionPreprocessRequest{
+			NetworkIdentifier: sepolia,
+			Operations:        ops,
+			Metadata:          map[string]interface{}{"max_fee_per_gas": fee},
+		})
+	}
+
+	for fee, want := range map[interface{}]string{"0x2a": "0x2a", "42": "0x2a", float64(42): "0x2a", 1e20: "0x56bc75e2d63100000"} {
+		pre, rErr := preprocess(fee)
+		require.Nil(t, rErr, fee)
+		assert.Equal(t, want, pre.Options["max_fee_per_gas"], fee)
+	}
+	for _, fee := range []interface{}{float64(-1), 1.5, "-1", "1.5", true} {
+		_, rErr := preprocess(fee)
+		require.NotNil(t, rErr, fee)
+		assert.Equal(t, ErrInvalidRequest.Code, rErr.Code, fee)
+	}
+}
+
+func TestConstructionMetadata_NoRPC(t *testing.T) {
+	s := NewConstructionAPIService(testNetworks(t, nil))
+	_, rErr := s.ConstructionMetadata(context.Background(), &types.ConstructionMetadataRequest{NetworkIdentifier: sepolia})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrNetwork.Code, rErr.Code)
+	assert.True(t, rErr.Retriable)
+}
+
+func mustBig(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid integer " + s)
+	}
+	return n
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Rosetta clients validate returned errors against the list published in
+// /network/options by code AND message, so request-specific context must go
+// into Details rather than Message. Use wrapErr to attach it.
+var (
+	ErrInvalidRequest = &types.Error{
+		Code:      1,
+		Message:   "Invalid request",
+		Retriable: false,
+	}
+	ErrNetwork = &types.Error{
+		Code:      2,
+		Message:   "Network error",
+		Retriable: true,
+	}
+	ErrBroadcastFailed = &types.Error{
+		Code:      3,
+		Message:   "Unable to broadcast transaction",
+		Retriable: false,
+	}
+	ErrTransactionNotFound = &types.Error{
+		Code:      4,
+		Message:   "Transaction not found",
+		Retriable: true,
+	}
+	ErrUnsupportedCurrency = &types.Error{
+		Code:      5,
+		Message:   "Currency not supported",
+		Retriable: false,
+	}
+	ErrBlockNotFound = &types.Error{
+		Code:      6,
+		Message:   "Block not found",
+		Retriable: true,
+	}
+	ErrCallFailed = &types.Error{
+		Code:      7,
+		Message:   "Call failed",
+		Retriable: false,
+	}
+	ErrIndexUnavailable = &types.Error{
+		Code:      8,
+		Message:   "Transaction index unavailable",
+		Retriable: false,
+	}
+	ErrCoinsUnsupported = &types.Error{
+		Code:      9,
+		Message:   "Account coins not supported",
+		Retriable: false,
+	}
+
+	// Errors is the full list advertised by /network/options.
+	Errors = []*types.Error{
+		ErrInvalidRequest,
+		ErrNetwork,
+		ErrBroadcastFailed,
+		ErrTransactionNotFound,
+		ErrUnsupportedCurrency,
+		ErrBlockNotFound,
+		ErrCallFailed,
+		ErrIndexUnavailable,
+		ErrCoinsUnsupported,
+	}
+)
+
+// wrapErr returns a copy of rErr with err's message attached as details.
+func wrapErr(rErr *types.Error, err error) *types.Error {
+	out := &types.Error{
+		Code:        rErr.Code,
+		Message:     rErr.Message,
+		Description: rErr.Description,
+		Retriable:   rErr.Retriable,
+	}
+	if err != nil {
+		out.Details = map[string]interface{}{"context": err.Error()}
+	}
+	return out
+}
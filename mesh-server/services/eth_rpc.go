@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,61 +11,183 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Default hardcoded Infura Sepolia endpoint (replace with actual project ID if no ENV is set)
-const defaultInfuraSepoliaURL = "https://sepolia.infura.io/v3/fffa2b97b0ee4febb791505fd5c067de"
-
 type rpcError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
+// Error makes errors reported by the node distinguishable, with errors.As,
+// from transport failures.
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
 type rpcRequest struct {
 	JSONRPC string      `json:"jsonrpc"`
-	ID      int         `json:"id"`
+	ID      int64       `json:"id"`
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params"`
 }
 
 type rpcResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
-	ID      int             `json:"id"`
+	ID      int64           `json:"id"`
 	Result  json.RawMessage `json:"result"`
 	Error   *rpcError       `json:"error,omitempty"`
 }
 
+// rpcCall is one request of a batch. The result is decoded into Out and a
+// failure of this request alone is reported in Err.
+type rpcCall struct {
+	Method string
+	Params interface{}
+	Out    interface{}
+	Err    error
+}
+
+const (
+	// maxBatchSize caps the requests sent in one HTTP round trip; many
+	// providers reject larger batches.
+	maxBatchSize = 100
+	// rpcWorkers bounds the concurrent HTTP requests made for one Rosetta
+	// request.
+	rpcWorkers = 4
+)
+
+// EthRPCClient talks to one or more JSON-RPC endpoints serving the same
+// chain. Requests go to the healthiest endpoint and fail over to the others;
+// see rpc_pool.go.
 type EthRPCClient struct {
-	URL        string
+	// Retries is the number of further attempts made after a retriable
+	// failure, each on the next best endpoint, waiting Backoff, then twice
+	// as long, between them.
+	Retries int
+	Backoff time.Duration
+	// MaxBlockDrift is how many blocks an endpoint may trail the highest
+	// one before it is taken out of rotation.
+	MaxBlockDrift int64
+
+	endpoints  []*rpcEndpoint
 	httpClient *http.Client
+	nextID     atomic.Int64
+	heights    heightCheck
 }
 
+// NewEthRPCFromEnv builds a client from INFURA_RPC_URL or ETH_RPC_URL, each
+// a comma-separated list of endpoints.
 func NewEthRPCFromEnv() (*EthRPCClient, error) {
-	url := os.Getenv("INFURA_RPC_URL")
-	if url == "" {
-		url = os.Getenv("ETH_RPC_URL")
+	urls := os.Getenv("INFURA_RPC_URL")
+	if urls == "" {
+		urls = os.Getenv("ETH_RPC_URL")
 	}
-	// If not provided via ENV, fall back to a hardcoded Sepolia endpoint
-	if url == "" {
-		url = defaultInfuraSepoliaURL
+	if urls == "" {
+		return nil, errors.New("INFURA_RPC_URL or ETH_RPC_URL is required")
 	}
-	return &EthRPCClient{
-		URL:        url,
-		httpClient: &http.Client{Timeout: 20 * time.Second},
-	}, nil
+	return NewEthRPC(SplitURLs(urls)...), nil
 }
 
-func (c *EthRPCClient) call(method string, params interface{}, out interface{}) error {
+// NewEthRPC returns a client for the JSON-RPC endpoints at urls, which must
+// all serve the same chain.
+func NewEthRPC(urls ...string) *EthRPCClient {
+	c := &EthRPCClient{
+		Retries:       defaultRetries,
+		Backoff:       defaultBackoff,
+		MaxBlockDrift: defaultMaxBlockDrift,
+		httpClient:    &http.Client{Timeout: 20 * time.Second},
+	}
+	for _, u := range urls {
+		c.endpoints = append(c.endpoints, newRPCEndpoint(u))
+	}
+	// the first height comparison runs one interval after start-up
+	c.heights.last.Store(time.Now().UnixNano())
+	return c
+}
+
+// SplitURLs parses a comma-separated endpoint list, dropping blanks.
+func SplitURLs(s string) []string {
+	var out []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// URLs returns the configured endpoints in configuration order.
+func (c *EthRPCClient) URLs() []string {
+	out := make([]string, len(c.endpoints))
+	for i, e := range c.endpoints {
+		out[i] = e.url
+	}
+	return out
+}
+
+func (c *EthRPCClient) call(ctx context.Context, method string, params interface{}, out interface{}) error {
 	if c == nil {
 		return errors.New("nil EthRPCClient")
 	}
-	reqBody := rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params}
-	b, err := json.Marshal(reqBody)
-	if err != nil {
+	id := c.nextID.Add(1)
+	var r rpcResponse
+	if err := c.post(ctx, rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}, &r); err != nil {
 		return err
 	}
-	httpReq, err := http.NewRequest("POST", c.URL, bytes.NewReader(b))
+	if r.ID != id {
+		return fmt.Errorf("rpc response id %d does not match request id %d", r.ID, id)
+	}
+	return r.decode(out)
+}
+
+// batch sends calls as JSON-RPC batches of at most maxBatchSize, up to
+// rpcWorkers batches at a time, and matches responses back to calls by ID.
+// The returned error covers transport failures; per-call errors are left in
+// each call's Err.
+func (c *EthRPCClient) batch(ctx context.Context, calls []*rpcCall) error {
+	if c == nil {
+		return errors.New("nil EthRPCClient")
+	}
+	chunks := (len(calls) + maxBatchSize - 1) / maxBatchSize
+	return parallel(ctx, chunks, rpcWorkers, func(ctx context.Context, i int) error {
+		end := (i + 1) * maxBatchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		chunk := calls[i*maxBatchSize : end]
+
+		reqs := make([]rpcRequest, len(chunk))
+		byID := make(map[int64]*rpcCall, len(chunk))
+		for j, call := range chunk {
+			id := c.nextID.Add(1)
+			reqs[j] = rpcRequest{JSONRPC: "2.0", ID: id, Method: call.Method, Params: call.Params}
+			byID[id] = call
+		}
+		var resps []rpcResponse
+		if err := c.post(ctx, reqs, &resps); err != nil {
+			return fmt.Errorf("rpc batch: %w", err)
+		}
+		// Responses may arrive in any order.
+		for _, r := range resps {
+			if call, ok := byID[r.ID]; ok {
+				call.Err = r.decode(call.Out)
+				delete(byID, r.ID)
+			}
+		}
+		for id, call := range byID {
+			call.Err = fmt.Errorf("rpc batch: no response to %s (id %d)", call.Method, id)
+		}
+		return nil
+	})
+}
+
+// send posts one JSON-RPC payload, a request or a batch, to url and decodes
+// the response into out.
+func (c *EthRPCClient) send(ctx context.Context, url string, body []byte, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
@@ -77,15 +200,22 @@ func (c *EthRPCClient) call(method string, params interface{}, out interface{})
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("rpc http status %d", resp.StatusCode)
+		return &httpStatusError{code: resp.StatusCode}
 	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
 
-	var r rpcResponse
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return err
-	}
+type httpStatusError struct {
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("rpc http status %d", e.code)
+}
+
+func (r *rpcResponse) decode(out interface{}) error {
 	if r.Error != nil {
-		return fmt.Errorf("rpc error %d: %s", r.Error.Code, r.Error.Message)
+		return r.Error
 	}
 	if out != nil {
 		if err := json.Unmarshal(r.Result, out); err != nil {
@@ -95,6 +225,52 @@ func (c *EthRPCClient) call(method string, params interface{}, out interface{})
 	return nil
 }
 
+// parallel runs fn for every index below n on at most workers goroutines.
+// It stops handing out work after the first error, which it returns, or once
+// ctx is done.
+func parallel(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	jobs := make(chan int)
+	if workers > n {
+		workers = n
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
 // Helpers
 func hexToInt64(h string) (int64, error) {
 	if h == "" {
@@ -134,27 +310,45 @@ func bigToStringWei(x *big.Int) string {
 // Types for decoding JSON-RPC responses
 
 type rpcBlock struct {
-	Number       string          `json:"number"`
-	Hash         string          `json:"hash"`
-	ParentHash   string          `json:"parentHash"`
-	Timestamp    string          `json:"timestamp"`
-	Transactions json.RawMessage `json:"transactions"` // can be []tx or []hash
+	Number        string          `json:"number"`
+	Hash          string          `json:"hash"`
+	ParentHash    string          `json:"parentHash"`
+	Timestamp     string          `json:"timestamp"`
+	BaseFeePerGas string          `json:"baseFeePerGas"`
+	Miner         string          `json:"miner"`
+	Difficulty    string          `json:"difficulty"`
+	Uncles        []string        `json:"uncles"`
+	Withdrawals   []rpcWithdrawal `json:"withdrawals"`
+	Transactions  json.RawMessage `json:"transactions"` // can be []tx or []hash
 }
 
 type rpcTx struct {
-	Hash     string `json:"hash"`
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Value    string `json:"value"`
-	Gas      string `json:"gas"`
-	GasPrice string `json:"gasPrice"`
-	Nonce    string `json:"nonce"`
+	Hash                 string `json:"hash"`
+	From                 string `json:"from"`
+	To                   string `json:"to"`
+	Value                string `json:"value"`
+	Gas                  string `json:"gas"`
+	GasPrice             string `json:"gasPrice"`
+	MaxFeePerGas         string `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas"`
+	Nonce                string `json:"nonce"`
+	BlockNumber          string `json:"blockNumber"` // null while pending
 }
 
 type rpcReceipt struct {
-	Status            string `json:"status"`
-	GasUsed           string `json:"gasUsed"`
-	EffectiveGasPrice string `json:"effectiveGasPrice"`
-	BlockNumber       string `json:"blockNumber"`
-	BlockHash         string `json:"blockHash"`
+	TransactionHash   string   `json:"transactionHash"`
+	Status            string   `json:"status"`
+	GasUsed           string   `json:"gasUsed"`
+	EffectiveGasPrice string   `json:"effectiveGasPrice"`
+	BlockNumber       string   `json:"blockNumber"`
+	BlockHash         string   `json:"blockHash"`
+	ContractAddress   string   `json:"contractAddress"`
+	Logs              []rpcLog `json:"logs"`
+}
+
+type rpcLog struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	LogIndex string   `json:"logIndex"`
 }
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch_CorrelatesResponses(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{"eth_chainId": "0xaa36a7", "eth_blockNumber": "0x10"}, nil)
+
+	// more than one batch, answered out of order by the stub
+	calls := make([]*rpcCall, maxBatchSize+5)
+	results := make([]string, len(calls))
+	for i := range calls {
+		method := "eth_chainId"
+		if i%2 == 1 {
+			method = "eth_blockNumber"
+		}
+		calls[i] = &rpcCall{Method: method, Params: []interface{}{}, Out: &results[i]}
+	}
+	missing := &rpcCall{Method: "eth_unknown", Params: []interface{}{}}
+	calls = append(calls, missing)
+
+	require.NoError(t, rpc.batch(context.Background(), calls))
+	for i := 0; i < maxBatchSize+5; i++ {
+		require.NoError(t, calls[i].Err)
+		want := "0xaa36a7"
+		if i%2 == 1 {
+			want = "0x10"
+		}
+		assert.Equal(t, want, results[i], "call %d", i)
+	}
+	assert.ErrorContains(t, missing.Err, "method not found")
+}
+
+func TestCall_HonoursContext(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{"eth_blockNumber": "0x10"}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out string
+	err := rpc.call(ctx, "eth_blockNumber", []interface{}{}, &out)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParallel_BoundedAndStopsOnError(t *testing.T) {
+	var running, peak atomic.Int32
+	err := parallel(context.Background(), 20, 3, func(ctx context.Context, i int) error {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+
+	boom := errors.New("boom")
+	var ran atomic.Int32
+	err = parallel(context.Background(), 1000, 2, func(ctx context.Context, i int) error {
+		ran.Add(1)
+		if i == 0 {
+			return boom
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.Less(t, ran.Load(), int32(1000))
+}
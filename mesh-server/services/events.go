@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	defaultPollInterval = 12 * time.Second
+	// maxEvents is how many events a log keeps; older ones are pruned.
+	maxEvents = 10000
+	// maxReorgDepth bounds the canonical blocks remembered to find the
+	// common ancestor after a reorg.
+	maxReorgDepth = 128
+	// maxCatchUp bounds the blocks added per poll so a poller far behind
+	// the head catches up over several polls.
+	maxCatchUp = 500
+	// defaultEventsLimit and maxEventsLimit bound one /events/blocks page.
+	defaultEventsLimit = 100
+	maxEventsLimit     = 1000
+)
+
+// BlockEventLog is an ordered log of block_added and block_removed events.
+// Sequence numbers start at 0 and never change, so consumers can resume from
+// the last sequence they processed.
+type BlockEventLog struct {
+	mu     sync.Mutex
+	first  int64 // sequence of events[0]
+	events []*types.BlockEvent
+	// chain is the canonical chain as last seen, oldest first.
+	chain []*types.BlockIdentifier
+}
+
+// tip returns the newest canonical block, or nil before the first poll.
+func (l *BlockEventLog) tip() *types.BlockIdentifier {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.chain) == 0 {
+		return nil
+	}
+	return l.chain[len(l.chain)-1]
+}
+
+// started reports whether the log has recorded an event. Unlike tip, it
+// stays true while a reorg unwinds the remembered chain.
+func (l *BlockEventLog) started() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.events) > 0
+}
+
+func (l *BlockEventLog) add(id *types.BlockIdentifier) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.chain = append(l.chain, id)
+	if len(l.chain) > maxReorgDepth {
+		l.chain = l.chain[len(l.chain)-maxReorgDepth:]
+	}
+	l.append(id, types.ADDED)
+}
+
+// removeTip drops the newest canonical block and returns it.
+func (l *BlockEventLog) removeTip() *types.BlockIdentifier {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := l.chain[len(l.chain)-1]
+	l.chain = l.chain[:len(l.chain)-1]
+	l.append(id, types.REMOVED)
+	return id
+}
+
+func (l *BlockEventLog) append(id *types.BlockIdentifier, typ types.BlockEventType) {
+	l.events = append(l.events, &types.BlockEvent{
+		Sequence:        l.first + int64(len(l.events)),
+		BlockIdentifier: id,
+		Type:            typ,
+	})
+	if len(l.events) > maxEvents {
+		drop := len(l.events) - maxEvents
+		l.events = append([]*types.BlockEvent(nil), l.events[drop:]...)
+		l.first += int64(drop)
+	}
+}
+
+// Events returns up to limit events from sequence offset on, and the highest
+// sequence recorded. Offsets past the next sequence were never issued by the
+// log and are rejected.
+func (l *BlockEventLog) Events(offset, limit int64) ([]*types.BlockEvent, int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	max := l.first + int64(len(l.events)) - 1
+	if offset < l.first {
+		return nil, max, fmt.Errorf("events before sequence %d have been pruned", l.first)
+	}
+	if offset > max+1 {
+		return nil, max, fmt.Errorf("sequence %d has not been issued; the latest is %d", offset, max)
+	}
+	out := []*types.BlockEvent{}
+	for seq := offset; seq <= max && int64(len(out)) < limit; seq++ {
+		out = append(out, l.events[seq-l.first])
+	}
+	return out, max, nil
+}
+
+// poll reads the node's head and brings the log up to it: blocks that are no
+// longer canonical are removed, newest first, back to the common ancestor,
+// then new blocks are added in order. The first poll starts the log at the
+// current head.
+func (l *BlockEventLog) poll(ctx context.Context, n *Network) error {
+	var head rpcBlock
+	if err := n.RPC.call(ctx, "eth_getBlockByNumber", []interface{}{"latest", false}, &head); err != nil {
+		return err
+	}
+	headIdx, err := hexToInt64(head.Number)
+	if err != nil {
+		return fmt.Errorf("latest block: %w", err)
+	}
+	n.Cache.observeHead(headIdx)
+
+	tip := l.tip()
+	if tip == nil {
+		l.add(&types.BlockIdentifier{Index: headIdx, Hash: head.Hash})
+		return nil
+	}
+
+	// Unwind while our tip is not the node's block at that height. Blocks
+	// above the reported head are left alone: a pooled endpoint may simply
+	// be a little behind.
+	removed := int64(-1)
+	for tip != nil && tip.Index <= headIdx {
+		var canonical rpcBlock
+		if err := n.RPC.call(ctx, "eth_getBlockByNumber", []interface{}{int64ToHex(tip.Index), false}, &canonical); err != nil {
+			return err
+		}
+		if canonical.Hash == tip.Hash {
+			break
+		}
+		removed = l.removeTip().Index
+		tip = l.tip()
+	}
+	if removed >= 0 {
+		n.Cache.reorg(removed)
+	}
+	if tip == nil {
+		// deeper than we remember: restart from the head
+		l.add(&types.BlockIdentifier{Index: headIdx, Hash: head.Hash})
+		return nil
+	}
+
+	// Add the blocks between our tip and the head.
+	end := headIdx
+	if end-tip.Index > maxCatchUp {
+		end = tip.Index + maxCatchUp
+	}
+	if end <= tip.Index {
+		return nil
+	}
+	headers := make([]rpcBlock, end-tip.Index)
+	calls := make([]*rpcCall, len(headers))
+	for i := range headers {
+		calls[i] = &rpcCall{Method: "eth_getBlockByNumber", Params: []interface{}{int64ToHex(tip.Index + 1 + int64(i)), false}, Out: &headers[i]}
+	}
+	if err := n.RPC.batch(ctx, calls); err != nil {
+		return err
+	}
+	parent := tip.Hash
+	for i, h := range headers {
+		// a missing header or a broken link means the chain moved while we
+		// read it; the next poll resolves it
+		if calls[i].Err != nil || h.Hash == "" || h.ParentHash != parent {
+			break
+		}
+		l.add(&types.BlockIdentifier{Index: tip.Index + 1 + int64(i), Hash: h.Hash})
+		parent = h.Hash
+	}
+	return nil
+}
+
+// pollEvents polls the head of n every interval until ctx is done.
+func (l *BlockEventLog) pollEvents(ctx context.Context, n *Network, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := l.poll(ctx, n); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Event poller for %s: %v", networkKey(n.Identifier), err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StartEventPollers starts a head poller for every network that reads from
+// a node. They stop when ctx is done.
+func (n *Networks) StartEventPollers(ctx context.Context) {
+	for _, net := range n.list {
+		if net.mode() == ModeMock {
+			continue
+		}
+		interval := net.PollInterval
+		if interval <= 0 {
+			interval = defaultPollInterval
+		}
+		go net.events.pollEvents(ctx, net, interval)
+	}
+}
+
+// EventsAPIService implements the Events API interface
+type EventsAPIService struct {
+	networks *Networks
+}
+
+// NewEventsAPIService creates a new EventsAPIService
+func NewEventsAPIService(networks *Networks) *EventsAPIService {
+	return &EventsAPIService{networks: networks}
+}
+
+// EventsBlocks implements the /events/blocks endpoint. Live networks serve
+// the log kept by their head poller. Mock networks, and hybrid ones whose
+// poller has not reached the node yet, serve the simulated chain, where
+// every block was added once and none removed. The log numbers its events
+// from 0 independently of the simulated chain, so once it has started a
+// hybrid network serves it for the rest of the process and never goes back
+// to simulated events.
+func (s *EventsAPIService) EventsBlocks(
+	ctx context.Context,
+	request *types.EventsBlocksRequest,
+) (*types.EventsBlocksResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	offset := int64(0)
+	if request.Offset != nil {
+		offset = *request.Offset
+	}
+	limit := int64(defaultEventsLimit)
+	if request.Limit != nil && *request.Limit > 0 {
+		limit = *request.Limit
+	}
+	if limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+
+	if n.mode() != ModeMock && (n.mode() == ModeLive || n.events.started()) {
+		if !n.events.started() {
+			return nil, wrapErr(ErrNetwork, errors.New("the head poller has not reached the node yet"))
+		}
+		events, max, err := n.events.Events(offset, limit)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidRequest, err)
+		}
+		markSource(ctx, SourceLive)
+		return &types.EventsBlocksResponse{MaxSequence: max, Events: events}, nil
+	}
+
+	sim := n.simulated()
+	max := sim.Head().BlockIdentifier.Index
+	events := []*types.BlockEvent{}
+	for seq := offset; seq <= max && int64(len(events)) < limit; seq++ {
+		b, _ := sim.Block(&types.PartialBlockIdentifier{Index: &seq})
+		events = append(events, &types.BlockEvent{Sequence: seq, BlockIdentifier: b.BlockIdentifier, Type: types.ADDED})
+	}
+	markSource(ctx, SourceMock)
+	return &types.EventsBlocksResponse{MaxSequence: max, Events: events}, nil
+}
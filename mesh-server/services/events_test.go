@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubChain is a node whose canonical chain tests can rewrite between polls.
+type stubChain struct {
+	mu     sync.Mutex
+	hashes []string // canonical hash by height
+}
+
+func (c *stubChain) set(hashes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hashes = hashes
+}
+
+func (c *stubChain) header(tag string) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index := int64(len(c.hashes) - 1)
+	if tag != "latest" {
+		index, _ = hexToInt64(tag)
+	}
+	if index < 0 || index >= int64(len(c.hashes)) {
+		return nil
+	}
+	parent := c.hashes[0]
+	if index > 0 {
+		parent = c.hashes[index-1]
+	}
+	return map[string]interface{}{"number": int64ToHex(index), "hash": c.hashes[index], "parentHash": parent}
+}
+
+func newStubChain(t *testing.T) (*stubChain, *EthRPCClient) {
+	t.Helper()
+	chain := &stubChain{}
+	type request struct {
+		ID     int64         `json:"id"`
+		Params []interface{} `json:"params"`
+	}
+	answer := func(req request) map[string]interface{} {
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": chain.header(req.Params[0].(string))}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if bytes.HasPrefix(body, []byte("[")) {
+			var reqs []request
+			require.NoError(t, json.Unmarshal(body, &reqs))
+			resps := []map[string]interface{}{}
+			for _, req := range reqs {
+				resps = append(resps, answer(req))
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req request
+		require.NoError(t, json.Unmarshal(body, &req))
+		_ = json.NewEncoder(w).Encode(answer(req))
+	}))
+	t.Cleanup(srv.Close)
+	return chain, NewEthRPC(srv.URL)
+}
+
+func eventSummary(events []*types.BlockEvent) []string {
+	out := []string{}
+	for _, e := range events {
+		out = append(out, fmt.Sprintf("%d %s %d:%s", e.Sequence, e.Type, e.BlockIdentifier.Index, e.BlockIdentifier.Hash))
+	}
+	return out
+}
+
+func TestEvents_PollerFollowsHeadAndReorgs(t *testing.T) {
+	chain, rpc := newStubChain(t)
+	networks := testNetworks(t, rpc)
+	n := networks.list[0]
+	n.Mode = ModeLive
+	n.Cache = NewBlockCache(CacheConfig{})
+	s := NewEventsAPIService(networks)
+	ctx := context.Background()
+
+	_, rErr := s.EventsBlocks(ctx, &types.EventsBlocksRequest{NetworkIdentifier: sepolia})
+	require.NotNil(t, rErr, "no events before the first poll")
+	assert.True(t, rErr.Retriable)
+
+	chain.set("0xa0", "0xa1", "0xa2")
+	require.NoError(t, n.events.poll(ctx, n))
+	chain.set("0xa0", "0xa1", "0xa2", "0xa3", "0xa4")
+	require.NoError(t, n.events.poll(ctx, n))
+	n.Cache.putBlock(cacheBlock(4, "0xa4", "0xa3"))
+
+	// blocks 3 and 4 are replaced by a longer branch
+	chain.set("0xa0", "0xa1", "0xa2", "0xb3", "0xb4", "0xb5")
+	require.NoError(t, n.events.poll(ctx, n))
+
+	resp, rErr := s.EventsBlocks(ctx, &types.EventsBlocksRequest{NetworkIdentifier: sepolia})
+	require.Nil(t, rErr)
+	assert.Equal(t, int64(7), resp.MaxSequence)
+	assert.Equal(t, []string{
+		"0 block_added 2:0xa2",
+		"1 block_added 3:0xa3",
+		"2 block_added 4:0xa4",
+		"3 block_removed 4:0xa4",
+		"4 block_removed 3:0xa3",
+		"5 block_added 3:0xb3",
+		"6 block_added 4:0xb4",
+		"7 block_added 5:0xb5",
+	}, eventSummary(resp.Events))
+
+	_, ok := n.Cache.blockByHash("0xa4")
+	assert.False(t, ok, "removed blocks leave the cache")
+
+	// resume from a sequence with a page size
+	offset, limit := int64(6), int64(1)
+	resp, rErr = s.EventsBlocks(ctx, &types.EventsBlocksRequest{NetworkIdentifier: sepolia, Offset: &offset, Limit: &limit})
+	require.Nil(t, rErr)
+	assert.Equal(t, []string{"6 block_added 4:0xb4"}, eventSummary(resp.Events))
+}
+
+func TestEvents_MockFromSimulatedChain(t *testing.T) {
+	s := NewEventsAPIService(testNetworks(t, nil))
+	limit := int64(2)
+	resp, rErr := s.EventsBlocks(context.Background(), &types.EventsBlocksRequest{NetworkIdentifier: sepolia, Limit: &limit})
+	require.Nil(t, rErr)
+	assert.Equal(t, int64(defaultSimBlocks), resp.MaxSequence)
+	require.Len(t, resp.Events, 2)
+	assert.Equal(t, types.ADDED, resp.Events[1].Type)
+	assert.Equal(t, int64(1), resp.Events[1].BlockIdentifier.Index)
+}
+
+func TestEvents_HybridSwitchesToLogOnce(t *testing.T) {
+	chain, rpc := newStubChain(t)
+	networks := testNetworks(t, rpc)
+	n := networks.list[0]
+	n.Mode = ModeHybrid
+	s := NewEventsAPIService(networks)
+	ctx := context.Background()
+	request := func(offset int64) (*types.EventsBlocksResponse, *types.Error) {
+		return s.EventsBlocks(ctx, &types.EventsBlocksRequest{NetworkIdentifier: sepolia, Offset: &offset})
+	}
+
+	// the simulated chain until the poller reaches the node
+	resp, rErr := request(0)
+	require.Nil(t, rErr)
+	assert.Equal(t, int64(defaultSimBlocks), resp.MaxSequence)
+
+	chain.set("0xa0", "0xa1")
+	require.NoError(t, n.events.poll(ctx, n))
+	resp, rErr = request(0)
+	require.Nil(t, rErr)
+	assert.Equal(t, []string{"0 block_added 1:0xa1"}, eventSummary(resp.Events))
+
+	// the next sequence is empty; simulated sequences past it were never
+	// issued by the log
+	resp, rErr = request(1)
+	require.Nil(t, rErr)
+	assert.Empty(t, resp.Events)
+	_, rErr = request(defaultSimBlocks)
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrInvalidRequest.Code, rErr.Code)
+
+	// a reorg deeper than the remembered chain empties it for a moment; the
+	// log keeps being served
+	n.events.removeTip()
+	resp, rErr = request(0)
+	require.Nil(t, rErr)
+	assert.Equal(t, []string{"0 block_added 1:0xa1", "1 block_removed 1:0xa1"}, eventSummary(resp.Events))
+}
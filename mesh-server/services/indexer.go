@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// indexBatch bounds the blocks indexed per step so a new index catches up
+// over several steps and a stopped server does not lose much work.
+const indexBatch = 100
+
+// IndexConfig enables the transaction index of a network that reads from a
+// node.
+type IndexConfig struct {
+	// Path is the directory of the LevelDB store; it is created if missing.
+	Path string `json:"path"`
+	// StartBlock is the first block indexed.
+	StartBlock int64 `json:"start_block"`
+}
+
+// Store layout. Block indexes and transaction positions are big-endian so
+// keys sort in chain order.
+//
+//	cursor                          -> indexCursor
+//	b <index>                       -> block hash
+//	t <index> <position>            -> types.BlockTransaction
+//	a <address> 0x00 <index> <pos>  -> empty, one per account a transaction touches
+var (
+	cursorKey     = []byte("cursor")
+	blockPrefix   = []byte("b")
+	txPrefix      = []byte("t")
+	accountPrefix = []byte("a")
+)
+
+// txLocationSize is the length of a block index and transaction position.
+const txLocationSize = 8 + 4
+
+// indexCursor is the next block to index and the hash its parent must have;
+// Parent is empty until the first block is indexed.
+type indexCursor struct {
+	Next   int64  `json:"next"`
+	Parent string `json:"parent"`
+}
+
+// Indexer follows a network's chain from a start block and stores, for every
+// transaction, the accounts it touches, so /search/transactions does not
+// have to scan the chain. Blocks that are reorganized away are removed from
+// the index before their replacements are added.
+type Indexer struct {
+	db     *leveldb.DB
+	start  int64
+	blocks *BlockAPIService
+
+	// stop and done are set while the indexer runs, so Close can stop it
+	// before the store closes.
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// OpenIndexer opens, or creates, the index store at cfg.Path. Indexing
+// resumes where the store left off.
+func OpenIndexer(cfg IndexConfig) (*Indexer, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("index path is required")
+	}
+	if cfg.StartBlock < 0 {
+		return nil, fmt.Errorf("invalid index start_block %d", cfg.StartBlock)
+	}
+	db, err := leveldb.OpenFile(cfg.Path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open index %s: %w", cfg.Path, err)
+	}
+	return &Indexer{db: db, start: cfg.StartBlock, blocks: &BlockAPIService{}}, nil
+}
+
+// Close stops the indexer if it runs, waiting for the step in progress, and
+// closes the store.
+func (x *Indexer) Close() error {
+	if x.stop != nil {
+		x.stop()
+		<-x.done
+		x.stop, x.done = nil, nil
+	}
+	return x.db.Close()
+}
+
+func (x *Indexer) cursor() (indexCursor, error) {
+	c := indexCursor{Next: x.start}
+	b, err := x.db.Get(cursorKey, nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	return c, json.Unmarshal(b, &c)
+}
+
+// Height returns the last indexed block, or -1 before the first.
+func (x *Indexer) Height() int64 {
+	c, err := x.cursor()
+	if err != nil || c.Parent == "" {
+		return -1
+	}
+	return c.Next - 1
+}
+
+// step indexes up to indexBatch blocks between the cursor and the node's
+// head. A block whose parent is not the last indexed block means the chain
+// reorganized: the last indexed block is removed and the step retries one
+// block lower.
+func (x *Indexer) step(ctx context.Context, n *Network) error {
+	var headHex string
+	if err := n.RPC.call(ctx, "eth_blockNumber", []interface{}{}, &headHex); err != nil {
+		return err
+	}
+	head, err := hexToInt64(headHex)
+	if err != nil {
+		return fmt.Errorf("block number: %w", err)
+	}
+	for i := 0; i < indexBatch; i++ {
+		c, err := x.cursor()
+		if err != nil {
+			return err
+		}
+		if c.Next > head {
+			return nil
+		}
+		var blk rpcBlock
+		if err := n.RPC.call(ctx, "eth_getBlockByNumber", []interface{}{int64ToHex(c.Next), true}, &blk); err != nil {
+			return err
+		}
+		if blk.Hash == "" {
+			// an endpoint behind the one that reported the head
+			return nil
+		}
+		if c.Parent != "" && !strings.EqualFold(blk.ParentHash, c.Parent) {
+			if err := x.unwind(c); err != nil {
+				return err
+			}
+			continue
+		}
+		resp, err := x.blocks.blockToRosetta(ctx, n, &blk)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", c.Next, err)
+		}
+		if err := x.put(resp.Block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// put indexes block and moves the cursor past it, in one batch.
+func (x *Indexer) put(block *types.Block) error {
+	batch := new(leveldb.Batch)
+	index := block.BlockIdentifier.Index
+	for pos, tx := range block.Transactions {
+		loc := txLocation(index, pos)
+		b, err := json.Marshal(&types.BlockTransaction{BlockIdentifier: block.BlockIdentifier, Transaction: tx})
+		if err != nil {
+			return err
+		}
+		batch.Put(append(append([]byte{}, txPrefix...), loc...), b)
+		for _, address := range transactionAccounts(tx) {
+			batch.Put(accountKey(address, loc), []byte{})
+		}
+	}
+	batch.Put(blockKey(index), []byte(block.BlockIdentifier.Hash))
+	if err := putCursor(batch, indexCursor{Next: index + 1, Parent: block.BlockIdentifier.Hash}); err != nil {
+		return err
+	}
+	return x.db.Write(batch, nil)
+}
+
+// unwind removes the last indexed block and moves the cursor back to it.
+func (x *Indexer) unwind(c indexCursor) error {
+	index := c.Next - 1
+	batch := new(leveldb.Batch)
+	it := x.db.NewIterator(util.BytesPrefix(append(append([]byte{}, txPrefix...), blockLocation(index)...)), nil)
+	for it.Next() {
+		var bt types.BlockTransaction
+		if err := json.Unmarshal(it.Value(), &bt); err != nil {
+			it.Release()
+			return err
+		}
+		loc := it.Key()[len(txPrefix):]
+		for _, address := range transactionAccounts(bt.Transaction) {
+			batch.Delete(accountKey(address, loc))
+		}
+		batch.Delete(append([]byte{}, it.Key()...))
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	batch.Delete(blockKey(index))
+	prev := indexCursor{Next: index}
+	if index > x.start {
+		parent, err := x.db.Get(blockKey(index-1), nil)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", index-1, err)
+		}
+		prev.Parent = string(parent)
+	}
+	if err := putCursor(batch, prev); err != nil {
+		return err
+	}
+	return x.db.Write(batch, nil)
+}
+
+// search walks the indexed transactions in blocks up to maxBlock, newest
+// first, with a reverse iterator over a snapshot of the store. A non-empty
+// address restricts them to transactions touching it. Each transaction is
+// decoded and passed to visit until visit returns false; the ones after
+// that are only counted, and search returns their number.
+func (x *Indexer) search(address string, maxBlock int64, visit func(*types.BlockTransaction) bool) (int64, error) {
+	if maxBlock < 0 {
+		return 0, nil
+	}
+	prefix := txPrefix
+	if address != "" {
+		prefix = accountKey(address, nil)
+	}
+	keys := util.BytesPrefix(prefix)
+	if maxBlock < math.MaxInt64 {
+		keys.Limit = append(append([]byte{}, prefix...), blockLocation(maxBlock+1)...)
+	}
+	snap, err := x.db.GetSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	defer snap.Release()
+	it := snap.NewIterator(keys, nil)
+	defer it.Release()
+
+	var rest int64
+	visiting := true
+	for ok := it.Last(); ok; ok = it.Prev() {
+		if !visiting {
+			rest++
+			continue
+		}
+		b := it.Value()
+		if address != "" {
+			key := it.Key()
+			b, err = snap.Get(append(append([]byte{}, txPrefix...), key[len(key)-txLocationSize:]...), nil)
+			if err != nil {
+				return 0, err
+			}
+		}
+		var bt types.BlockTransaction
+		if err := json.Unmarshal(b, &bt); err != nil {
+			return 0, err
+		}
+		visiting = visit(&bt)
+	}
+	return rest, it.Error()
+}
+
+// run indexes every interval until ctx is done.
+func (x *Indexer) run(ctx context.Context, n *Network, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := x.step(ctx, n); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Indexer for %s: %v", networkKey(n.Identifier), err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StartIndexers starts the indexer of every network configured with one.
+// They stop when ctx is done or Networks.Close is called.
+func (n *Networks) StartIndexers(ctx context.Context) {
+	for _, net := range n.list {
+		if net.Index == nil {
+			continue
+		}
+		interval := net.PollInterval
+		if interval <= 0 {
+			interval = defaultPollInterval
+		}
+		x := net.Index
+		runCtx, stop := context.WithCancel(ctx)
+		x.stop, x.done = stop, make(chan struct{})
+		go func(n *Network, done chan struct{}) {
+			defer close(done)
+			x.run(runCtx, n, interval)
+		}(net, x.done)
+	}
+}
+
+func putCursor(batch *leveldb.Batch, c indexCursor) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	batch.Put(cursorKey, b)
+	return nil
+}
+
+func blockLocation(index int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(index))
+	return b
+}
+
+func txLocation(index int64, pos int) []byte {
+	b := make([]byte, txLocationSize)
+	binary.BigEndian.PutUint64(b, uint64(index))
+	binary.BigEndian.PutUint32(b[8:], uint32(pos))
+	return b
+}
+
+func blockKey(index int64) []byte {
+	return append(append([]byte{}, blockPrefix...), blockLocation(index)...)
+}
+
+func accountKey(address string, loc []byte) []byte {
+	key := append(append([]byte{}, accountPrefix...), strings.ToLower(address)...)
+	key = append(key, 0)
+	return append(key, loc...)
+}
+
+// transactionAccounts returns the addresses tx's operations touch, lower
+// case and without duplicates.
+func transactionAccounts(tx *types.Transaction) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, op := range tx.Operations {
+		if op.Account == nil {
+			continue
+		}
+		address := strings.ToLower(op.Account.Address)
+		if !seen[address] {
+			seen[address] = true
+			out = append(out, address)
+		}
+	}
+	return out
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// indexedChain serves blocks by number, so tests can replace them between
+// index steps.
+type indexedChain struct {
+	mu     sync.Mutex
+	blocks []map[string]interface{}
+}
+
+func (c *indexedChain) set(blocks ...map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks = blocks
+}
+
+func (c *indexedChain) rpc(t *testing.T) *EthRPCClient {
+	lookup := func(params []json.RawMessage) interface{} {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var tag string
+		require.NoError(t, json.Unmarshal(params[0], &tag))
+		for _, b := range c.blocks {
+			if b["number"] == tag {
+				return b
+			}
+		}
+		return nil
+	}
+	head := func([]json.RawMessage) interface{} {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.blocks[len(c.blocks)-1]["number"]
+	}
+	return newStubRPC(t, map[string]interface{}{
+		"eth_blockNumber":      head,
+		"eth_getBlockByNumber": lookup,
+		"eth_getBlockReceipts": liveReceipts(),
+	}, nil)
+}
+
+func emptyBlock(number, hash, parent string) map[string]interface{} {
+	return map[string]interface{}{"number": number, "hash": hash, "parentHash": parent, "transactions": []interface{}{}}
+}
+
+func txHashes(resp *types.SearchTransactionsResponse) []string {
+	out := []string{}
+	for _, bt := range resp.Transactions {
+		out = append(out, bt.Transaction.TransactionIdentifier.Hash)
+	}
+	return out
+}
+
+func TestIndexer_FollowsChainAndUnwindsReorgs(t *testing.T) {
+	chain := &indexedChain{}
+	networks := testNetworks(t, chain.rpc(t))
+	n := networks.list[0]
+	path := filepath.Join(t.TempDir(), "index")
+	index, err := OpenIndexer(IndexConfig{Path: path, StartBlock: 16})
+	require.NoError(t, err)
+	n.Index = index
+	s := NewSearchAPIService(networks)
+	ctx := context.Background()
+	search := func(req *types.SearchTransactionsRequest) *types.SearchTransactionsResponse {
+		req.NetworkIdentifier = sepolia
+		resp, rErr := s.SearchTransactions(ctx, req)
+		require.Nil(t, rErr)
+		return resp
+	}
+
+	chain.set(liveBlock(), emptyBlock("0x11", "0xb11", "0xb10"))
+	require.NoError(t, index.step(ctx, n))
+	assert.Equal(t, int64(17), index.Height())
+
+	resp := search(&types.SearchTransactionsRequest{AccountIdentifier: &types.AccountIdentifier{Address: testRecip}})
+	assert.Equal(t, []string{"0xok"}, txHashes(resp), "the reverted and zero-value calls move nothing to the recipient")
+	assert.Equal(t, int64(1), resp.TotalCount)
+
+	// newest first, paged with an offset
+	limit := int64(2)
+	resp = search(&types.SearchTransactionsRequest{Type: strPtr("Fee"), Limit: &limit})
+	assert.Equal(t, []string{"0xcall", "0xreverted"}, txHashes(resp))
+	require.NotNil(t, resp.NextOffset)
+	resp = search(&types.SearchTransactionsRequest{Type: strPtr("Fee"), Limit: &limit, Offset: resp.NextOffset})
+	assert.Equal(t, []string{"0xok"}, txHashes(resp))
+	assert.Nil(t, resp.NextOffset)
+	assert.Equal(t, int64(3), resp.TotalCount)
+
+	// once the page is full the index counts the rest without decoding them
+	all := search(&types.SearchTransactionsRequest{})
+	decoded := 0
+	rest, err := index.search("", math.MaxInt64, func(*types.BlockTransaction) bool {
+		decoded++
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, decoded)
+	assert.Equal(t, all.TotalCount-1, rest)
+	limit = 1
+	resp = search(&types.SearchTransactionsRequest{Address: strPtr(testSender), Limit: &limit})
+	assert.Equal(t, []string{"0xcall"}, txHashes(resp))
+	assert.Equal(t, int64(3), resp.TotalCount)
+
+	failed := false
+	resp = search(&types.SearchTransactionsRequest{Address: strPtr(testSender), Success: &failed})
+	assert.Equal(t, []string{"0xreverted"}, txHashes(resp))
+
+	maxBlock := int64(15)
+	resp = search(&types.SearchTransactionsRequest{Address: strPtr(testSender), MaxBlock: &maxBlock})
+	assert.Empty(t, resp.Transactions)
+
+	// blocks 16 and 17 are replaced; the new 16 holds only a withdrawal
+	reorged := emptyBlock("0x10", "0xc10", "0xb0f")
+	reorged["withdrawals"] = []interface{}{
+		map[string]interface{}{"index": "0x1", "validatorIndex": "0x2a", "address": testRecip, "amount": "0x3b9aca00"},
+	}
+	chain.set(reorged, emptyBlock("0x11", "0xc11", "0xc10"), emptyBlock("0x12", "0xc12", "0xc11"))
+	require.NoError(t, index.step(ctx, n))
+	assert.Equal(t, int64(18), index.Height())
+
+	resp = search(&types.SearchTransactionsRequest{AccountIdentifier: &types.AccountIdentifier{Address: testRecip}})
+	assert.Equal(t, []string{"0xc10"}, txHashes(resp))
+	resp = search(&types.SearchTransactionsRequest{Address: strPtr(testSender)})
+	assert.Empty(t, resp.Transactions, "transactions of removed blocks leave the index")
+
+	// the store survives a restart and indexing resumes from it
+	require.NoError(t, index.Close())
+	index, err = OpenIndexer(IndexConfig{Path: path, StartBlock: 16})
+	require.NoError(t, err)
+	defer index.Close()
+	n.Index = index
+	assert.Equal(t, int64(18), index.Height())
+	resp = search(&types.SearchTransactionsRequest{Type: strPtr("Withdrawal")})
+	assert.Equal(t, []string{"0xc10"}, txHashes(resp))
+}
+
+func TestSearch_Filters(t *testing.T) {
+	s := NewSearchAPIService(testNetworks(t, nil))
+	ctx := context.Background()
+	sim := s.networks.list[0].simulated()
+	account := sim.Accounts()[0]
+
+	resp, rErr := s.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		NetworkIdentifier: sepolia,
+		AccountIdentifier: &types.AccountIdentifier{Address: account},
+		Currency:          ethCurrency,
+	})
+	require.Nil(t, rErr)
+	require.NotEmpty(t, resp.Transactions)
+	prev := resp.Transactions[0].BlockIdentifier.Index
+	for _, bt := range resp.Transactions {
+		assert.LessOrEqual(t, bt.BlockIdentifier.Index, prev, "newest first")
+		prev = bt.BlockIdentifier.Index
+		assert.Contains(t, transactionAccounts(bt.Transaction), account)
+	}
+
+	// "or" matches either condition
+	other := sim.Accounts()[1]
+	or := types.OR
+	either, rErr := s.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		NetworkIdentifier: sepolia,
+		Operator:          &or,
+		AccountIdentifier: &types.AccountIdentifier{Address: account},
+		Address:           &other,
+	})
+	require.Nil(t, rErr)
+	assert.Greater(t, either.TotalCount, resp.TotalCount)
+
+	_, rErr = s.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		NetworkIdentifier: sepolia,
+		CoinIdentifier:    &types.CoinIdentifier{Identifier: "0xok:0"},
+	})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrInvalidRequest.Code, rErr.Code)
+
+	// live networks without an index cannot search
+	n := s.networks.list[0]
+	n.RPC = newStubRPC(t, map[string]interface{}{}, nil)
+	n.Mode = ModeLive
+	_, rErr = s.SearchTransactions(ctx, &types.SearchTransactionsRequest{NetworkIdentifier: sepolia})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrIndexUnavailable.Code, rErr.Code)
+}
+
+func TestIndexer_CloseStopsRun(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	polled := make(chan struct{})
+	head := func([]json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		if polls++; polls == 1 {
+			close(polled)
+		}
+		return "0x10"
+	}
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return polls
+	}
+	networks := testNetworks(t, newStubRPC(t, map[string]interface{}{
+		"eth_blockNumber":      head,
+		"eth_getBlockByNumber": emptyBlock("0x10", "0xb10", "0xb0f"),
+	}, nil))
+	n := networks.list[0]
+	n.PollInterval = time.Millisecond
+	index, err := OpenIndexer(IndexConfig{Path: filepath.Join(t.TempDir(), "index"), StartBlock: 16})
+	require.NoError(t, err)
+	n.Index = index
+
+	networks.StartIndexers(context.Background())
+	<-polled
+	require.NoError(t, networks.Close())
+
+	// the indexer stopped with the store, rather than polling into a closed one
+	after := count()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, after, count())
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// MempoolAPIService implements the Mempool API interface
+type MempoolAPIService struct {
+	networks *Networks
+}
+
+// NewMempoolAPIService creates a new MempoolAPIService
+func NewMempoolAPIService(networks *Networks) *MempoolAPIService {
+	return &MempoolAPIService{networks: networks}
+}
+
+// txpoolContent is the subset of the txpool_content response we use:
+// pending transactions grouped by sender and nonce.
+type txpoolContent struct {
+	Pending map[string]map[string]rpcTx `json:"pending"`
+}
+
+// Mempool implements the /mempool endpoint
+func (s *MempoolAPIService) Mempool(
+	ctx context.Context,
+	request *types.NetworkRequest,
+) (*types.MempoolResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	if n.mode() != ModeMock {
+		hashes, err := s.pendingHashes(ctx, n)
+		if err == nil {
+			ids := make([]*types.TransactionIdentifier, 0, len(hashes))
+			for _, h := range hashes {
+				ids = append(ids, &types.TransactionIdentifier{Hash: h})
+			}
+			markSource(ctx, SourceLive)
+			return &types.MempoolResponse{TransactionIdentifiers: ids}, nil
+		}
+		if rErr := n.liveFailure(ErrNetwork, err); rErr != nil {
+			return nil, rErr
+		}
+		// hybrid: fall back to mock
+	}
+
+	// Mock mempool from the simulated chain
+	ids := []*types.TransactionIdentifier{}
+	for _, h := range n.simulated().Pending() {
+		ids = append(ids, &types.TransactionIdentifier{Hash: h})
+	}
+	markSource(ctx, SourceMock)
+	return &types.MempoolResponse{TransactionIdentifiers: ids}, nil
+}
+
+// MempoolTransaction implements the /mempool/transaction endpoint
+func (s *MempoolAPIService) MempoolTransaction(
+	ctx context.Context,
+	request *types.MempoolTransactionRequest,
+) (*types.MempoolTransactionResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	txHash := request.TransactionIdentifier.Hash
+	if txHash == "" {
+		return nil, wrapErr(ErrInvalidRequest, errors.New("transaction hash is required"))
+	}
+
+	if n.mode() != ModeMock {
+		var tx *rpcTx
+		err := n.RPC.call(ctx, "eth_getTransactionByHash", []interface{}{txHash}, &tx)
+		if err == nil {
+			// A mined transaction has left the mempool; callers should use
+			// /block/transaction instead.
+			if tx == nil || tx.Hash == "" || tx.BlockNumber != "" {
+				return nil, wrapErr(ErrTransactionNotFound, errors.New("transaction is not pending"))
+			}
+			transaction := pendingTransaction(n, tx)
+			transaction.Metadata = withSource(ctx, SourceLive, transaction.Metadata)
+			return &types.MempoolTransactionResponse{Transaction: transaction}, nil
+		}
+		if rErr := n.liveFailure(ErrNetwork, err); rErr != nil {
+			return nil, rErr
+		}
+		// hybrid: fall back to mock
+	}
+
+	// Mock pending transaction from the simulated chain
+	tx, ok := n.simulated().pendingTx(txHash)
+	if !ok {
+		return nil, wrapErr(ErrTransactionNotFound, errors.New("transaction is not pending"))
+	}
+	transaction := pendingTransaction(n, tx)
+	transaction.Metadata = withSource(ctx, SourceMock, transaction.Metadata)
+	return &types.MempoolTransactionResponse{Transaction: transaction}, nil
+}
+
+// pendingHashes lists pending transaction hashes, preferring txpool_content
+// and falling back to eth_pendingTransactions for nodes without the txpool
+// namespace. Hashes are sorted so repeated calls are stable.
+func (s *MempoolAPIService) pendingHashes(ctx context.Context, n *Network) ([]string, error) {
+	hashes := []string{}
+	var pool txpoolContent
+	if err := n.RPC.call(ctx, "txpool_content", []interface{}{}, &pool); err == nil {
+		for _, byNonce := range pool.Pending {
+			for _, tx := range byNonce {
+				hashes = append(hashes, tx.Hash)
+			}
+		}
+	} else {
+		var pending []rpcTx
+		if err := n.RPC.call(ctx, "eth_pendingTransactions", []interface{}{}, &pending); err != nil {
+			return nil, err
+		}
+		for _, tx := range pending {
+			hashes = append(hashes, tx.Hash)
+		}
+	}
+	sort.Strings(hashes)
+	return hashes, nil
+}
+
+// pendingTransaction maps a pending RPC transaction to Rosetta. Operations
+// carry no status and no Fee, as neither is known until the transaction is
+// mined; the gas parameters are reported in metadata instead.
+func pendingTransaction(n *Network, tx *rpcTx) *types.Transaction {
+	metadata := map[string]interface{}{}
+	for k, v := range map[string]string{
+		"nonce":                    tx.Nonce,
+		"gas_limit":                tx.Gas,
+		"gas_price":                tx.GasPrice,
+		"max_fee_per_gas":          tx.MaxFeePerGas,
+		"max_priority_fee_per_gas": tx.MaxPriorityFeePerGas,
+	} {
+		if v != "" {
+			metadata[k] = hexToBigIntMust(v)
+		}
+	}
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: tx.Hash},
+		Operations:            transactionOperations(tx, nil, nil, nil, n),
+		Metadata:              metadata,
+	}
+}
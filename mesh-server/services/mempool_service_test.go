@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMempool_TxpoolContent(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{
+		"txpool_content": map[string]interface{}{
+			"pending": map[string]interface{}{
+				"0xaaa": map[string]interface{}{
+					"1": map[string]interface{}{"hash": "0x02"},
+					"0": map[string]interface{}{"hash": "0x01"},
+				},
+			},
+			"queued": map[string]interface{}{},
+		},
+	}, nil)
+	s := NewMempoolAPIService(testNetworks(t, rpc))
+
+	resp, rErr := s.Mempool(context.Background(), &types.NetworkRequest{NetworkIdentifier: sepolia})
+	require.Nil(t, rErr)
+	assert.Equal(t, []*types.TransactionIdentifier{{Hash: "0x01"}, {Hash: "0x02"}}, resp.TransactionIdentifiers)
+}
+
+func TestMempool_FallsBackToPendingTransactions(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_pendingTransactions": []interface{}{map[string]interface{}{"hash": "0x03"}},
+	}, nil)
+	s := NewMempoolAPIService(testNetworks(t, rpc))
+
+	resp, rErr := s.Mempool(context.Background(), &types.NetworkRequest{NetworkIdentifier: sepolia})
+	require.Nil(t, rErr)
+	assert.Equal(t, []*types.TransactionIdentifier{{Hash: "0x03"}}, resp.TransactionIdentifiers)
+}
+
+func TestMempoolTransaction_Pending(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getTransactionByHash": map[string]interface{}{
+			"hash":        "0x01",
+			"from":        "0x1111111111111111111111111111111111111111",
+			"to":          "0x2222222222222222222222222222222222222222",
+			"value":       "0x64",
+			"gas":         "0x5208",
+			"nonce":       "0x3",
+			"blockNumber": nil,
+		},
+	}, nil)
+	s := NewMempoolAPIService(testNetworks(t, rpc))
+
+	resp, rErr := s.MempoolTransaction(context.Background(), &types.MempoolTransactionRequest{
+		NetworkIdentifier:     sepolia,
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "0x01"},
+	})
+	require.Nil(t, rErr)
+	ops := resp.Transaction.Operations
+	require.Len(t, ops, 2)
+	assert.Equal(t, "-100", ops[0].Amount.Value)
+	assert.Equal(t, "100", ops[1].Amount.Value)
+	assert.Nil(t, ops[0].Status)
+	assert.Equal(t, "3", resp.Transaction.Metadata["nonce"])
+}
+
+func TestMempoolTransaction_Mined(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getTransactionByHash": map[string]interface{}{"hash": "0x01", "blockNumber": "0x10"},
+	}, nil)
+	s := NewMempoolAPIService(testNetworks(t, rpc))
+
+	_, rErr := s.MempoolTransaction(context.Background(), &types.MempoolTransactionRequest{
+		NetworkIdentifier:     sepolia,
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "0x01"},
+	})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrTransactionNotFound.Code, rErr.Code)
+}
+
+func TestMempool_MockServesSimulatedChain(t *testing.T) {
+	networks := testNetworks(t, nil)
+	n := networks.list[0]
+	s := NewMempoolAPIService(networks)
+	ctx := context.Background()
+
+	resp, rErr := s.Mempool(ctx, &types.NetworkRequest{NetworkIdentifier: sepolia})
+	require.Nil(t, rErr)
+	require.Len(t, resp.TransactionIdentifiers, simPendingTxs)
+
+	accounts := map[string]bool{}
+	for _, a := range n.simulated().Accounts() {
+		accounts[a] = true
+	}
+	for _, id := range resp.TransactionIdentifiers {
+		hash, err := hexutil.Decode(id.Hash)
+		require.NoError(t, err, id.Hash)
+		assert.Len(t, hash, 32)
+		_, _, mined := n.simulated().Transaction(id.Hash)
+		assert.False(t, mined, "pending transactions are not in a block")
+
+		tx, rErr := s.MempoolTransaction(ctx, &types.MempoolTransactionRequest{NetworkIdentifier: sepolia, TransactionIdentifier: id})
+		require.Nil(t, rErr)
+		assert.Equal(t, SourceMock, tx.Transaction.Metadata["source"])
+		require.Len(t, tx.Transaction.Operations, 2)
+		for _, op := range tx.Transaction.Operations {
+			assert.True(t, common.IsHexAddress(op.Account.Address), op.Account.Address)
+			assert.True(t, accounts[op.Account.Address], "a simulated account")
+			assert.Nil(t, op.Status)
+		}
+		assert.Contains(t, tx.Transaction.Metadata, "nonce")
+	}
+
+	_, rErr = s.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		NetworkIdentifier:     sepolia,
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: n.simulated().Head().Transactions[0].TransactionIdentifier.Hash},
+	})
+	require.NotNil(t, rErr, "mined transactions are not pending")
+	assert.Equal(t, ErrTransactionNotFound.Code, rErr.Code)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Modes select where a network's data comes from.
+const (
+	// ModeLive serves node data only; RPC failures are returned as
+	// retriable errors.
+	ModeLive = "live"
+	// ModeMock serves mock data from the network's deterministic simulated
+	// chain and never reads from the node.
+	ModeMock = "mock"
+	// ModeHybrid serves node data and falls back to the simulated chain when
+	// the node cannot answer.
+	ModeHybrid = "hybrid"
+)
+
+// Sources reported for every response, in SourceHeader and, where the
+// response has one, a "source" metadata entry.
+const (
+	SourceLive = "live"
+	SourceMock = "mock"
+)
+
+// SourceHeader is the response header naming the source of a response.
+const SourceHeader = "X-Mesh-Source"
+
+// PeerCountHeader is the /network/status response header carrying the node's
+// peer count when the node reports only the number of its peers.
+const PeerCountHeader = "X-Mesh-Peer-Count"
+
+// mode resolves the effective mode: networks without an RPC client can only
+// serve mock data, and an unset mode means hybrid.
+func (n *Network) mode() string {
+	switch {
+	case n.RPC == nil:
+		return ModeMock
+	case n.Mode == "":
+		return ModeHybrid
+	}
+	return n.Mode
+}
+
+// liveFailure turns a failed live read into the error to return. It returns
+// nil on hybrid networks, telling the caller to serve mock data instead.
+func (n *Network) liveFailure(rErr *types.Error, err error) *types.Error {
+	if n.mode() == ModeHybrid {
+		return nil
+	}
+	return wrapErr(rErr, err)
+}
+
+type sourceKey struct{}
+
+// sourceWriter sets SourceHeader from the source recorded by the service,
+// and any other header it recorded, before the response header is written.
+type sourceWriter struct {
+	http.ResponseWriter
+	source      string
+	headers     map[string]string
+	wroteHeader bool
+}
+
+func (w *sourceWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.source != "" {
+			w.Header().Set(SourceHeader, w.source)
+		}
+		for k, v := range w.headers {
+			w.Header().Set(k, v)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sourceWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// WithSourceHeader wraps the Mesh router so responses report in SourceHeader
+// whether they were served from the node or from mock data. Responses that
+// read no chain data, such as /network/list, carry no header.
+func WithSourceHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &sourceWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), sourceKey{}, sw)))
+	})
+}
+
+// markSource records the source of the response being built for ctx.
+func markSource(ctx context.Context, source string) {
+	if sw, ok := ctx.Value(sourceKey{}).(*sourceWriter); ok {
+		sw.source = source
+	}
+}
+
+// markHeader records a header of the response being built for ctx, for
+// values the Mesh response type has no field for.
+func markHeader(ctx context.Context, key, value string) {
+	if sw, ok := ctx.Value(sourceKey{}).(*sourceWriter); ok {
+		if sw.headers == nil {
+			sw.headers = map[string]string{}
+		}
+		sw.headers[key] = value
+	}
+}
+
+// withSource marks the response source and returns metadata with a matching
+// "source" entry, allocating it if needed.
+func withSource(ctx context.Context, source string, metadata map[string]interface{}) map[string]interface{} {
+	markSource(ctx, source)
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["source"] = source
+	return metadata
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModes_RPCFailure(t *testing.T) {
+	// the stub knows no methods, so every call fails
+	rpc := newStubRPC(t, map[string]interface{}{}, nil)
+	networks := testNetworks(t, rpc)
+	s := NewBlockAPIService(networks)
+	request := &types.BlockRequest{NetworkIdentifier: sepolia, BlockIdentifier: &types.PartialBlockIdentifier{}}
+
+	networks.list[0].Mode = ModeLive
+	_, rErr := s.Block(context.Background(), request)
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrNetwork.Code, rErr.Code)
+	assert.True(t, rErr.Retriable)
+	assert.Contains(t, Errors, ErrNetwork, "live errors are advertised in /network/options")
+
+	networks.list[0].Mode = ModeHybrid
+	resp, rErr := s.Block(context.Background(), request)
+	require.Nil(t, rErr)
+	assert.Equal(t, SourceMock, resp.Block.Metadata["source"])
+}
+
+func TestModes_LiveBlockNotFound(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{"eth_getBlockByNumber": nil}, nil)
+	networks := testNetworks(t, rpc)
+	networks.list[0].Mode = ModeLive
+	s := NewAccountAPIService(networks)
+
+	idx := int64(99)
+	_, rErr := s.AccountBalance(context.Background(), &types.AccountBalanceRequest{
+		NetworkIdentifier: sepolia,
+		AccountIdentifier: &types.AccountIdentifier{Address: testSender},
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrBlockNotFound.Code, rErr.Code)
+	assert.True(t, rErr.Retriable)
+}
+
+func TestModes_MockIgnoresRPC(t *testing.T) {
+	var sent []string
+	rpc := newStubRPC(t, map[string]interface{}{"eth_blockNumber": "0x10"}, &sent)
+	networks := testNetworks(t, rpc)
+	networks.list[0].Mode = ModeMock
+
+	_, rErr := NewNetworkAPIService(networks).NetworkStatus(context.Background(), &types.NetworkRequest{NetworkIdentifier: sepolia})
+	require.Nil(t, rErr)
+	assert.Empty(t, sent)
+}
+
+func TestWithSourceHeader(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{"eth_getBlockByNumber": liveBlock(), "eth_getBlockReceipts": liveReceipts()}, nil)
+	s := NewBlockAPIService(testNetworks(t, rpc))
+	handler := WithSourceHeader(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, rErr := s.Block(r.Context(), &types.BlockRequest{NetworkIdentifier: sepolia, BlockIdentifier: &types.PartialBlockIdentifier{}})
+		require.Nil(t, rErr)
+		assert.Equal(t, SourceLive, resp.Block.Metadata["source"])
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/block", nil))
+	assert.Equal(t, SourceLive, rec.Header().Get(SourceHeader))
+}
+
+func TestLoadNetworksFromEnv_LiveModeRequiresRPC(t *testing.T) {
+	t.Setenv("MESH_NETWORKS_FILE", "")
+	t.Setenv("MESH_MODE", ModeLive)
+	for _, env := range []string{"MAINNET_RPC_URL", "SEPOLIA_RPC_URL", "HOLESKY_RPC_URL", "AMOY_RPC_URL", "INFURA_RPC_URL", "ETH_RPC_URL"} {
+		t.Setenv(env, "http://node.invalid")
+	}
+	networks, err := LoadNetworksFromEnv()
+	require.NoError(t, err)
+	mainnet, _ := networks.Lookup(&types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Mainnet"})
+	assert.Equal(t, ModeLive, mainnet.mode())
+
+	t.Setenv("AMOY_RPC_URL", "")
+	_, err = LoadNetworksFromEnv()
+	assert.ErrorContains(t, err, "live mode requires an RPC URL")
+
+	t.Setenv("MESH_MODE", "sometimes")
+	_, err = LoadNetworksFromEnv()
+	assert.ErrorContains(t, err, "unknown mode")
+}
+
+func TestModes_TraceFailure(t *testing.T) {
+	networks := testNetworks(t, nil)
+	n := networks.list[0]
+	hash := n.simulated().Head().Transactions[0].TransactionIdentifier.Hash
+	tx := liveBlock()["transactions"].([]interface{})[0].(map[string]interface{})
+	tx["hash"] = hash
+	// the node serves the transaction but not its trace
+	n.RPC = newStubRPC(t, map[string]interface{}{
+		"eth_getTransactionByHash":  tx,
+		"eth_getTransactionReceipt": liveReceipts()[0],
+		"eth_getBlockByHash":        liveBlock(),
+	}, nil)
+	n.Trace = TraceDebug
+	s := NewBlockAPIService(networks)
+	request := &types.BlockTransactionRequest{
+		NetworkIdentifier:     sepolia,
+		BlockIdentifier:       &types.BlockIdentifier{},
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+	}
+
+	n.Mode = ModeLive
+	_, rErr := s.BlockTransaction(context.Background(), request)
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrNetwork.Code, rErr.Code)
+
+	n.Mode = ModeHybrid
+	resp, rErr := s.BlockTransaction(context.Background(), request)
+	require.Nil(t, rErr)
+	assert.Equal(t, SourceMock, resp.Transaction.Metadata["source"])
+}
+
+func TestModes_BlockTraceFailure(t *testing.T) {
+	networks := testNetworks(t, nil)
+	n := networks.list[0]
+	// the node serves the block and its receipts but not its traces
+	n.RPC = newStubRPC(t, map[string]interface{}{
+		"eth_getBlockByNumber": liveBlock(),
+		"eth_getBlockReceipts": liveReceipts(),
+	}, nil)
+	n.Trace = TraceDebug
+	s := NewBlockAPIService(networks)
+	index := n.simulated().Head().BlockIdentifier.Index
+	request := &types.BlockRequest{NetworkIdentifier: sepolia, BlockIdentifier: &types.PartialBlockIdentifier{Index: &index}}
+
+	n.Mode = ModeLive
+	_, rErr := s.Block(context.Background(), request)
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrNetwork.Code, rErr.Code)
+
+	n.Mode = ModeHybrid
+	resp, rErr := s.Block(context.Background(), request)
+	require.Nil(t, rErr)
+	assert.Equal(t, SourceMock, resp.Block.Metadata["source"])
+	assert.Equal(t, n.simulated().Head().BlockIdentifier, resp.Block.BlockIdentifier)
+}
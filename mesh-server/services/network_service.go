@@ -2,34 +2,22 @@ package services
 
 import (
     "context"
-    "os"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strconv"
 
     "github.com/coinbase/rosetta-sdk-go/types"
 )
 
 // NetworkAPIService implements the Network API interface
 type NetworkAPIService struct {
-    network *types.NetworkIdentifier
-    rpc     *EthRPCClient
-    live    bool
+    networks *Networks
 }
 
 // NewNetworkAPIService creates a new NetworkAPIService
-func NewNetworkAPIService(network *types.NetworkIdentifier, rpc *EthRPCClient) *NetworkAPIService {
-    live := false
-    if rpc != nil {
-        // Enable live mode by default when RPC is provided, can be disabled with MESH_LIVE=false
-        if v := os.Getenv("MESH_LIVE"); v == "false" || v == "0" {
-            live = false
-        } else {
-            live = true
-        }
-    }
-    return &NetworkAPIService{
-        network: network,
-        rpc:     rpc,
-        live:    live,
-    }
+func NewNetworkAPIService(networks *Networks) *NetworkAPIService {
+    return &NetworkAPIService{networks: networks}
 }
 
 // NetworkList implements the /network/list endpoint
@@ -38,9 +26,7 @@ func (s *NetworkAPIService) NetworkList(
     request *types.MetadataRequest,
 ) (*types.NetworkListResponse, *types.Error) {
     return &types.NetworkListResponse{
-        NetworkIdentifiers: []*types.NetworkIdentifier{
-            s.network,
-        },
+        NetworkIdentifiers: s.networks.Identifiers(),
     }, nil
 }
 
@@ -49,10 +35,29 @@ func (s *NetworkAPIService) NetworkOptions(
     ctx context.Context,
     request *types.NetworkRequest,
 ) (*types.NetworkOptionsResponse, *types.Error) {
+    n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+    if rErr != nil {
+        return nil, rErr
+    }
+
+    // Mock data keeps every balance; a node only does when it is an archive
+    // node. Hybrid networks fall back to mock data when the node cannot be
+    // probed.
+    historical := true
+    if n.mode() != ModeMock {
+        archive, err := n.state.historical(ctx, n)
+        switch {
+        case err == nil:
+            historical = archive
+        case n.mode() == ModeLive:
+            historical = false
+        }
+    }
     return &types.NetworkOptionsResponse{
         Version: &types.Version{
             RosettaVersion: "1.5.1",
             NodeVersion:    "1.0.0",
+            Metadata:       map[string]interface{}{"mode": n.mode(), "model": n.model()},
         },
         Allow: &types.Allow{
             OperationStatuses: []*types.OperationStatus{
@@ -65,25 +70,10 @@ func (s *NetworkAPIService) NetworkOptions(
                     Successful: false,
                 },
             },
-            OperationTypes: []string{
-                "Transfer",
-                "Reward",
-                "Fee",
-            },
-            Errors: []*types.Error{
-                {
-                    Code:      1,
-                    Message:   "Invalid request",
-                    Retriable: false,
-                },
-                {
-                    Code:      2,
-                    Message:   "Network error",
-                    Retriable: true,
-                },
-            },
-            HistoricalBalanceLookup: true,
-            CallMethods:             []string{},
+            OperationTypes: OperationTypes,
+            Errors:                  Errors,
+            HistoricalBalanceLookup: historical,
+            CallMethods:             CallMethods,
             BalanceExemptions:       []*types.BalanceExemption{},
             MempoolCoins:            false,
         },
@@ -95,95 +85,190 @@ func (s *NetworkAPIService) NetworkStatus(
     ctx context.Context,
     request *types.NetworkRequest,
 ) (*types.NetworkStatusResponse, *types.Error) {
-    // Live path when RPC is available and live mode enabled
-    if s.rpc != nil && s.live {
-        // Get latest block number
-        var numHex string
-        if err := s.rpc.call("eth_blockNumber", []interface{}{}, &numHex); err == nil {
-            if currentIndex, err := hexToInt64(numHex); err == nil {
-                // Fetch current block (hash + timestamp)
-                var blk rpcBlock
-                _ = s.rpc.call("eth_getBlockByNumber", []interface{}{int64ToHex(currentIndex), false}, &blk)
-                // Fetch genesis
-                var genesis rpcBlock
-                _ = s.rpc.call("eth_getBlockByNumber", []interface{}{int64ToHex(0), false}, &genesis)
-
-                // Build identifiers
-                currentHash := blk.Hash
-                if currentHash == "" {
-                    currentHash = ""
-                }
-                genesisHash := genesis.Hash
-
-                // Timestamp
-                ts := int64(0)
-                if blk.Timestamp != "" {
-                    if v, err := hexToInt64(blk.Timestamp); err == nil {
-                        ts = v * 1000 // seconds -> ms
-                    }
-                }
-
-                current := &types.BlockIdentifier{Index: currentIndex, Hash: currentHash}
-                g := &types.BlockIdentifier{Index: 0, Hash: genesisHash}
-
-                stage := "synced"
-                synced := true
-                return &types.NetworkStatusResponse{
-                    CurrentBlockIdentifier: current,
-                    CurrentBlockTimestamp:  ts,
-                    GenesisBlockIdentifier: g,
-                    OldestBlockIdentifier:  g,
-                    SyncStatus: &types.SyncStatus{
-                        CurrentIndex: &currentIndex,
-                        TargetIndex:  &currentIndex,
-                        Stage:        &stage,
-                        Synced:       &synced,
-                    },
-                    Peers: []*types.Peer{},
-                }, nil
-            }
-        }
-        // If live fails for any reason, fall back to mock below
+    n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+    if rErr != nil {
+        return nil, rErr
     }
 
-    // Mock fallback
-    // Mock current block
-    currentBlock := &types.BlockIdentifier{
-        Index: 1000000,
-        Hash:  "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
-    }
-
-    // Mock genesis block
-    genesisBlock := &types.BlockIdentifier{
-        Index: 0,
-        Hash:  "0x0000000000000000000000000000000000000000000000000000000000000000",
-    }
-
-    // Mock peers
-    peers := []*types.Peer{
-        {
-            PeerID:   "peer1",
-            Metadata: map[string]interface{}{"address": "127.0.0.1:8080"},
-        },
-        {
-            PeerID:   "peer2",
-            Metadata: map[string]interface{}{"address": "127.0.0.1:8081"},
-        },
+    // Live path unless the network serves mock data only
+    if n.mode() != ModeMock {
+        status, err := s.liveStatus(ctx, n)
+        if err == nil {
+            markSource(ctx, SourceLive)
+            return status, nil
+        }
+        if rErr := n.liveFailure(ErrNetwork, err); rErr != nil {
+            return nil, rErr
+        }
+        // hybrid: fall back to mock below
     }
 
+    // Mock status from the simulated chain
+    sim := n.simulated()
+    head := sim.Head()
+    headIndex := head.BlockIdentifier.Index
     stage := "synced"
     synced := true
+    markSource(ctx, SourceMock)
     return &types.NetworkStatusResponse{
-        CurrentBlockIdentifier: currentBlock,
-        CurrentBlockTimestamp:  1640995200000, // Mock timestamp
-        GenesisBlockIdentifier: genesisBlock,
-        OldestBlockIdentifier:  genesisBlock,
+        CurrentBlockIdentifier: head.BlockIdentifier,
+        CurrentBlockTimestamp:  head.Timestamp,
+        GenesisBlockIdentifier: sim.Genesis(),
+        OldestBlockIdentifier:  sim.Genesis(),
         SyncStatus: &types.SyncStatus{
-            CurrentIndex: &currentBlock.Index,
-            TargetIndex:  &currentBlock.Index,
+            CurrentIndex: &headIndex,
+            TargetIndex:  &headIndex,
             Stage:        &stage,
             Synced:       &synced,
         },
-        Peers: peers,
+        Peers: []*types.Peer{},
     }, nil
-}
\ No newline at end of file
+}
+
+// liveStatus reads the head and genesis blocks, the sync progress and the
+// peers from the node. Sync progress and peers are optional: nodes that do
+// not serve eth_syncing, admin_peers or net_peerCount report less. The
+// oldest block is where a pruned node's state starts; it is omitted when the
+// node cannot be probed.
+func (s *NetworkAPIService) liveStatus(ctx context.Context, n *Network) (*types.NetworkStatusResponse, error) {
+    // Fetch everything in one round trip
+    var blk, genesis rpcBlock
+    var syncing json.RawMessage
+    var peers []rpcPeer
+    var peerCount string
+    calls := []*rpcCall{
+        {Method: "eth_getBlockByNumber", Params: []interface{}{"latest", false}, Out: &blk},
+        {Method: "eth_getBlockByNumber", Params: []interface{}{int64ToHex(0), false}, Out: &genesis},
+        {Method: "eth_syncing", Params: []interface{}{}, Out: &syncing},
+        {Method: "admin_peers", Params: []interface{}{}, Out: &peers},
+        {Method: "net_peerCount", Params: []interface{}{}, Out: &peerCount},
+    }
+    if err := n.RPC.batch(ctx, calls); err != nil {
+        return nil, err
+    }
+    for _, call := range calls[:2] {
+        if call.Err != nil {
+            return nil, call.Err
+        }
+    }
+    currentIndex, err := hexToInt64(blk.Number)
+    if err != nil {
+        return nil, fmt.Errorf("latest block: %w", err)
+    }
+    n.Cache.observeHead(currentIndex)
+
+    // Timestamp
+    ts := int64(0)
+    if blk.Timestamp != "" {
+        if v, err := hexToInt64(blk.Timestamp); err == nil {
+            ts = v * 1000 // seconds -> ms
+        }
+    }
+
+    current := &types.BlockIdentifier{Index: currentIndex, Hash: blk.Hash}
+    g := &types.BlockIdentifier{Index: 0, Hash: genesis.Hash}
+
+    status := &types.NetworkStatusResponse{
+        CurrentBlockIdentifier: current,
+        CurrentBlockTimestamp:  ts,
+        GenesisBlockIdentifier: g,
+        SyncStatus:             &types.SyncStatus{CurrentIndex: &currentIndex},
+        Peers:                  []*types.Peer{},
+    }
+    if calls[2].Err == nil {
+        if status.SyncStatus, err = syncStatus(syncing, currentIndex); err != nil {
+            return nil, fmt.Errorf("eth_syncing: %w", err)
+        }
+    }
+    switch {
+    case calls[3].Err == nil:
+        for _, p := range peers {
+            status.Peers = append(status.Peers, &types.Peer{
+                PeerID:   p.ID,
+                Metadata: map[string]interface{}{"name": p.Name, "remote_address": p.Network.RemoteAddress},
+            })
+        }
+    case calls[4].Err == nil:
+        // Only the number of peers is known: no peer is made up, and the
+        // count goes out in PeerCountHeader.
+        count, err := hexToInt64(peerCount)
+        if err != nil {
+            return nil, fmt.Errorf("net_peerCount: %w", err)
+        }
+        markHeader(ctx, PeerCountHeader, strconv.FormatInt(count, 10))
+    }
+
+    // Oldest block with state
+    oldest, err := n.state.oldest(ctx, n, currentIndex)
+    switch {
+    case err != nil && ctx.Err() != nil:
+        return nil, err
+    case err != nil:
+        // the node cannot be probed; the oldest block is unknown
+    case oldest == 0:
+        status.OldestBlockIdentifier = g
+    default:
+        var ob rpcBlock
+        if err := n.RPC.call(ctx, "eth_getBlockByNumber", []interface{}{int64ToHex(oldest), false}, &ob); err != nil {
+            return nil, fmt.Errorf("oldest block: %w", err)
+        }
+        status.OldestBlockIdentifier = &types.BlockIdentifier{Index: oldest, Hash: ob.Hash}
+    }
+    return status, nil
+}
+
+// rpcPeer is the part of an admin_peers entry reported as a peer.
+type rpcPeer struct {
+    ID      string `json:"id"`
+    Name    string `json:"name"`
+    Network struct {
+        RemoteAddress string `json:"remoteAddress"`
+    } `json:"network"`
+}
+
+// rpcSyncProgress is an eth_syncing result while the node syncs. Erigon adds
+// the progress of each of its stages, in the order they run.
+type rpcSyncProgress struct {
+    CurrentBlock string `json:"currentBlock"`
+    HighestBlock string `json:"highestBlock"`
+    Stages       []struct {
+        Name        string `json:"stage_name"`
+        BlockNumber string `json:"block_number"`
+    } `json:"stages"`
+}
+
+// syncStatus converts an eth_syncing result: false once the node is synced
+// to head, or its progress otherwise. The stage is "synced", "syncing" or,
+// on Erigon, the first stage that has not reached the highest block.
+func syncStatus(raw json.RawMessage, head int64) (*types.SyncStatus, error) {
+    var done bool
+    if err := json.Unmarshal(raw, &done); err == nil {
+        if done {
+            return nil, errors.New("unexpected true")
+        }
+        stage := "synced"
+        synced := true
+        return &types.SyncStatus{CurrentIndex: &head, TargetIndex: &head, Stage: &stage, Synced: &synced}, nil
+    }
+    var p rpcSyncProgress
+    if err := json.Unmarshal(raw, &p); err != nil {
+        return nil, err
+    }
+    current, err := hexToInt64(p.CurrentBlock)
+    if err != nil {
+        return nil, fmt.Errorf("currentBlock: %w", err)
+    }
+    target, err := hexToInt64(p.HighestBlock)
+    if err != nil {
+        return nil, fmt.Errorf("highestBlock: %w", err)
+    }
+    stage := "syncing"
+    for _, st := range p.Stages {
+        if reached, err := hexToInt64(st.BlockNumber); err == nil && reached < target {
+            stage = st.Name
+            break
+        }
+    }
+    synced := false
+    return &types.SyncStatus{CurrentIndex: &current, TargetIndex: &target, Stage: &stage, Synced: &synced}, nil
+}
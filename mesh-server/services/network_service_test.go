@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blocksByNumber answers eth_getBlockByNumber with a block of hash "0xb"
+// followed by the requested number, or "latest" for head.
+func blocksByNumber(t *testing.T, head string) func([]json.RawMessage) interface{} {
+	return func(params []json.RawMessage) interface{} {
+		var tag string
+		require.NoError(t, json.Unmarshal(params[0], &tag))
+		if tag == "latest" {
+			tag = head
+		}
+		return map[string]interface{}{"number": tag, "hash": "0xb" + tag[2:], "timestamp": "0x64"}
+	}
+}
+
+// stateFrom answers eth_getBalance like a node that pruned the state of
+// blocks below oldest.
+func stateFrom(t *testing.T, oldest int64) func([]json.RawMessage) interface{} {
+	return func(params []json.RawMessage) interface{} {
+		var tag string
+		require.NoError(t, json.Unmarshal(params[1], &tag))
+		index, err := hexToInt64(tag)
+		require.NoError(t, err)
+		if index < oldest {
+			return &rpcError{Code: -32000, Message: "missing trie node"}
+		}
+		return "0x0"
+	}
+}
+
+func TestNetworkStatus_Live(t *testing.T) {
+	ctx := context.Background()
+	request := &types.NetworkRequest{NetworkIdentifier: sepolia}
+
+	t.Run("synced archive node", func(t *testing.T) {
+		networks := testNetworks(t, newStubRPC(t, map[string]interface{}{
+			"eth_blockNumber":      "0x40",
+			"eth_getBlockByNumber": blocksByNumber(t, "0x40"),
+			"eth_syncing":          false,
+			"eth_getBalance":       stateFrom(t, 0),
+			"admin_peers": []interface{}{
+				map[string]interface{}{"id": "a1", "name": "Geth/v1.13", "network": map[string]interface{}{"remoteAddress": "10.0.0.1:30303"}},
+			},
+			"net_peerCount": "0x5",
+		}, nil))
+		networks.list[0].Mode = ModeLive
+		s := NewNetworkAPIService(networks)
+
+		status, rErr := s.NetworkStatus(ctx, request)
+		require.Nil(t, rErr)
+		assert.Equal(t, &types.BlockIdentifier{Index: 64, Hash: "0xb40"}, status.CurrentBlockIdentifier)
+		assert.Equal(t, int64(64), *status.SyncStatus.TargetIndex)
+		assert.Equal(t, "synced", *status.SyncStatus.Stage)
+		assert.True(t, *status.SyncStatus.Synced)
+		assert.Equal(t, status.GenesisBlockIdentifier, status.OldestBlockIdentifier)
+		require.Len(t, status.Peers, 1, "admin_peers is preferred over net_peerCount")
+		assert.Equal(t, "a1", status.Peers[0].PeerID)
+		assert.Equal(t, "10.0.0.1:30303", status.Peers[0].Metadata["remote_address"])
+
+		options, rErr := s.NetworkOptions(ctx, request)
+		require.Nil(t, rErr)
+		assert.True(t, options.Allow.HistoricalBalanceLookup)
+	})
+
+	t.Run("syncing pruned node", func(t *testing.T) {
+		networks := testNetworks(t, newStubRPC(t, map[string]interface{}{
+			"eth_blockNumber":      "0x40",
+			"eth_getBlockByNumber": blocksByNumber(t, "0x40"),
+			"eth_syncing": map[string]interface{}{
+				"currentBlock": "0x40",
+				"highestBlock": "0x80",
+				"stages": []interface{}{
+					map[string]interface{}{"stage_name": "Headers", "block_number": "0x80"},
+					map[string]interface{}{"stage_name": "Execution", "block_number": "0x40"},
+					map[string]interface{}{"stage_name": "Finish", "block_number": "0x40"},
+				},
+			},
+			"eth_getBalance": stateFrom(t, 0x32),
+			"net_peerCount":  "0x2",
+		}, nil))
+		networks.list[0].Mode = ModeLive
+		s := NewNetworkAPIService(networks)
+
+		rec := httptest.NewRecorder()
+		var status *types.NetworkStatusResponse
+		WithSourceHeader(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var rErr *types.Error
+			status, rErr = s.NetworkStatus(r.Context(), request)
+			require.Nil(t, rErr)
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/network/status", nil))
+		assert.Equal(t, int64(64), *status.SyncStatus.CurrentIndex)
+		assert.Equal(t, int64(128), *status.SyncStatus.TargetIndex)
+		assert.Equal(t, "Execution", *status.SyncStatus.Stage)
+		assert.False(t, *status.SyncStatus.Synced)
+		assert.Equal(t, &types.BlockIdentifier{Index: 50, Hash: "0xb32"}, status.OldestBlockIdentifier)
+		assert.Empty(t, status.Peers, "net_peerCount names no peers")
+		assert.Equal(t, "2", rec.Header().Get(PeerCountHeader))
+
+		options, rErr := s.NetworkOptions(ctx, request)
+		require.Nil(t, rErr)
+		assert.False(t, options.Allow.HistoricalBalanceLookup)
+	})
+
+	t.Run("minimal node", func(t *testing.T) {
+		// no eth_syncing, peers or eth_getBalance
+		networks := testNetworks(t, newStubRPC(t, map[string]interface{}{
+			"eth_blockNumber":      "0x40",
+			"eth_getBlockByNumber": blocksByNumber(t, "0x40"),
+		}, nil))
+		n := networks.list[0]
+		n.Mode = ModeLive
+		s := NewNetworkAPIService(networks)
+
+		status, rErr := s.NetworkStatus(ctx, request)
+		require.Nil(t, rErr)
+		assert.Equal(t, int64(64), *status.SyncStatus.CurrentIndex)
+		assert.Nil(t, status.SyncStatus.Synced, "sync progress is unknown")
+		assert.Nil(t, status.OldestBlockIdentifier, "pruning is unknown")
+		assert.Empty(t, status.Peers)
+
+		options, rErr := s.NetworkOptions(ctx, request)
+		require.Nil(t, rErr)
+		assert.False(t, options.Allow.HistoricalBalanceLookup)
+		n.Mode = ModeHybrid
+		options, rErr = s.NetworkOptions(ctx, request)
+		require.Nil(t, rErr)
+		assert.True(t, options.Allow.HistoricalBalanceLookup, "hybrid networks fall back to mock history")
+	})
+}
+
+func TestStateProbe_ReusesWindow(t *testing.T) {
+	probes := 0
+	networks := testNetworks(t, newStubRPC(t, map[string]interface{}{
+		"eth_getBalance": func(params []json.RawMessage) interface{} {
+			probes++
+			return stateFrom(t, 100)(params)
+		},
+	}, nil))
+	n := networks.list[0]
+	ctx := context.Background()
+
+	oldest, err := n.state.oldest(ctx, n, 228)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), oldest)
+	assert.LessOrEqual(t, probes, 10, "binary search")
+
+	// later heads keep the same window without probing again
+	before := probes
+	oldest, err = n.state.oldest(ctx, n, 300)
+	require.NoError(t, err)
+	assert.Equal(t, int64(172), oldest)
+	assert.Equal(t, before, probes)
+}
+
+func TestStateProbe_ProbesWithoutLock(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	networks := testNetworks(t, newStubRPC(t, map[string]interface{}{
+		"eth_getBalance": func(params []json.RawMessage) interface{} {
+			once.Do(func() {
+				close(started)
+				<-release
+			})
+			return stateFrom(t, 100)(params)
+		},
+	}, nil))
+	n := networks.list[0]
+
+	done := make(chan error)
+	go func() {
+		_, err := n.state.oldest(context.Background(), n, 228)
+		done <- err
+	}()
+	<-started
+	// the slow probe does not hold the lock
+	locked := n.state.mu.TryLock()
+	if locked {
+		n.state.mu.Unlock()
+	}
+	close(release)
+	require.NoError(t, <-done)
+	assert.True(t, locked, "the lock is free while the node is probed")
+
+	oldest, err := n.state.oldest(context.Background(), n, 228)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), oldest)
+}
+
+func TestStateProbe_AbortsOnOtherErrors(t *testing.T) {
+	networks := testNetworks(t, newStubRPC(t, map[string]interface{}{
+		"eth_getBalance": func(params []json.RawMessage) interface{} {
+			var tag string
+			require.NoError(t, json.Unmarshal(params[1], &tag))
+			if tag == "0x0" {
+				return &rpcError{Code: -32000, Message: "missing trie node 0xabc (path )"}
+			}
+			return &rpcError{Code: -32005, Message: "limit exceeded"}
+		},
+	}, nil))
+	n := networks.list[0]
+
+	_, err := n.state.oldest(context.Background(), n, 228)
+	require.Error(t, err, "a rate limit is not pruned state")
+	assert.Contains(t, err.Error(), "limit exceeded")
+	assert.True(t, n.state.checked.IsZero(), "a failed probe is not cached")
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// OperationTypes lists every operation type the services emit. It is shared
+// by the asserter and /network/options so the two cannot drift apart.
+var OperationTypes = []string{"Transfer", "Reward", "Fee", "Withdrawal"}
+
+// Models describe how a network tracks value.
+const (
+	// ModelAccount networks keep a balance per account; /account/coins is
+	// not served.
+	ModelAccount = "account"
+	// ModelUTXO networks track value as coins, served by /account/coins.
+	// Ethereum JSON-RPC nodes are account-based, so UTXO networks are
+	// served from the simulated chain only.
+	ModelUTXO = "utxo"
+)
+
+// NetworkConfig is the JSON form of a network entry in MESH_NETWORKS_FILE.
+type NetworkConfig struct {
+	Blockchain string `json:"blockchain"`
+	Network    string `json:"network"`
+	ChainID    int64  `json:"chain_id"`
+	// RPCURL and RPCURLs are used as-is; RPCURLEnv names an environment
+	// variable holding a comma-separated URL list so provider keys can stay
+	// out of the config file. All of them are pooled; see EthRPCClient.
+	RPCURL    string          `json:"rpc_url,omitempty"`
+	RPCURLs   []string        `json:"rpc_urls,omitempty"`
+	RPCURLEnv string          `json:"rpc_url_env,omitempty"`
+	Currency  *types.Currency `json:"currency"`
+	Tokens    []Token         `json:"tokens,omitempty"`
+	// Trace is "debug", "parity" or empty; see the Trace* constants.
+	// Entries without one use MESH_TRACE.
+	Trace string `json:"trace,omitempty"`
+	// Rewards is the proof-of-work block reward schedule. Networks that
+	// were never mined, or whose blocks carry no protocol reward, omit it.
+	Rewards []RewardEra `json:"rewards,omitempty"`
+	// Mode is "live", "mock" or "hybrid"; see the Mode* constants. Entries
+	// without one use MESH_MODE.
+	Mode string `json:"mode,omitempty"`
+	// Sim configures the simulated chain that mock data is served from.
+	Sim *SimConfig `json:"sim,omitempty"`
+	// MaxBlockDrift overrides how far an endpoint may trail the others
+	// before it is taken out of rotation.
+	MaxBlockDrift int64 `json:"max_block_drift,omitempty"`
+	// Cache sizes the block cache of live networks; nil selects the
+	// defaults.
+	Cache *CacheConfig `json:"cache,omitempty"`
+	// PollInterval is how often the head is polled for /events/blocks, as
+	// a Go duration such as "4s"; the default suits 12-second blocks.
+	PollInterval string `json:"poll_interval,omitempty"`
+	// Index enables the transaction index behind /search/transactions; it
+	// requires an RPC URL.
+	Index *IndexConfig `json:"index,omitempty"`
+	// Model is "account" (the default) or "utxo"; see the Model* constants.
+	Model string `json:"model,omitempty"`
+}
+
+// defaultNetworkConfigs is served when MESH_NETWORKS_FILE is not set.
+var defaultNetworkConfigs = []NetworkConfig{
+	{Blockchain: "Ethereum", Network: "Mainnet", ChainID: 1, RPCURLEnv: "MAINNET_RPC_URL", Currency: ethCurrency, Rewards: mainnetRewards},
+	{Blockchain: "Ethereum", Network: "Sepolia", ChainID: 11155111, RPCURLEnv: "SEPOLIA_RPC_URL", Currency: ethCurrency, Rewards: sepoliaRewards},
+	{Blockchain: "Ethereum", Network: "Holesky", ChainID: 17000, RPCURLEnv: "HOLESKY_RPC_URL", Currency: ethCurrency},
+	{Blockchain: "Polygon", Network: "Amoy", ChainID: 80002, RPCURLEnv: "AMOY_RPC_URL", Currency: &types.Currency{Symbol: "POL", Decimals: 18}},
+}
+
+// Network is a served network together with the node that backs it. A nil
+// RPC means the network only serves mock data whatever its Mode.
+type Network struct {
+	Identifier *types.NetworkIdentifier
+	ChainID    *big.Int
+	Currency   *types.Currency
+	RPC        *EthRPCClient
+	Tokens     *TokenRegistry
+	// Mode selects live, mock or hybrid data; empty means hybrid.
+	Mode string
+	// Model is ModelAccount or ModelUTXO; empty means account.
+	Model string
+	// Trace selects how internal transfers are traced; TraceOff skips them.
+	Trace string
+	// Sim configures the simulated chain behind mock data; nil selects the
+	// defaults, seeded with the chain ID.
+	Sim *SimConfig
+	// Cache holds parsed blocks read from RPC; nil disables caching.
+	Cache *BlockCache
+	// PollInterval is how often the head poller behind /events/blocks
+	// runs; zero selects the default.
+	PollInterval time.Duration
+	// Index serves /search/transactions; nil means mock data is searched,
+	// or, on live networks, searches are rejected.
+	Index *Indexer
+
+	rewards  []rewardEra
+	simOnce  sync.Once
+	simChain *SimChain
+	events   BlockEventLog
+	state    stateProbe
+}
+
+// Networks is the set of networks served by the Mesh API, keyed by
+// NetworkIdentifier.
+type Networks struct {
+	list []*Network
+	byID map[string]*Network
+}
+
+func networkKey(id *types.NetworkIdentifier) string {
+	return id.Blockchain + "/" + id.Network
+}
+
+// NewNetworks indexes the given networks, rejecting duplicates.
+func NewNetworks(list ...*Network) (*Networks, error) {
+	if len(list) == 0 {
+		return nil, errors.New("at least one network is required")
+	}
+	n := &Networks{byID: map[string]*Network{}}
+	for _, net := range list {
+		key := networkKey(net.Identifier)
+		if _, dup := n.byID[key]; dup {
+			return nil, fmt.Errorf("duplicate network %s", key)
+		}
+		n.byID[key] = net
+		n.list = append(n.list, net)
+	}
+	return n, nil
+}
+
+// LoadNetworksFromEnv builds the served networks from MESH_NETWORKS_FILE, or
+// from defaultNetworkConfigs when it is unset. Networks whose RPC URL cannot
+// be resolved are still served, from mock data, unless they are in live mode.
+// The caller closes the returned networks to release their index stores.
+func LoadNetworksFromEnv() (networks *Networks, err error) {
+	configs := defaultNetworkConfigs
+	fromFile := false
+	if path := os.Getenv("MESH_NETWORKS_FILE"); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read network config: %w", err)
+		}
+		// decode into a fresh slice: decoding into defaultNetworkConfigs
+		// would overwrite its entries in place
+		configs = nil
+		if err := json.Unmarshal(b, &configs); err != nil {
+			return nil, fmt.Errorf("parse network config: %w", err)
+		}
+		fromFile = true
+	}
+
+	defaultMode := os.Getenv("MESH_MODE")
+	if defaultMode == "" {
+		defaultMode = ModeHybrid
+		// MESH_LIVE=false predates MESH_MODE and still forces mock data.
+		if os.Getenv("MESH_LIVE") == "false" || os.Getenv("MESH_LIVE") == "0" {
+			defaultMode = ModeMock
+		}
+	}
+	list := make([]*Network, 0, len(configs))
+	defer func() {
+		// an index store is locked while open; release the ones opened
+		// before the failure
+		if err != nil {
+			(&Networks{list: list}).Close()
+		}
+	}()
+	for _, cfg := range configs {
+		if cfg.Blockchain == "" || cfg.Network == "" || cfg.ChainID <= 0 || cfg.Currency == nil {
+			return nil, fmt.Errorf("network %s/%s: blockchain, network, chain_id and currency are required", cfg.Blockchain, cfg.Network)
+		}
+		net := &Network{
+			Identifier: &types.NetworkIdentifier{Blockchain: cfg.Blockchain, Network: cfg.Network},
+			ChainID:    big.NewInt(cfg.ChainID),
+			Currency:   cfg.Currency,
+			Trace:      cfg.Trace,
+			Mode:       cfg.Mode,
+			Model:      cfg.Model,
+			Sim:        cfg.Sim,
+		}
+		list = append(list, net)
+		if net.Mode == "" {
+			net.Mode = defaultMode
+		}
+		switch net.Mode {
+		case ModeLive, ModeMock, ModeHybrid:
+		default:
+			return nil, fmt.Errorf("network %s: unknown mode %q", networkKey(net.Identifier), net.Mode)
+		}
+		switch net.Model {
+		case "", ModelAccount:
+		case ModelUTXO:
+			if net.Mode != ModeMock {
+				return nil, fmt.Errorf("network %s: the utxo model is only served from the simulated chain; set mode to mock", networkKey(net.Identifier))
+			}
+		default:
+			return nil, fmt.Errorf("network %s: unknown model %q", networkKey(net.Identifier), net.Model)
+		}
+		if net.Trace == "" {
+			net.Trace = os.Getenv("MESH_TRACE")
+		}
+		switch net.Trace {
+		case TraceOff, TraceDebug, TraceParity:
+		default:
+			return nil, fmt.Errorf("network %s: unknown trace mode %q", networkKey(net.Identifier), net.Trace)
+		}
+
+		if cfg.PollInterval != "" {
+			d, err := time.ParseDuration(cfg.PollInterval)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("network %s: invalid poll_interval %q", networkKey(net.Identifier), cfg.PollInterval)
+			}
+			net.PollInterval = d
+		}
+
+		rewards, err := parseRewardEras(cfg.Rewards)
+		if err != nil {
+			return nil, fmt.Errorf("network %s: %w", networkKey(net.Identifier), err)
+		}
+		net.rewards = rewards
+
+		urls := append([]string{}, cfg.RPCURLs...)
+		if cfg.RPCURL != "" {
+			urls = append([]string{cfg.RPCURL}, urls...)
+		}
+		if cfg.RPCURLEnv != "" {
+			urls = append(urls, SplitURLs(os.Getenv(cfg.RPCURLEnv))...)
+		}
+		if len(urls) > 0 {
+			net.RPC = NewEthRPC(urls...)
+		} else if !fromFile && cfg.Network == "Sepolia" {
+			// Sepolia keeps honouring the original INFURA_RPC_URL/ETH_RPC_URL
+			// variables.
+			net.RPC, _ = NewEthRPCFromEnv()
+		}
+		if net.RPC != nil && cfg.MaxBlockDrift > 0 {
+			net.RPC.MaxBlockDrift = cfg.MaxBlockDrift
+		}
+		if net.RPC != nil {
+			cache := CacheConfig{}
+			if cfg.Cache != nil {
+				cache = *cfg.Cache
+			}
+			net.Cache = NewBlockCache(cache)
+		}
+		if cfg.Index != nil {
+			if net.RPC == nil {
+				return nil, fmt.Errorf("network %s: index requires an RPC URL", networkKey(net.Identifier))
+			}
+			index, err := OpenIndexer(*cfg.Index)
+			if err != nil {
+				return nil, fmt.Errorf("network %s: %w", networkKey(net.Identifier), err)
+			}
+			net.Index = index
+		}
+		if net.RPC == nil {
+			if net.Mode == ModeLive {
+				return nil, fmt.Errorf("network %s: live mode requires an RPC URL", networkKey(net.Identifier))
+			}
+			log.Printf("No RPC configured for %s, serving mock data", networkKey(net.Identifier))
+		}
+
+		// Token lists come from the config file; the default Sepolia entry
+		// keeps reading MESH_TOKENS/MESH_TOKENS_FILE.
+		if !fromFile && cfg.Network == "Sepolia" {
+			net.Tokens = envTokenRegistry()
+		} else {
+			tokens, err := NewTokenRegistry(cfg.Tokens)
+			if err != nil {
+				return nil, fmt.Errorf("network %s: %w", networkKey(net.Identifier), err)
+			}
+			net.Tokens = tokens
+		}
+	}
+	return NewNetworks(list...)
+}
+
+// simulated returns the network's simulated chain, generating it on first
+// use so live networks never pay for it.
+func (n *Network) simulated() *SimChain {
+	n.simOnce.Do(func() {
+		cfg := SimConfig{Seed: n.ChainID.Int64()}
+		if n.Sim != nil {
+			cfg = *n.Sim
+			if cfg.Seed == 0 {
+				cfg.Seed = n.ChainID.Int64()
+			}
+		}
+		if n.model() == ModelUTXO {
+			n.simChain = NewUTXOSimChain(cfg, n.Currency)
+		} else {
+			n.simChain = NewSimChain(cfg, n.Currency)
+		}
+	})
+	return n.simChain
+}
+
+// model resolves the effective model; an unset model means account.
+func (n *Network) model() string {
+	if n.Model == "" {
+		return ModelAccount
+	}
+	return n.Model
+}
+
+// CacheStats reports the block cache statistics of every live network,
+// keyed by "blockchain/network".
+func (n *Networks) CacheStats() map[string]CacheStats {
+	out := map[string]CacheStats{}
+	for _, net := range n.list {
+		if net.Cache != nil {
+			out[networkKey(net.Identifier)] = net.Cache.Stats()
+		}
+	}
+	return out
+}
+
+// Close stops the indexers of the networks and closes their index stores.
+func (n *Networks) Close() error {
+	var errs []error
+	for _, net := range n.list {
+		if net.Index != nil {
+			if err := net.Index.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("network %s: %w", networkKey(net.Identifier), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Identifiers returns the identifiers of every served network in
+// configuration order.
+func (n *Networks) Identifiers() []*types.NetworkIdentifier {
+	ids := make([]*types.NetworkIdentifier, 0, len(n.list))
+	for _, net := range n.list {
+		ids = append(ids, net.Identifier)
+	}
+	return ids
+}
+
+// Lookup resolves a request's NetworkIdentifier to the network serving it.
+func (n *Networks) Lookup(id *types.NetworkIdentifier) (*Network, *types.Error) {
+	if id == nil {
+		return nil, wrapErr(ErrInvalidRequest, errors.New("network identifier is required"))
+	}
+	net, ok := n.byID[networkKey(id)]
+	if !ok || id.SubNetworkIdentifier != nil {
+		return nil, wrapErr(ErrInvalidRequest, fmt.Errorf("unsupported network %s", types.PrintStruct(id)))
+	}
+	return net, nil
+}
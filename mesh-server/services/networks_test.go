@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadNetworksFromEnv_Defaults(t *testing.T) {
+	t.Setenv("MESH_NETWORKS_FILE", "")
+	t.Setenv("MAINNET_RPC_URL", "http://mainnet.invalid")
+	networks, err := LoadNetworksFromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, []*types.NetworkIdentifier{
+		{Blockchain: "Ethereum", Network: "Mainnet"},
+		{Blockchain: "Ethereum", Network: "Sepolia"},
+		{Blockchain: "Ethereum", Network: "Holesky"},
+		{Blockchain: "Polygon", Network: "Amoy"},
+	}, networks.Identifiers())
+
+	mainnet, rErr := networks.Lookup(&types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Mainnet"})
+	require.Nil(t, rErr)
+	assert.Equal(t, "http://mainnet.invalid", mainnet.RPC.URLs()[0])
+	assert.Equal(t, int64(1), mainnet.ChainID.Int64())
+
+	amoy, rErr := networks.Lookup(&types.NetworkIdentifier{Blockchain: "Polygon", Network: "Amoy"})
+	require.Nil(t, rErr)
+	assert.Equal(t, "POL", amoy.Currency.Symbol)
+
+	_, rErr = networks.Lookup(&types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Mainnet"})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrInvalidRequest.Code, rErr.Code)
+}
+
+func TestLoadNetworksFromEnv_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "networks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"blockchain": "Ethereum", "network": "Holesky", "chain_id": 17000, "rpc_url_env": "TEST_HOLESKY_RPC",
+		 "currency": {"symbol": "ETH", "decimals": 18},
+		 "tokens": [{"address": "0x0000000000000000000000000000000000000001", "symbol": "TKN", "decimals": 6}]}
+	]`), 0o600))
+	t.Setenv("MESH_NETWORKS_FILE", path)
+	t.Setenv("TEST_HOLESKY_RPC", "http://holesky.invalid")
+
+	networks, err := LoadNetworksFromEnv()
+	require.NoError(t, err)
+	require.Len(t, networks.Identifiers(), 1)
+
+	holesky, rErr := networks.Lookup(&types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Holesky"})
+	require.Nil(t, rErr)
+	assert.Equal(t, "http://holesky.invalid", holesky.RPC.URLs()[0])
+	assert.Len(t, holesky.Tokens.Tokens(), 1)
+}
+
+func TestLoadNetworksFromEnv_Model(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "networks.json")
+	t.Setenv("MESH_NETWORKS_FILE", path)
+	t.Setenv("MESH_MODE", "")
+	write := func(entry string) {
+		require.NoError(t, os.WriteFile(path, []byte(`[{"blockchain": "Sim", "network": "Coins", "chain_id": 7,
+			"currency": {"symbol": "SIM", "decimals": 8}, `+entry+`}]`), 0o600))
+	}
+
+	write(`"model": "utxo", "mode": "mock"`)
+	networks, err := LoadNetworksFromEnv()
+	require.NoError(t, err)
+	assert.True(t, networks.list[0].simulated().UTXO())
+
+	write(`"model": "utxo", "rpc_url": "http://node.invalid"`)
+	_, err = LoadNetworksFromEnv()
+	assert.ErrorContains(t, err, "simulated chain")
+
+	write(`"model": "ledger"`)
+	_, err = LoadNetworksFromEnv()
+	assert.ErrorContains(t, err, "unknown model")
+}
+
+func TestLoadNetworksFromEnv_IndexLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "networks.json")
+	t.Setenv("MESH_NETWORKS_FILE", path)
+	indexed := `{"blockchain": "Ethereum", "network": "Sepolia", "chain_id": 11155111, "rpc_url": "http://node.invalid",
+		"currency": {"symbol": "ETH", "decimals": 18}, "index": {"path": "` + filepath.Join(dir, "index") + `"}}`
+	require.NoError(t, os.WriteFile(path, []byte(`[`+indexed+`]`), 0o600))
+
+	networks, err := LoadNetworksFromEnv()
+	require.NoError(t, err)
+	_, err = LoadNetworksFromEnv()
+	assert.Error(t, err, "the store is locked while open")
+	require.NoError(t, networks.Close())
+
+	// A later network failing to load releases the store opened before it
+	require.NoError(t, os.WriteFile(path, []byte(`[`+indexed+`, {"blockchain": "Ethereum", "network": "Mainnet"}]`), 0o600))
+	_, err = LoadNetworksFromEnv()
+	assert.ErrorContains(t, err, "are required")
+
+	require.NoError(t, os.WriteFile(path, []byte(`[`+indexed+`]`), 0o600))
+	networks, err = LoadNetworksFromEnv()
+	require.NoError(t, err)
+	require.NoError(t, networks.Close())
+}
+
+func TestNetworkDispatch(t *testing.T) {
+	mainnet := &types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Mainnet"}
+	rpcA := newStubRPC(t, map[string]interface{}{"eth_getTransactionByHash": map[string]interface{}{"hash": "0x01", "blockNumber": nil, "from": "0xa", "to": "0xb", "value": "0x1"}}, nil)
+	rpcB := newStubRPC(t, map[string]interface{}{"eth_getTransactionByHash": map[string]interface{}{"hash": "0x01", "blockNumber": nil, "from": "0xa", "to": "0xb", "value": "0x2"}}, nil)
+	networks, err := NewNetworks(
+		&Network{Identifier: sepolia, ChainID: mustBig("11155111"), Currency: ethCurrency, RPC: rpcA},
+		&Network{Identifier: mainnet, ChainID: mustBig("1"), Currency: ethCurrency, RPC: rpcB},
+	)
+	require.NoError(t, err)
+	s := NewMempoolAPIService(networks)
+
+	for id, want := range map[*types.NetworkIdentifier]string{sepolia: "1", mainnet: "2"} {
+		resp, rErr := s.MempoolTransaction(context.Background(), &types.MempoolTransactionRequest{
+			NetworkIdentifier:     id,
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "0x01"},
+		})
+		require.Nil(t, rErr)
+		assert.Equal(t, want, resp.Transaction.Operations[1].Amount.Value, id.Network)
+	}
+}
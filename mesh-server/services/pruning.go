@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stateProbeInterval is how long a pruning probe is trusted. In between, a
+// pruned node is assumed to keep the same number of blocks of state below
+// its head.
+const stateProbeInterval = time.Hour
+
+// probeAddress is the account whose balance is read to test for state.
+const probeAddress = "0x0000000000000000000000000000000000000000"
+
+// stateProbe finds the oldest block whose state a node still serves. Archive
+// nodes keep state back to genesis; full nodes prune all but recent blocks,
+// and balances before them cannot be read.
+type stateProbe struct {
+	mu      sync.Mutex
+	checked time.Time
+	archive bool
+	// window is how many blocks below the head a pruned node keeps state
+	// for.
+	window int64
+}
+
+// oldest returns the oldest block with state given the node's head, probing
+// the node when the last probe is older than stateProbeInterval. The probe
+// runs without the lock, so a slow node does not hold up other callers;
+// concurrent probes simply store the same result.
+func (p *stateProbe) oldest(ctx context.Context, n *Network, head int64) (int64, error) {
+	p.mu.Lock()
+	stale := time.Since(p.checked) > stateProbeInterval
+	p.mu.Unlock()
+	if stale {
+		oldest, err := probeOldestState(ctx, n, head)
+		if err != nil {
+			return 0, err
+		}
+		p.mu.Lock()
+		p.checked = time.Now()
+		p.archive = oldest == 0
+		p.window = head - oldest
+		p.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.archive || head < p.window {
+		return 0, nil
+	}
+	return head - p.window, nil
+}
+
+// historical reports whether the node serves balances back to genesis. It
+// probes the node when no recent probe is known.
+func (p *stateProbe) historical(ctx context.Context, n *Network) (bool, error) {
+	var headHex string
+	if err := n.RPC.call(ctx, "eth_blockNumber", []interface{}{}, &headHex); err != nil {
+		return false, err
+	}
+	head, err := hexToInt64(headHex)
+	if err != nil {
+		return false, err
+	}
+	if _, err := p.oldest(ctx, n, head); err != nil {
+		return false, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.archive, nil
+}
+
+// prunedStateErrors are the JSON-RPC error messages, lower-cased, of nodes
+// asked for state they have pruned.
+var prunedStateErrors = []string{"missing trie node", "header not found"}
+
+// prunedState reports whether err is a node's answer for pruned state.
+func prunedState(err error) bool {
+	var rpcErr *rpcError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	message := strings.ToLower(rpcErr.Message)
+	for _, pruned := range prunedStateErrors {
+		if strings.Contains(message, pruned) {
+			return true
+		}
+	}
+	return false
+}
+
+// probeOldestState binary searches for the oldest block at which the node
+// answers eth_getBalance. Pruned state is reported as a JSON-RPC error such
+// as "missing trie node"; any other failure, including a rate limit or a
+// node that does not serve eth_getBalance at all, aborts the probe.
+func probeOldestState(ctx context.Context, n *Network, head int64) (int64, error) {
+	hasState := func(index int64) (bool, error) {
+		var balance string
+		err := n.RPC.call(ctx, "eth_getBalance", []interface{}{probeAddress, int64ToHex(index)}, &balance)
+		if prunedState(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	ok, err := hasState(0)
+	if err != nil || ok {
+		return 0, err
+	}
+	// state is missing at lo and assumed at hi
+	lo, hi := int64(0), head
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ok, err := hasState(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, nil
+}
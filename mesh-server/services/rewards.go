@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// gweiToWei scales withdrawal amounts, which the execution layer reports in
+// Gwei.
+var gweiToWei = big.NewInt(1_000_000_000)
+
+// RewardEra is the static block reward paid to miners from FromBlock until
+// the next era starts. Reward is a decimal wei amount.
+type RewardEra struct {
+	FromBlock int64  `json:"from_block"`
+	Reward    string `json:"reward"`
+}
+
+type rewardEra struct {
+	fromBlock int64
+	reward    *big.Int
+}
+
+// Proof-of-work reward schedules of the default networks. Holesky launched
+// after the Merge and Polygon has no protocol block reward, so neither has
+// one.
+var (
+	mainnetRewards = []RewardEra{
+		{FromBlock: 0, Reward: "5000000000000000000"},
+		{FromBlock: 4370000, Reward: "3000000000000000000"}, // Byzantium
+		{FromBlock: 7280000, Reward: "2000000000000000000"}, // Constantinople
+	}
+	sepoliaRewards = []RewardEra{
+		{FromBlock: 0, Reward: "2000000000000000000"},
+	}
+)
+
+// parseRewardEras validates a reward schedule and sorts it by start block.
+func parseRewardEras(eras []RewardEra) ([]rewardEra, error) {
+	out := make([]rewardEra, 0, len(eras))
+	for _, e := range eras {
+		reward, ok := new(big.Int).SetString(e.Reward, 10)
+		if !ok || reward.Sign() < 0 || e.FromBlock < 0 {
+			return nil, fmt.Errorf("invalid reward era %+v", e)
+		}
+		out = append(out, rewardEra{fromBlock: e.FromBlock, reward: reward})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].fromBlock < out[j].fromBlock })
+	return out, nil
+}
+
+// blockReward returns the static reward for a block at index, or nil when the
+// schedule does not cover it.
+func (n *Network) blockReward(index int64) *big.Int {
+	var reward *big.Int
+	for _, e := range n.rewards {
+		if index >= e.fromBlock {
+			reward = e.reward
+		}
+	}
+	return reward
+}
+
+// rpcUncle is the subset of an uncle header needed to pay its miner.
+type rpcUncle struct {
+	Number string `json:"number"`
+	Miner  string `json:"miner"`
+}
+
+// rpcWithdrawal is a beacon-chain withdrawal processed by an execution block.
+type rpcWithdrawal struct {
+	Index          string `json:"index"`
+	ValidatorIndex string `json:"validatorIndex"`
+	Address        string `json:"address"`
+	Amount         string `json:"amount"` // Gwei
+}
+
+// blockRewardTransaction builds the block-level transaction, identified by
+// the block hash, that carries balance changes not caused by any transaction:
+// proof-of-work miner and uncle rewards and post-Merge validator withdrawals.
+// It returns nil when the block has none.
+func blockRewardTransaction(ctx context.Context, n *Network, blk *rpcBlock, index int64) (*types.Transaction, error) {
+	ops := []*types.Operation{}
+
+	// Post-Merge blocks report zero difficulty and pay no block reward.
+	if difficulty, err := hexToBigInt(blk.Difficulty); err == nil && difficulty.Sign() > 0 {
+		if reward := n.blockReward(index); reward != nil && blk.Miner != "" {
+			// The miner earns 1/32 of the reward for each uncle included.
+			minerReward := new(big.Int).Set(reward)
+			inclusion := new(big.Int).Div(reward, big.NewInt(32))
+			uncles := make([]rpcUncle, len(blk.Uncles))
+			calls := make([]*rpcCall, len(blk.Uncles))
+			for i := range blk.Uncles {
+				calls[i] = &rpcCall{Method: "eth_getUncleByBlockHashAndIndex", Params: []interface{}{blk.Hash, int64ToHex(int64(i))}, Out: &uncles[i]}
+			}
+			if len(calls) > 0 {
+				if err := n.RPC.batch(ctx, calls); err != nil {
+					return nil, err
+				}
+			}
+			for i, uncle := range uncles {
+				if calls[i].Err != nil {
+					return nil, fmt.Errorf("uncle %d of block %s: %w", i, blk.Hash, calls[i].Err)
+				}
+				uncleIndex, err := hexToInt64(uncle.Number)
+				if err != nil {
+					return nil, fmt.Errorf("uncle %d of block %s: %w", i, blk.Hash, err)
+				}
+				// Uncle miners earn (uncle + 8 - block) / 8 of the reward.
+				uncleReward := new(big.Int).Mul(reward, big.NewInt(uncleIndex+8-index))
+				uncleReward.Div(uncleReward, big.NewInt(8))
+				ops = appendReward(ops, uncle.Miner, uncleReward, n.Currency, map[string]interface{}{"reward_type": "uncle"})
+				minerReward.Add(minerReward, inclusion)
+			}
+			ops = appendReward(ops, blk.Miner, minerReward, n.Currency, map[string]interface{}{"reward_type": "block"})
+		}
+	}
+
+	for _, w := range blk.Withdrawals {
+		amount, err := hexToBigInt(w.Amount)
+		if err != nil || amount.Sign() == 0 {
+			continue
+		}
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
+			Type:                "Withdrawal",
+			Status:              strPtr(statusSuccess),
+			Account:             &types.AccountIdentifier{Address: w.Address},
+			Amount:              &types.Amount{Value: new(big.Int).Mul(amount, gweiToWei).String(), Currency: n.Currency},
+			Metadata: map[string]interface{}{
+				"withdrawal_index": hexToBigIntMust(w.Index),
+				"validator_index":  hexToBigIntMust(w.ValidatorIndex),
+			},
+		})
+	}
+
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: blk.Hash},
+		Operations:            ops,
+	}, nil
+}
+
+func appendReward(ops []*types.Operation, address string, amount *big.Int, currency *types.Currency, metadata map[string]interface{}) []*types.Operation {
+	return append(ops, &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
+		Type:                "Reward",
+		Status:              strPtr(statusSuccess),
+		Account:             &types.AccountIdentifier{Address: address},
+		Amount:              &types.Amount{Value: amount.String(), Currency: currency},
+		Metadata:            metadata,
+	})
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultRetries       = 2
+	defaultBackoff       = 100 * time.Millisecond
+	defaultMaxBlockDrift = 5
+	// heightCheckInterval is how often, at most, endpoint block heights are
+	// compared to find lagging nodes.
+	heightCheckInterval = 15 * time.Second
+	// ewmaWeight is the weight of the newest sample in the latency and
+	// error-rate averages.
+	ewmaWeight = 0.2
+)
+
+// rpcEndpoint is one upstream node together with its observed health.
+type rpcEndpoint struct {
+	url string
+	// name identifies the endpoint in errors and logs without leaking the
+	// API key that provider URLs usually carry in their path or query.
+	name string
+
+	mu        sync.Mutex
+	latency   time.Duration // moving average of successful requests
+	errorRate float64       // moving average of failures, 0 to 1
+	height    int64
+	lagging   bool
+}
+
+func newRPCEndpoint(raw string) *rpcEndpoint {
+	name := "endpoint"
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		name = u.Scheme + "://" + u.Host
+	}
+	return &rpcEndpoint{url: raw, name: name}
+}
+
+// record folds the outcome of one request into the endpoint's averages.
+func (e *rpcEndpoint) record(d time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	} else if e.latency == 0 {
+		e.latency = d
+	} else {
+		e.latency = time.Duration(ewmaWeight*float64(d) + (1-ewmaWeight)*float64(e.latency))
+	}
+	e.errorRate = ewmaWeight*failed + (1-ewmaWeight)*e.errorRate
+}
+
+// score ranks endpoints; lower is better. Errors weigh far more than
+// latency so a fast but failing node loses to a slow healthy one.
+func (e *rpcEndpoint) score() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ms := math.Max(float64(e.latency)/float64(time.Millisecond), 1)
+	score := ms * (1 + 20*e.errorRate)
+	if e.lagging {
+		score += 1e9
+	}
+	return score
+}
+
+// EndpointStatus is a snapshot of one endpoint's health.
+type EndpointStatus struct {
+	Name      string
+	Latency   time.Duration
+	ErrorRate float64
+	Height    int64
+	Lagging   bool
+}
+
+// Endpoints reports the health of every endpoint in configuration order.
+func (c *EthRPCClient) Endpoints() []EndpointStatus {
+	out := make([]EndpointStatus, len(c.endpoints))
+	for i, e := range c.endpoints {
+		e.mu.Lock()
+		out[i] = EndpointStatus{Name: e.name, Latency: e.latency, ErrorRate: e.errorRate, Height: e.height, Lagging: e.lagging}
+		e.mu.Unlock()
+	}
+	return out
+}
+
+// pick returns the best-scoring endpoint not yet tried for this request, or
+// the best overall once every endpoint has been tried.
+func (c *EthRPCClient) pick(tried map[*rpcEndpoint]bool) *rpcEndpoint {
+	var best *rpcEndpoint
+	bestScore := math.Inf(1)
+	for _, untriedOnly := range []bool{true, false} {
+		for _, e := range c.endpoints {
+			if untriedOnly && tried[e] {
+				continue
+			}
+			if s := e.score(); s < bestScore {
+				best, bestScore = e, s
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return best
+}
+
+// post sends one JSON-RPC payload, failing over between endpoints and
+// retrying retriable failures with exponential backoff.
+func (c *EthRPCClient) post(ctx context.Context, payload interface{}, out interface{}) error {
+	if len(c.endpoints) == 0 {
+		return errors.New("no rpc endpoints configured")
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	c.maybeCheckHeights()
+
+	tried := map[*rpcEndpoint]bool{}
+	var lastErr error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			wait := c.Backoff << (attempt - 1)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			}
+		}
+		e := c.pick(tried)
+		tried[e] = true
+
+		start := time.Now()
+		err := c.send(ctx, e.url, body, out)
+		e.record(time.Since(start), err)
+		if err == nil {
+			return nil
+		}
+		lastErr = fmt.Errorf("%s: %w", e.name, err)
+		if ctx.Err() != nil || !retriable(err) {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+// retriable reports whether a failed request may succeed if repeated,
+// possibly elsewhere: transport failures, rate limiting and server errors.
+// Malformed responses and JSON-RPC errors are final.
+func retriable(err error) bool {
+	var status *httpStatusError
+	if errors.As(err, &status) {
+		return status.code == 429 || status.code >= 500
+	}
+	var transport *url.Error
+	return errors.As(err, &transport)
+}
+
+// heightCheck throttles block-height comparisons between endpoints.
+type heightCheck struct {
+	running atomic.Bool
+	last    atomic.Int64 // unix nanoseconds
+}
+
+// maybeCheckHeights starts a background height comparison when the client
+// has several endpoints and the last comparison is stale.
+func (c *EthRPCClient) maybeCheckHeights() {
+	if len(c.endpoints) < 2 || time.Since(time.Unix(0, c.heights.last.Load())) < heightCheckInterval {
+		return
+	}
+	if !c.heights.running.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer c.heights.running.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		c.checkHeights(ctx)
+	}()
+}
+
+// checkHeights asks every endpoint for its head and takes those more than
+// MaxBlockDrift blocks behind the highest out of rotation. Endpoints that
+// catch up are put back.
+func (c *EthRPCClient) checkHeights(ctx context.Context) {
+	c.heights.last.Store(time.Now().UnixNano())
+	heights := make([]int64, len(c.endpoints))
+	_ = parallel(ctx, len(c.endpoints), len(c.endpoints), func(ctx context.Context, i int) error {
+		e := c.endpoints[i]
+		body, _ := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: c.nextID.Add(1), Method: "eth_blockNumber", Params: []interface{}{}})
+		var r rpcResponse
+		var head string
+		start := time.Now()
+		err := c.send(ctx, e.url, body, &r)
+		if err == nil {
+			err = r.decode(&head)
+		}
+		e.record(time.Since(start), err)
+		if err == nil {
+			heights[i], _ = hexToInt64(head)
+		}
+		return nil
+	})
+
+	var max int64
+	for _, h := range heights {
+		if h > max {
+			max = h
+		}
+	}
+	for i, e := range c.endpoints {
+		e.mu.Lock()
+		if heights[i] > 0 {
+			e.height = heights[i]
+		}
+		// endpoints that did not answer keep their previous verdict
+		if heights[i] > 0 || e.height == 0 {
+			e.lagging = max-e.height > c.MaxBlockDrift
+		}
+		e.mu.Unlock()
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRPC answers eth_blockNumber with head, or fails every request
+// with the given HTTP status, counting the requests it receives.
+func countingRPC(t *testing.T, status int, head string, hits *atomic.Int32) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": head})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestPool_FailsOverAndScores(t *testing.T) {
+	var downHits, upHits atomic.Int32
+	down := countingRPC(t, http.StatusServiceUnavailable, "", &downHits)
+	up := countingRPC(t, http.StatusOK, "0x10", &upHits)
+	rpc := NewEthRPC(down, up)
+	rpc.Backoff = time.Millisecond
+
+	var head string
+	require.NoError(t, rpc.call(context.Background(), "eth_blockNumber", []interface{}{}, &head))
+	assert.Equal(t, "0x10", head)
+	assert.Equal(t, int32(1), downHits.Load())
+
+	// the failure is remembered, so the healthy endpoint is tried first
+	require.NoError(t, rpc.call(context.Background(), "eth_blockNumber", []interface{}{}, &head))
+	assert.Equal(t, int32(1), downHits.Load())
+	assert.Greater(t, rpc.Endpoints()[0].ErrorRate, 0.0)
+	assert.Zero(t, rpc.Endpoints()[1].ErrorRate)
+}
+
+func TestPool_RetriesWithoutLeakingURL(t *testing.T) {
+	var hits atomic.Int32
+	down := countingRPC(t, http.StatusTooManyRequests, "", &hits)
+	rpc := NewEthRPC(down + "/v3/secret-key")
+	rpc.Backoff = time.Millisecond
+
+	err := rpc.call(context.Background(), "eth_blockNumber", []interface{}{}, nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(rpc.Retries+1), hits.Load())
+	assert.NotContains(t, err.Error(), "secret-key")
+}
+
+func TestPool_RPCErrorsAreFinal(t *testing.T) {
+	var sent []string
+	rpc := newStubRPC(t, map[string]interface{}{}, &sent)
+	err := rpc.call(context.Background(), "eth_unknown", []interface{}{}, nil)
+	assert.ErrorContains(t, err, "method not found")
+	assert.Zero(t, rpc.Endpoints()[0].ErrorRate, "JSON-RPC errors say nothing about endpoint health")
+}
+
+func TestPool_DropsLaggingEndpoints(t *testing.T) {
+	var laggingHits, freshHits atomic.Int32
+	lagging := countingRPC(t, http.StatusOK, "0x64", &laggingHits) // 100
+	fresh := countingRPC(t, http.StatusOK, "0x78", &freshHits)     // 120
+	rpc := NewEthRPC(lagging, fresh)
+
+	rpc.checkHeights(context.Background())
+	status := rpc.Endpoints()
+	assert.True(t, status[0].Lagging)
+	assert.False(t, status[1].Lagging)
+	assert.Equal(t, int64(120), status[1].Height)
+
+	laggingHits.Store(0)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, rpc.call(context.Background(), "eth_blockNumber", []interface{}{}, nil))
+	}
+	assert.Zero(t, laggingHits.Load())
+}
+
+func TestNewEthRPCFromEnv_NoFallback(t *testing.T) {
+	t.Setenv("INFURA_RPC_URL", "")
+	t.Setenv("ETH_RPC_URL", "")
+	_, err := NewEthRPCFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("ETH_RPC_URL", "http://a.invalid, http://b.invalid")
+	rpc, err := NewEthRPCFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://a.invalid", "http://b.invalid"}, rpc.URLs())
+}
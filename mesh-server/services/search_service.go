@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// defaultSearchLimit and maxSearchLimit bound one /search/transactions
+	// page.
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+// SearchAPIService implements the Search API interface
+type SearchAPIService struct {
+	networks *Networks
+}
+
+// NewSearchAPIService creates a new SearchAPIService
+func NewSearchAPIService(networks *Networks) *SearchAPIService {
+	return &SearchAPIService{networks: networks}
+}
+
+// SearchTransactions implements the /search/transactions endpoint. Networks
+// with an index search it; mock and hybrid networks without one scan the
+// simulated chain. Results are newest first.
+func (s *SearchAPIService) SearchTransactions(
+	ctx context.Context,
+	request *types.SearchTransactionsRequest,
+) (*types.SearchTransactionsResponse, *types.Error) {
+	n, rErr := s.networks.Lookup(request.NetworkIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+	filter, rErr := newTransactionFilter(n, request)
+	if rErr != nil {
+		return nil, rErr
+	}
+	page := &searchPage{limit: defaultSearchLimit}
+	if request.Offset != nil {
+		page.offset = *request.Offset
+	}
+	if request.Limit != nil && *request.Limit > 0 {
+		page.limit = *request.Limit
+	}
+	if page.limit > maxSearchLimit {
+		page.limit = maxSearchLimit
+	}
+	maxBlock := int64(math.MaxInt64)
+	if request.MaxBlock != nil {
+		maxBlock = *request.MaxBlock
+	}
+	visit := func(bt *types.BlockTransaction) {
+		if filter.matches(bt) {
+			page.add(bt)
+		}
+	}
+
+	switch {
+	case n.Index != nil:
+		// When the index alone decides which transactions match, decoding
+		// stops once the page is full and the rest are only counted.
+		indexOnly := filter.indexOnly()
+		rest, err := n.Index.search(filter.indexAddress(), maxBlock, func(bt *types.BlockTransaction) bool {
+			visit(bt)
+			return !indexOnly || !page.full()
+		})
+		if err != nil {
+			return nil, wrapErr(ErrNetwork, err)
+		}
+		page.total += rest
+		markSource(ctx, SourceLive)
+	case n.mode() != ModeLive:
+		sim := n.simulated()
+		start := sim.Head().BlockIdentifier.Index
+		if maxBlock < start {
+			start = maxBlock
+		}
+		for i := start; i >= 0; i-- {
+			index := i
+			b, _ := sim.Block(&types.PartialBlockIdentifier{Index: &index})
+			for j := len(b.Transactions) - 1; j >= 0; j-- {
+				visit(&types.BlockTransaction{BlockIdentifier: b.BlockIdentifier, Transaction: b.Transactions[j]})
+			}
+		}
+		markSource(ctx, SourceMock)
+	default:
+		return nil, wrapErr(ErrIndexUnavailable, errors.New("network has no transaction index; configure one with \"index\""))
+	}
+	return page.response(), nil
+}
+
+// searchPage collects one page of matching transactions and counts them all.
+type searchPage struct {
+	offset, limit int64
+	total         int64
+	out           []*types.BlockTransaction
+}
+
+func (p *searchPage) add(bt *types.BlockTransaction) {
+	if p.total >= p.offset && int64(len(p.out)) < p.limit {
+		p.out = append(p.out, bt)
+	}
+	p.total++
+}
+
+// full reports whether the page has every transaction it will return.
+func (p *searchPage) full() bool {
+	return p.total >= p.offset+p.limit
+}
+
+func (p *searchPage) response() *types.SearchTransactionsResponse {
+	resp := &types.SearchTransactionsResponse{Transactions: p.out, TotalCount: p.total}
+	if resp.Transactions == nil {
+		resp.Transactions = []*types.BlockTransaction{}
+	}
+	if next := p.offset + int64(len(p.out)); next < p.total {
+		resp.NextOffset = &next
+	}
+	return resp
+}
+
+// transactionFilter holds the conditions of a search. With the "and"
+// operator a transaction must meet all of them, with "or" any one. An
+// operation condition (account, address, coin, currency, status, type) is
+// met when at least one operation of the transaction meets it.
+type transactionFilter struct {
+	or         bool
+	address    string
+	byAddress  bool
+	conditions []func(*types.BlockTransaction) bool
+}
+
+func newTransactionFilter(n *Network, request *types.SearchTransactionsRequest) (*transactionFilter, *types.Error) {
+	f := &transactionFilter{or: request.Operator != nil && *request.Operator == types.OR}
+	if coin := request.CoinIdentifier; coin != nil {
+		if n.model() != ModelUTXO {
+			return nil, wrapErr(ErrInvalidRequest, errors.New("coin_identifier is not supported on account-based networks"))
+		}
+		f.conditions = append(f.conditions, anyOperation(func(op *types.Operation) bool {
+			return op.CoinChange != nil && op.CoinChange.CoinIdentifier.Identifier == coin.Identifier
+		}))
+	}
+	if id := request.TransactionIdentifier; id != nil {
+		f.conditions = append(f.conditions, func(bt *types.BlockTransaction) bool {
+			return strings.EqualFold(bt.Transaction.TransactionIdentifier.Hash, id.Hash)
+		})
+	}
+	if account := request.AccountIdentifier; account != nil {
+		f.address = account.Address
+		f.conditions = append(f.conditions, anyOperation(func(op *types.Operation) bool {
+			return op.Account != nil && strings.EqualFold(op.Account.Address, account.Address) &&
+				types.Hash(op.Account.SubAccount) == types.Hash(account.SubAccount)
+		}))
+	}
+	if address := request.Address; address != nil {
+		f.address = *address
+		f.byAddress = true
+		f.conditions = append(f.conditions, anyOperation(func(op *types.Operation) bool {
+			return op.Account != nil && strings.EqualFold(op.Account.Address, *address)
+		}))
+	}
+	if currency := request.Currency; currency != nil {
+		f.conditions = append(f.conditions, anyOperation(func(op *types.Operation) bool {
+			return op.Amount != nil && currencyMatches(op.Amount.Currency, currency)
+		}))
+	}
+	if status := request.Status; status != nil {
+		f.conditions = append(f.conditions, anyOperation(func(op *types.Operation) bool {
+			return op.Status != nil && *op.Status == *status
+		}))
+	}
+	if typ := request.Type; typ != nil {
+		f.conditions = append(f.conditions, anyOperation(func(op *types.Operation) bool {
+			return op.Type == *typ
+		}))
+	}
+	if success := request.Success; success != nil {
+		f.conditions = append(f.conditions, func(bt *types.BlockTransaction) bool {
+			return transactionSucceeded(bt.Transaction) == *success
+		})
+	}
+	return f, nil
+}
+
+// indexAddress returns the address whose transactions can be read from the
+// account index instead of scanning every transaction, or "" when the
+// conditions do not require one.
+func (f *transactionFilter) indexAddress() string {
+	if f.or {
+		return ""
+	}
+	return f.address
+}
+
+// indexOnly reports whether every transaction search reads for
+// indexAddress matches, so matches can be counted without being decoded:
+// there are no conditions, or only the address one, which the account index
+// answers.
+func (f *transactionFilter) indexOnly() bool {
+	return len(f.conditions) == 0 || (len(f.conditions) == 1 && f.byAddress && !f.or)
+}
+
+func (f *transactionFilter) matches(bt *types.BlockTransaction) bool {
+	if len(f.conditions) == 0 {
+		return true
+	}
+	for _, cond := range f.conditions {
+		if cond(bt) == f.or {
+			return f.or
+		}
+	}
+	return !f.or
+}
+
+func anyOperation(match func(*types.Operation) bool) func(*types.BlockTransaction) bool {
+	return func(bt *types.BlockTransaction) bool {
+		for _, op := range bt.Transaction.Operations {
+			if match(op) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// currencyMatches compares on symbol and decimals, and on the contract
+// address when the requested currency names one.
+func currencyMatches(c, want *types.Currency) bool {
+	if c == nil || c.Symbol != want.Symbol || c.Decimals != want.Decimals {
+		return false
+	}
+	if addr, ok := want.Metadata["contract_address"].(string); ok {
+		have, _ := c.Metadata["contract_address"].(string)
+		return strings.EqualFold(have, addr)
+	}
+	return true
+}
+
+// transactionSucceeded reports the receipt status recorded in the metadata
+// of mined transactions; transactions without one, such as block rewards,
+// succeed when all their operations do.
+func transactionSucceeded(tx *types.Transaction) bool {
+	if status, ok := tx.Metadata["status"].(string); ok {
+		return status == statusSuccess
+	}
+	for _, op := range tx.Operations {
+		if op.Status == nil || *op.Status != statusSuccess {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,462 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SimConfig configures the simulated chain that mock data is served from.
+// Zero values select the defaults below; the seed defaults to the chain ID.
+type SimConfig struct {
+	Seed     int64    `json:"seed,omitempty"`
+	Blocks   int64    `json:"blocks,omitempty"`
+	Accounts []string `json:"accounts,omitempty"`
+}
+
+const (
+	defaultSimBlocks   = 1000
+	defaultSimAccounts = 8
+	// maxSimTxs bounds the transactions generated per block.
+	maxSimTxs = 4
+	// simGenesisTime is the genesis timestamp in milliseconds; blocks follow
+	// every simBlockTime milliseconds.
+	simGenesisTime = 1700000000000
+	simBlockTime   = 12000
+	simGasLimit    = 21000
+	// simPendingTxs is the number of transfers waiting in the simulated
+	// mempool of an account-based chain.
+	simPendingTxs = 3
+)
+
+var (
+	simGenesisBalance = mustBigInt("1000000000000000000000") // 1000 ETH
+	simBlockReward    = mustBigInt("2000000000000000000")    // 2 ETH
+	simBaseFee        = big.NewInt(7_000_000_000)
+)
+
+// SimChain is a deterministic, hash-linked chain of value transfers between a
+// fixed set of accounts. Every block is generated up front from the seed and
+// balances are snapshotted per block by applying its operations, so
+// /account/balance at any height always matches the operations served by
+// /block.
+type SimChain struct {
+	currency *types.Currency
+	accounts []string
+	blocks   []*types.Block
+	balances []map[string]*big.Int
+	byHash   map[string]int64
+	txBlock  map[string]int64
+	// unspent holds the coins of a UTXO chain by owner, oldest first; nil
+	// for account-based chains.
+	unspent map[string][]*types.Coin
+	// nonces counts the transactions each account has sent.
+	nonces map[string]uint64
+	// pending holds the transfers waiting for the block after the head,
+	// in hash order; empty for UTXO chains.
+	pending []*rpcTx
+}
+
+// NewSimChain generates the account-based chain described by cfg in the
+// given currency.
+func NewSimChain(cfg SimConfig, currency *types.Currency) *SimChain {
+	return newSimChain(cfg, currency, false)
+}
+
+// NewUTXOSimChain generates the chain described by cfg as a UTXO chain:
+// every credit creates a coin, and a transfer spends all of the sender's
+// coins into the payment, change for the sender and a fee for the miner.
+func NewUTXOSimChain(cfg SimConfig, currency *types.Currency) *SimChain {
+	return newSimChain(cfg, currency, true)
+}
+
+func newSimChain(cfg SimConfig, currency *types.Currency, utxo bool) *SimChain {
+	if cfg.Blocks <= 0 {
+		cfg.Blocks = defaultSimBlocks
+	}
+	c := &SimChain{
+		currency: currency,
+		byHash:   map[string]int64{},
+		txBlock:  map[string]int64{},
+		nonces:   map[string]uint64{},
+	}
+	if utxo {
+		c.unspent = map[string][]*types.Coin{}
+	}
+	for _, a := range cfg.Accounts {
+		c.accounts = append(c.accounts, strings.ToLower(a))
+	}
+	for i := len(c.accounts); i < defaultSimAccounts && len(cfg.Accounts) == 0; i++ {
+		c.accounts = append(c.accounts, strings.ToLower(common.BytesToAddress(simHash(cfg.Seed, "account", int64(i))).Hex()))
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	balances := map[string]*big.Int{}
+
+	// Genesis allocations are reported as Reward operations so the chain is
+	// self-describing from block 0.
+	var genesisOps []*types.Operation
+	for _, a := range c.accounts {
+		genesisOps = appendReward(genesisOps, a, simGenesisBalance, currency, map[string]interface{}{"reward_type": "genesis"})
+	}
+	applyOperations(balances, genesisOps)
+	genesisHash := hexutil.Encode(simHash(cfg.Seed, "block", 0))
+	c.createCoins(genesisHash, genesisOps)
+	genesis := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 0, Hash: genesisHash},
+		Timestamp:       simGenesisTime,
+		Transactions: []*types.Transaction{{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: genesisHash},
+			Operations:            genesisOps,
+		}},
+	}
+	// Rosetta expects the genesis block to be its own parent.
+	genesis.ParentBlockIdentifier = genesis.BlockIdentifier
+	c.appendBlock(genesis, balances)
+
+	for index := int64(1); index <= cfg.Blocks; index++ {
+		parent := c.blocks[index-1].BlockIdentifier
+		miner := c.accounts[rng.Intn(len(c.accounts))]
+
+		var txs []*types.Transaction
+		for n := rng.Intn(maxSimTxs + 1); n > 0; n-- {
+			if tx := c.randomTransfer(rng, cfg.Seed, index, len(txs), miner, balances); tx != nil {
+				applyOperations(balances, tx.Operations)
+				txs = append(txs, tx)
+			}
+		}
+
+		// The block hash commits to the parent and every transaction.
+		parts := [][]byte{common.FromHex(parent.Hash)}
+		for _, tx := range txs {
+			parts = append(parts, common.FromHex(tx.TransactionIdentifier.Hash))
+		}
+		hash := hexutil.Encode(crypto.Keccak256(append(parts, simHash(cfg.Seed, "block", index))...))
+
+		reward := &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations:            appendReward(nil, miner, simBlockReward, currency, map[string]interface{}{"reward_type": "block"}),
+		}
+		applyOperations(balances, reward.Operations)
+		c.createCoins(hash, reward.Operations)
+
+		c.appendBlock(&types.Block{
+			BlockIdentifier:       &types.BlockIdentifier{Index: index, Hash: hash},
+			ParentBlockIdentifier: parent,
+			Timestamp:             simGenesisTime + index*simBlockTime,
+			Transactions:          append([]*types.Transaction{reward}, txs...),
+		}, balances)
+	}
+	if !utxo {
+		c.generatePending(rng, cfg.Seed, balances)
+	}
+	return c
+}
+
+// generatePending fills the mempool with transfers the head balances can pay
+// for, each with the sender's next nonce. They move no balance: the chain
+// never mines them.
+func (c *SimChain) generatePending(rng *rand.Rand, seed int64, balances map[string]*big.Int) {
+	index := int64(len(c.blocks))
+	for seq := 0; len(c.pending) < simPendingTxs && seq < 4*simPendingTxs; seq++ {
+		from := c.accounts[rng.Intn(len(c.accounts))]
+		to := c.accounts[rng.Intn(len(c.accounts))]
+		tip := big.NewInt(rng.Int63n(2_000_000_000) + 1)
+		maxFee := new(big.Int).Add(new(big.Int).Mul(simBaseFee, big.NewInt(2)), tip)
+		spendable := new(big.Int).Sub(balanceOf(balances, from), new(big.Int).Mul(maxFee, big.NewInt(simGasLimit)))
+		if from == to || spendable.Sign() <= 0 {
+			continue
+		}
+		value := new(big.Int).Rand(rng, new(big.Int).Add(new(big.Int).Div(spendable, big.NewInt(10)), big.NewInt(1)))
+
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(seq))
+		c.pending = append(c.pending, &rpcTx{
+			Hash:                 hexutil.Encode(crypto.Keccak256(simHash(seed, "pending", index), buf)),
+			From:                 from,
+			To:                   to,
+			Value:                hexutil.EncodeBig(value),
+			Gas:                  int64ToHex(simGasLimit),
+			MaxFeePerGas:         hexutil.EncodeBig(maxFee),
+			MaxPriorityFeePerGas: hexutil.EncodeBig(tip),
+			Nonce:                hexutil.EncodeUint64(c.nonces[from]),
+		})
+		c.nonces[from]++
+	}
+	sort.Slice(c.pending, func(i, j int) bool { return c.pending[i].Hash < c.pending[j].Hash })
+}
+
+// randomTransfer generates a transfer paying a tip to miner. One in ten
+// transfers on account-based chains fails, moving no value but still paying
+// its fee; UTXO transfers always succeed.
+func (c *SimChain) randomTransfer(rng *rand.Rand, seed, index int64, seq int, miner string, balances map[string]*big.Int) *types.Transaction {
+	from := c.accounts[rng.Intn(len(c.accounts))]
+	to := c.accounts[rng.Intn(len(c.accounts))]
+	tip := big.NewInt(rng.Int63n(2_000_000_000) + 1)
+	price := new(big.Int).Add(simBaseFee, tip)
+	fee := new(big.Int).Mul(price, big.NewInt(simGasLimit))
+
+	spendable := new(big.Int).Sub(balanceOf(balances, from), fee)
+	if from == to || spendable.Sign() <= 0 {
+		return nil
+	}
+	c.nonces[from]++
+	// Send up to a tenth of what the sender can spend.
+	value := new(big.Int).Rand(rng, new(big.Int).Add(new(big.Int).Div(spendable, big.NewInt(10)), big.NewInt(1)))
+	status := statusSuccess
+	if rng.Intn(10) == 0 && c.unspent == nil {
+		status = statusFailure
+	}
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(seq))
+	hash := hexutil.Encode(crypto.Keccak256(simHash(seed, "tx", index), buf))
+
+	if c.unspent != nil {
+		tipFee := new(big.Int).Mul(tip, big.NewInt(simGasLimit))
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations:            c.spendCoins(hash, from, to, miner, value, fee, tipFee),
+			Metadata:              map[string]interface{}{"status": status},
+		}
+	}
+
+	var ops []*types.Operation
+	if value.Sign() > 0 {
+		ops = appendTransfer(ops, from, to, value, c.currency, strPtr(status), nil)
+	}
+	rc := &rpcReceipt{GasUsed: int64ToHex(simGasLimit), EffectiveGasPrice: hexutil.EncodeBig(price)}
+	blk := &rpcBlock{BaseFeePerGas: hexutil.EncodeBig(simBaseFee), Miner: miner}
+	ops = appendFeeOperations(ops, from, rc, blk, c.currency)
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+		Operations:            ops,
+		Metadata:              map[string]interface{}{"status": status},
+	}
+}
+
+// spendCoins spends every coin of from and creates coins paying value to to,
+// the change back to from and tip to miner. The rest of fee is burned.
+func (c *SimChain) spendCoins(hash, from, to, miner string, value, fee, tip *big.Int) []*types.Operation {
+	var ops []*types.Operation
+	in := new(big.Int)
+	for _, coin := range c.unspent[from] {
+		amount := mustBigInt(coin.Amount.Value)
+		in.Add(in, amount)
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
+			Type:                "Transfer",
+			Status:              strPtr(statusSuccess),
+			Account:             &types.AccountIdentifier{Address: from},
+			Amount:              &types.Amount{Value: new(big.Int).Neg(amount).String(), Currency: c.currency},
+			CoinChange:          &types.CoinChange{CoinIdentifier: coin.CoinIdentifier, CoinAction: types.CoinSpent},
+		})
+	}
+	delete(c.unspent, from)
+
+	inputs := make([]*types.OperationIdentifier, len(ops))
+	for i, op := range ops {
+		inputs[i] = op.OperationIdentifier
+	}
+	output := func(typ, owner string, amount *big.Int) {
+		if amount.Sign() > 0 {
+			ops = append(ops, &types.Operation{
+				OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
+				RelatedOperations:   inputs,
+				Type:                typ,
+				Status:              strPtr(statusSuccess),
+				Account:             &types.AccountIdentifier{Address: owner},
+				Amount:              &types.Amount{Value: amount.String(), Currency: c.currency},
+			})
+		}
+	}
+	output("Transfer", to, value)
+	output("Transfer", from, new(big.Int).Sub(new(big.Int).Sub(in, value), fee))
+	output("Fee", miner, tip)
+	c.createCoins(hash, ops[len(inputs):])
+	return ops
+}
+
+// createCoins records a coin for every credit in ops, identified as
+// "<transaction hash>:<operation index>". It does nothing on account-based
+// chains.
+func (c *SimChain) createCoins(hash string, ops []*types.Operation) {
+	if c.unspent == nil {
+		return
+	}
+	for _, op := range ops {
+		if op.Amount == nil || mustBigInt(op.Amount.Value).Sign() <= 0 {
+			continue
+		}
+		id := &types.CoinIdentifier{Identifier: fmt.Sprintf("%s:%d", hash, op.OperationIdentifier.Index)}
+		op.CoinChange = &types.CoinChange{CoinIdentifier: id, CoinAction: types.CoinCreated}
+		owner := op.Account.Address
+		c.unspent[owner] = append(c.unspent[owner], &types.Coin{CoinIdentifier: id, Amount: op.Amount})
+	}
+}
+
+func (c *SimChain) appendBlock(b *types.Block, balances map[string]*big.Int) {
+	snapshot := make(map[string]*big.Int, len(balances))
+	for a, v := range balances {
+		snapshot[a] = new(big.Int).Set(v)
+	}
+	c.blocks = append(c.blocks, b)
+	c.balances = append(c.balances, snapshot)
+	c.byHash[b.BlockIdentifier.Hash] = b.BlockIdentifier.Index
+	for _, tx := range b.Transactions {
+		c.txBlock[tx.TransactionIdentifier.Hash] = b.BlockIdentifier.Index
+	}
+}
+
+// Accounts returns the simulated accounts.
+func (c *SimChain) Accounts() []string {
+	return append([]string(nil), c.accounts...)
+}
+
+// Genesis returns the identifier of block 0.
+func (c *SimChain) Genesis() *types.BlockIdentifier {
+	return c.blocks[0].BlockIdentifier
+}
+
+// Head returns the last generated block.
+func (c *SimChain) Head() *types.Block {
+	return c.blocks[len(c.blocks)-1]
+}
+
+// Block resolves a block by index or hash, or the head when id selects
+// neither. The returned block is a copy whose Metadata may be set freely.
+func (c *SimChain) Block(id *types.PartialBlockIdentifier) (*types.Block, bool) {
+	index, ok := c.resolve(id)
+	if !ok {
+		return nil, false
+	}
+	b := *c.blocks[index]
+	b.Metadata = nil
+	return &b, true
+}
+
+// UTXO reports whether the chain tracks coins.
+func (c *SimChain) UTXO() bool {
+	return c.unspent != nil
+}
+
+// Coins returns copies of the unspent coins of address at the head, oldest
+// first.
+func (c *SimChain) Coins(address string) []*types.Coin {
+	out := []*types.Coin{}
+	for _, coin := range c.unspent[strings.ToLower(address)] {
+		out = append(out, &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: coin.CoinIdentifier.Identifier},
+			Amount:         &types.Amount{Value: coin.Amount.Value, Currency: coin.Amount.Currency},
+		})
+	}
+	return out
+}
+
+// Transaction returns a copy of a transaction and the block containing it.
+func (c *SimChain) Transaction(hash string) (*types.Transaction, *types.BlockIdentifier, bool) {
+	index, ok := c.txBlock[strings.ToLower(hash)]
+	if !ok {
+		return nil, nil, false
+	}
+	b := c.blocks[index]
+	for _, tx := range b.Transactions {
+		if tx.TransactionIdentifier.Hash == strings.ToLower(hash) {
+			out := *tx
+			out.Metadata = map[string]interface{}{}
+			for k, v := range tx.Metadata {
+				out.Metadata[k] = v
+			}
+			return &out, b.BlockIdentifier, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Pending returns the hashes of the transactions in the mempool, sorted.
+func (c *SimChain) Pending() []string {
+	hashes := make([]string, 0, len(c.pending))
+	for _, tx := range c.pending {
+		hashes = append(hashes, tx.Hash)
+	}
+	return hashes
+}
+
+// pendingTx returns a copy of the pending transaction hash.
+func (c *SimChain) pendingTx(hash string) (*rpcTx, bool) {
+	for _, tx := range c.pending {
+		if tx.Hash == strings.ToLower(hash) {
+			out := *tx
+			return &out, true
+		}
+	}
+	return nil, false
+}
+
+// Balance returns the balance of address after the block selected by id.
+func (c *SimChain) Balance(address string, id *types.PartialBlockIdentifier) (*types.BlockIdentifier, *big.Int, bool) {
+	index, ok := c.resolve(id)
+	if !ok {
+		return nil, nil, false
+	}
+	return c.blocks[index].BlockIdentifier, new(big.Int).Set(balanceOf(c.balances[index], strings.ToLower(address))), true
+}
+
+func (c *SimChain) resolve(id *types.PartialBlockIdentifier) (int64, bool) {
+	head := int64(len(c.blocks) - 1)
+	switch {
+	case id == nil || (id.Index == nil && id.Hash == nil):
+		return head, true
+	case id.Hash != nil:
+		index, ok := c.byHash[strings.ToLower(*id.Hash)]
+		if !ok || (id.Index != nil && *id.Index != index) {
+			return 0, false
+		}
+		return index, true
+	default:
+		return *id.Index, *id.Index >= 0 && *id.Index <= head
+	}
+}
+
+// applyOperations adds every successful operation's amount to its account.
+func applyOperations(balances map[string]*big.Int, ops []*types.Operation) {
+	for _, op := range ops {
+		if op.Status == nil || *op.Status != statusSuccess || op.Amount == nil {
+			continue
+		}
+		amount, _ := new(big.Int).SetString(op.Amount.Value, 10)
+		balances[op.Account.Address] = new(big.Int).Add(balanceOf(balances, op.Account.Address), amount)
+	}
+}
+
+func balanceOf(balances map[string]*big.Int, address string) *big.Int {
+	if v, ok := balances[address]; ok {
+		return v
+	}
+	return big.NewInt(0)
+}
+
+// simHash derives a deterministic 32-byte value from the seed, a label and
+// an index.
+func simHash(seed int64, label string, index int64) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf, uint64(seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(index))
+	return crypto.Keccak256([]byte(label), buf)
+}
+
+func mustBigInt(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid big integer " + s)
+	}
+	return v
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimChain_Deterministic(t *testing.T) {
+	a := NewSimChain(SimConfig{Seed: 7, Blocks: 50}, ethCurrency)
+	b := NewSimChain(SimConfig{Seed: 7, Blocks: 50}, ethCurrency)
+	c := NewSimChain(SimConfig{Seed: 8, Blocks: 50}, ethCurrency)
+
+	assert.Equal(t, a.Head(), b.Head())
+	assert.NotEqual(t, a.Head().BlockIdentifier.Hash, c.Head().BlockIdentifier.Hash)
+	assert.Len(t, a.Accounts(), defaultSimAccounts)
+}
+
+func TestSimChain_LinkedAndReconciled(t *testing.T) {
+	c := NewSimChain(SimConfig{Seed: 1, Blocks: 200}, ethCurrency)
+	sums := map[string]*big.Int{}
+	transfers := 0
+	for i := int64(0); i <= 200; i++ {
+		idx := i
+		blk, ok := c.Block(&types.PartialBlockIdentifier{Index: &idx})
+		require.True(t, ok)
+		if i > 0 {
+			parent, _ := c.Block(&types.PartialBlockIdentifier{Index: &[]int64{i - 1}[0]})
+			assert.Equal(t, parent.BlockIdentifier, blk.ParentBlockIdentifier)
+		}
+		byHash, ok := c.Block(&types.PartialBlockIdentifier{Hash: &blk.BlockIdentifier.Hash})
+		require.True(t, ok)
+		assert.Equal(t, i, byHash.BlockIdentifier.Index)
+
+		for _, tx := range blk.Transactions {
+			for _, op := range tx.Operations {
+				if op.Type == "Transfer" {
+					transfers++
+				}
+			}
+			applyOperations(sums, tx.Operations)
+		}
+		for _, account := range c.Accounts() {
+			_, balance, ok := c.Balance(account, &types.PartialBlockIdentifier{Index: &idx})
+			require.True(t, ok)
+			assert.Equal(t, balanceOf(sums, account).String(), balance.String(), "account %s at block %d", account, i)
+			assert.True(t, balance.Sign() >= 0)
+		}
+	}
+	assert.NotZero(t, transfers)
+
+	beyond := int64(201)
+	_, ok := c.Block(&types.PartialBlockIdentifier{Index: &beyond})
+	assert.False(t, ok)
+}
+
+func TestMockMode_ServesSimulatedChain(t *testing.T) {
+	networks := testNetworks(t, nil)
+	blocks := NewBlockAPIService(networks)
+	accounts := NewAccountAPIService(networks)
+	sim := networks.list[0].simulated()
+
+	idx := int64(10)
+	resp, rErr := blocks.Block(context.Background(), &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, SourceMock, resp.Block.Metadata["source"])
+
+	last := resp.Block.Transactions[len(resp.Block.Transactions)-1]
+	tx, rErr := blocks.BlockTransaction(context.Background(), &types.BlockTransactionRequest{
+		NetworkIdentifier:     sepolia,
+		BlockIdentifier:       resp.Block.BlockIdentifier,
+		TransactionIdentifier: last.TransactionIdentifier,
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, last.Operations, tx.Transaction.Operations)
+
+	balance, rErr := accounts.AccountBalance(context.Background(), &types.AccountBalanceRequest{
+		NetworkIdentifier: sepolia,
+		AccountIdentifier: &types.AccountIdentifier{Address: sim.Accounts()[0]},
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.Nil(t, rErr)
+	_, want, _ := sim.Balance(sim.Accounts()[0], &types.PartialBlockIdentifier{Index: &idx})
+	assert.Equal(t, want.String(), balance.Balances[0].Value)
+	assert.Equal(t, resp.Block.BlockIdentifier, balance.BlockIdentifier)
+}
+
+func TestSimChain_UTXOCoinsMatchBalances(t *testing.T) {
+	c := NewUTXOSimChain(SimConfig{Seed: 1, Blocks: 200}, ethCurrency)
+	require.True(t, c.UTXO())
+	unspent := map[string]string{}
+	for i := int64(0); i <= 200; i++ {
+		idx := i
+		blk, _ := c.Block(&types.PartialBlockIdentifier{Index: &idx})
+		for _, tx := range blk.Transactions {
+			for _, op := range tx.Operations {
+				change := op.CoinChange
+				require.NotNil(t, change, "every operation moves a coin")
+				id := change.CoinIdentifier.Identifier
+				switch change.CoinAction {
+				case types.CoinCreated:
+					unspent[id] = op.Amount.Value
+				case types.CoinSpent:
+					value, ok := unspent[id]
+					require.True(t, ok, "coin %s spent before it was created, or twice", id)
+					assert.Equal(t, "-"+value, op.Amount.Value)
+					delete(unspent, id)
+				}
+			}
+		}
+	}
+
+	for _, account := range c.Accounts() {
+		sum := new(big.Int)
+		for _, coin := range c.Coins(account) {
+			assert.Equal(t, unspent[coin.CoinIdentifier.Identifier], coin.Amount.Value)
+			sum.Add(sum, mustBigInt(coin.Amount.Value))
+		}
+		_, balance, _ := c.Balance(account, nil)
+		assert.Equal(t, balance.String(), sum.String(), "coins of %s add up to its balance", account)
+	}
+	assert.False(t, NewSimChain(SimConfig{Seed: 1, Blocks: 10}, ethCurrency).UTXO())
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// erc20TransferTopic is keccak256("Transfer(address,address,uint256)").
+	erc20TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// erc20BalanceOfSelector is the 4-byte selector of balanceOf(address).
+	erc20BalanceOfSelector = "0x70a08231"
+)
+
+// defaultTokens is used when neither MESH_TOKENS nor MESH_TOKENS_FILE is set.
+var defaultTokens = []Token{
+	{Address: "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238", Symbol: "USDC", Decimals: 6},
+}
+
+// Token describes an ERC-20 contract exposed as a Rosetta currency.
+type Token struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals int32  `json:"decimals"`
+}
+
+// Currency returns the Rosetta currency for the token. The contract address
+// travels in currency metadata so clients can tell apart tokens that share a
+// symbol.
+func (t Token) Currency() *types.Currency {
+	return &types.Currency{
+		Symbol:   t.Symbol,
+		Decimals: t.Decimals,
+		Metadata: map[string]interface{}{"contract_address": t.Address},
+	}
+}
+
+// TokenRegistry indexes the configured ERC-20 tokens by contract address.
+type TokenRegistry struct {
+	tokens []Token
+	byAddr map[string]Token
+}
+
+// NewTokenRegistry builds a registry from the given tokens, rejecting invalid
+// or duplicate contract addresses.
+func NewTokenRegistry(tokens []Token) (*TokenRegistry, error) {
+	r := &TokenRegistry{byAddr: map[string]Token{}}
+	for _, t := range tokens {
+		if !common.IsHexAddress(t.Address) {
+			return nil, fmt.Errorf("token %s: invalid contract address %q", t.Symbol, t.Address)
+		}
+		if t.Symbol == "" || t.Decimals < 0 {
+			return nil, fmt.Errorf("token %s: symbol and non-negative decimals are required", t.Address)
+		}
+		t.Address = common.HexToAddress(t.Address).Hex()
+		key := strings.ToLower(t.Address)
+		if _, dup := r.byAddr[key]; dup {
+			return nil, fmt.Errorf("token %s: duplicate contract address", t.Address)
+		}
+		r.byAddr[key] = t
+		r.tokens = append(r.tokens, t)
+	}
+	return r, nil
+}
+
+// LoadTokenRegistryFromEnv reads the token list from MESH_TOKENS (inline JSON)
+// or MESH_TOKENS_FILE (path to a JSON file), falling back to defaultTokens.
+// Both use the format [{"address": "0x..", "symbol": "USDC", "decimals": 6}].
+func LoadTokenRegistryFromEnv() (*TokenRegistry, error) {
+	raw := []byte(os.Getenv("MESH_TOKENS"))
+	if len(raw) == 0 {
+		if path := os.Getenv("MESH_TOKENS_FILE"); path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read token registry: %w", err)
+			}
+			raw = b
+		}
+	}
+	if len(raw) == 0 {
+		return NewTokenRegistry(defaultTokens)
+	}
+	var tokens []Token
+	if err := json.Unmarshal(raw, &tokens); err != nil {
+		return nil, fmt.Errorf("parse token registry: %w", err)
+	}
+	return NewTokenRegistry(tokens)
+}
+
+// envTokenRegistry loads the registry for service constructors, logging and
+// disabling token support when the configuration is invalid.
+func envTokenRegistry() *TokenRegistry {
+	r, err := LoadTokenRegistryFromEnv()
+	if err != nil {
+		log.Printf("Invalid token registry, ERC-20 support disabled: %v", err)
+		return nil
+	}
+	return r
+}
+
+// Tokens returns the registered tokens in configuration order.
+func (r *TokenRegistry) Tokens() []Token {
+	if r == nil {
+		return nil
+	}
+	return r.tokens
+}
+
+// Lookup returns the token registered at the given contract address.
+func (r *TokenRegistry) Lookup(address string) (Token, bool) {
+	if r == nil {
+		return Token{}, false
+	}
+	t, ok := r.byAddr[strings.ToLower(address)]
+	return t, ok
+}
+
+// ForCurrency resolves a requested Rosetta currency to a token, matching on
+// the contract_address metadata when present and on symbol and decimals
+// otherwise.
+func (r *TokenRegistry) ForCurrency(c *types.Currency) (Token, bool) {
+	if r == nil || c == nil {
+		return Token{}, false
+	}
+	if addr, ok := c.Metadata["contract_address"].(string); ok {
+		t, found := r.Lookup(addr)
+		return t, found && t.Symbol == c.Symbol && t.Decimals == c.Decimals
+	}
+	for _, t := range r.tokens {
+		if t.Symbol == c.Symbol && t.Decimals == c.Decimals {
+			return t, true
+		}
+	}
+	return Token{}, false
+}
+
+// balanceOfCallData encodes balanceOf(owner) for eth_call.
+func balanceOfCallData(owner string) string {
+	return erc20BalanceOfSelector + common.Bytes2Hex(common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32))
+}
+
+// tokenTransferOperations appends a Transfer debit/credit pair to ops for
+// every ERC-20 Transfer event in logs emitted by a registered token. Events
+// from unknown contracts, and ERC-721 transfers (which index the token id as a
+// fourth topic), are skipped.
+func tokenTransferOperations(ops []*types.Operation, logs []rpcLog, tokens *TokenRegistry, status *string) []*types.Operation {
+	for _, l := range logs {
+		if len(l.Topics) != 3 || !strings.EqualFold(l.Topics[0], erc20TransferTopic) {
+			continue
+		}
+		token, ok := tokens.Lookup(l.Address)
+		if !ok {
+			continue
+		}
+		value, ok := new(big.Int).SetString(strings.TrimPrefix(l.Data, "0x"), 16)
+		if !ok {
+			continue
+		}
+		from := strings.ToLower(common.HexToAddress(l.Topics[1]).Hex())
+		to := strings.ToLower(common.HexToAddress(l.Topics[2]).Hex())
+		logIndex, _ := hexToInt64(l.LogIndex)
+		ops = appendTransfer(ops, from, to, value, token.Currency(), status, map[string]interface{}{
+			"contract_address": token.Address,
+			"log_index":        logIndex,
+		})
+	}
+	return ops
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"
+
+func TestTokenRegistry_RejectsInvalidEntries(t *testing.T) {
+	_, err := NewTokenRegistry([]Token{{Address: "nope", Symbol: "X", Decimals: 1}})
+	assert.Error(t, err)
+
+	_, err = NewTokenRegistry([]Token{
+		{Address: testToken, Symbol: "USDC", Decimals: 6},
+		{Address: "0x1c7d4b196cb0c7b01d743fbc6116a902379c7238", Symbol: "USDC2", Decimals: 6},
+	})
+	assert.Error(t, err)
+}
+
+func TestLoadTokenRegistryFromEnv(t *testing.T) {
+	t.Setenv("MESH_TOKENS", `[{"address":"0x0000000000000000000000000000000000000001","symbol":"TKN","decimals":2}]`)
+	r, err := LoadTokenRegistryFromEnv()
+	require.NoError(t, err)
+	require.Len(t, r.Tokens(), 1)
+
+	tok, ok := r.ForCurrency(&types.Currency{Symbol: "TKN", Decimals: 2})
+	require.True(t, ok)
+	assert.Equal(t, "0x0000000000000000000000000000000000000001", tok.Address)
+
+	_, ok = r.ForCurrency(&types.Currency{Symbol: "TKN", Decimals: 2, Metadata: map[string]interface{}{"contract_address": testToken}})
+	assert.False(t, ok)
+}
+
+func TestTokenTransferOperations(t *testing.T) {
+	r, err := NewTokenRegistry([]Token{{Address: testToken, Symbol: "USDC", Decimals: 6}})
+	require.NoError(t, err)
+
+	logs := []rpcLog{
+		{
+			Address:  testToken,
+			Topics:   []string{erc20TransferTopic, "0x0000000000000000000000001111111111111111111111111111111111111111", "0x0000000000000000000000002222222222222222222222222222222222222222"},
+			Data:     "0x00000000000000000000000000000000000000000000000000000000000f4240",
+			LogIndex: "0x3",
+		},
+		// unregistered contract
+		{
+			Address: "0x0000000000000000000000000000000000000009",
+			Topics:  []string{erc20TransferTopic, "0x00", "0x00"},
+			Data:    "0x01",
+		},
+		// ERC-721 transfer from a registered address
+		{
+			Address: testToken,
+			Topics:  []string{erc20TransferTopic, "0x00", "0x00", "0x01"},
+		},
+	}
+
+	existing := transferOperations("0xaaa", "0xbbb", mustBig("1"), ethCurrency, strPtr("SUCCESS"))
+	ops := tokenTransferOperations(existing, logs, r, strPtr("SUCCESS"))
+	require.Len(t, ops, 4)
+
+	debit, credit := ops[2], ops[3]
+	assert.Equal(t, int64(2), debit.OperationIdentifier.Index)
+	assert.Equal(t, int64(2), credit.RelatedOperations[0].Index)
+	assert.Equal(t, "0x1111111111111111111111111111111111111111", debit.Account.Address)
+	assert.Equal(t, "-1000000", debit.Amount.Value)
+	assert.Equal(t, "1000000", credit.Amount.Value)
+	assert.Equal(t, testToken, credit.Amount.Currency.Metadata["contract_address"])
+	assert.Equal(t, int64(3), credit.Metadata["log_index"])
+}
+
+func TestAccountBalance_TokenBalanceOf(t *testing.T) {
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getBlockByNumber": map[string]interface{}{"number": "0x10", "hash": "0xblock"},
+		"eth_getBalance":       "0x2",
+		"eth_call":             "0x00000000000000000000000000000000000000000000000000000000000f4240",
+	}, nil)
+	s := NewAccountAPIService(testNetworks(t, rpc, Token{Address: testToken, Symbol: "USDC", Decimals: 6}))
+
+	resp, rErr := s.AccountBalance(context.Background(), &types.AccountBalanceRequest{
+		NetworkIdentifier: sepolia,
+		AccountIdentifier: &types.AccountIdentifier{Address: "0x1111111111111111111111111111111111111111"},
+	})
+	require.Nil(t, rErr)
+	assert.Equal(t, int64(16), resp.BlockIdentifier.Index)
+	require.Len(t, resp.Balances, 2)
+	assert.Equal(t, "2", resp.Balances[0].Value)
+	assert.Equal(t, "1000000", resp.Balances[1].Value)
+	assert.Equal(t, "USDC", resp.Balances[1].Currency.Symbol)
+
+	_, rErr = s.AccountBalance(context.Background(), &types.AccountBalanceRequest{
+		NetworkIdentifier: sepolia,
+		AccountIdentifier: &types.AccountIdentifier{Address: "0x1111111111111111111111111111111111111111"},
+		Currencies:        []*types.Currency{{Symbol: "DAI", Decimals: 18}},
+	})
+	require.NotNil(t, rErr)
+	assert.Equal(t, ErrUnsupportedCurrency.Code, rErr.Code)
+}
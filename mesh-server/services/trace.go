@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Trace modes select how internal value transfers are fetched.
+const (
+	// TraceOff reports top-level transaction value only.
+	TraceOff = ""
+	// TraceDebug uses debug_traceBlockByNumber with geth's callTracer.
+	TraceDebug = "debug"
+	// TraceParity uses trace_block (Erigon, Nethermind, OpenEthereum).
+	TraceParity = "parity"
+)
+
+// internalTransfer is a value movement made by contract code: a nested CALL
+// or CREATE with value, or the balance swept by SELFDESTRUCT.
+type internalTransfer struct {
+	From  string
+	To    string
+	Value *big.Int
+	Kind  string // CALL, CREATE, CREATE2 or SELFDESTRUCT
+}
+
+// callFrame is one frame of callTracer output.
+type callFrame struct {
+	Type  string      `json:"type"`
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Value string      `json:"value"`
+	Error string      `json:"error"`
+	Calls []callFrame `json:"calls"`
+}
+
+type debugTraceResult struct {
+	TxHash string    `json:"txHash"`
+	Result callFrame `json:"result"`
+}
+
+// parityTrace is one entry of trace_block / trace_transaction output.
+type parityTrace struct {
+	Type   string `json:"type"`
+	Action struct {
+		CallType      string `json:"callType"`
+		From          string `json:"from"`
+		To            string `json:"to"`
+		Value         string `json:"value"`
+		Address       string `json:"address"`
+		RefundAddress string `json:"refundAddress"`
+		Balance       string `json:"balance"`
+	} `json:"action"`
+	Result *struct {
+		Address string `json:"address"`
+	} `json:"result"`
+	Error           string `json:"error"`
+	TraceAddress    []int  `json:"traceAddress"`
+	TransactionHash string `json:"transactionHash"`
+}
+
+// blockInternalTransfers returns the internal transfers of every transaction
+// in the block, keyed by lower-cased transaction hash. It returns nil when
+// tracing is disabled for the network.
+func blockInternalTransfers(ctx context.Context, n *Network, blk *rpcBlock, txs []rpcTx) (map[string][]internalTransfer, error) {
+	out := map[string][]internalTransfer{}
+	switch n.Trace {
+	case TraceOff:
+		return nil, nil
+	case TraceDebug:
+		var results []debugTraceResult
+		params := []interface{}{blk.Number, map[string]interface{}{"tracer": "callTracer"}}
+		if err := n.RPC.call(ctx, "debug_traceBlockByNumber", params, &results); err != nil {
+			// Providers often refuse block-wide traces; trace each
+			// transaction instead.
+			roots, txErr := transactionTraces(ctx, n, txs)
+			if txErr != nil {
+				return nil, fmt.Errorf("debug_traceBlockByNumber: %v; debug_traceTransaction: %w", err, txErr)
+			}
+			for i, root := range roots {
+				out[strings.ToLower(txs[i].Hash)] = frameTransfers(root)
+			}
+			return out, nil
+		}
+		if len(results) != len(txs) {
+			return nil, fmt.Errorf("debug_traceBlockByNumber returned %d traces for %d transactions", len(results), len(txs))
+		}
+		for i := range results {
+			out[strings.ToLower(txs[i].Hash)] = frameTransfers(results[i].Result)
+		}
+	case TraceParity:
+		var traces []parityTrace
+		if err := n.RPC.call(ctx, "trace_block", []interface{}{blk.Number}, &traces); err != nil {
+			return nil, fmt.Errorf("trace_block: %w", err)
+		}
+		for hash, transfers := range parityTransfers(traces) {
+			out[hash] = transfers
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace mode %q", n.Trace)
+	}
+	return out, nil
+}
+
+// transactionTraces fetches the callTracer trace of every transaction in one
+// batched round of debug_traceTransaction calls.
+func transactionTraces(ctx context.Context, n *Network, txs []rpcTx) ([]callFrame, error) {
+	roots := make([]callFrame, len(txs))
+	calls := make([]*rpcCall, len(txs))
+	for i := range txs {
+		params := []interface{}{txs[i].Hash, map[string]interface{}{"tracer": "callTracer"}}
+		calls[i] = &rpcCall{Method: "debug_traceTransaction", Params: params, Out: &roots[i]}
+	}
+	if err := n.RPC.batch(ctx, calls); err != nil {
+		return nil, err
+	}
+	for _, call := range calls {
+		if call.Err != nil {
+			return nil, call.Err
+		}
+	}
+	return roots, nil
+}
+
+// transactionInternalTransfers is the single-transaction form of
+// blockInternalTransfers, used by /block/transaction.
+func transactionInternalTransfers(ctx context.Context, n *Network, txHash string) ([]internalTransfer, error) {
+	switch n.Trace {
+	case TraceOff:
+		return nil, nil
+	case TraceDebug:
+		var root callFrame
+		params := []interface{}{txHash, map[string]interface{}{"tracer": "callTracer"}}
+		if err := n.RPC.call(ctx, "debug_traceTransaction", params, &root); err != nil {
+			return nil, fmt.Errorf("debug_traceTransaction: %w", err)
+		}
+		return frameTransfers(root), nil
+	case TraceParity:
+		var traces []parityTrace
+		if err := n.RPC.call(ctx, "trace_transaction", []interface{}{txHash}, &traces); err != nil {
+			return nil, fmt.Errorf("trace_transaction: %w", err)
+		}
+		return parityTransfers(traces)[strings.ToLower(txHash)], nil
+	default:
+		return nil, fmt.Errorf("unknown trace mode %q", n.Trace)
+	}
+}
+
+// frameTransfers walks the callTracer tree below the top-level frame, whose
+// value is already covered by the transaction itself. Frames that reverted
+// are skipped along with everything beneath them, since none of their value
+// movements persisted.
+func frameTransfers(root callFrame) []internalTransfer {
+	if root.Error != "" {
+		return nil
+	}
+	var out []internalTransfer
+	var walk func(frames []callFrame)
+	walk = func(frames []callFrame) {
+		for _, f := range frames {
+			if f.Error != "" {
+				continue
+			}
+			kind := strings.ToUpper(f.Type)
+			switch kind {
+			case "CALL", "CREATE", "CREATE2", "SELFDESTRUCT":
+				// DELEGATECALL, STATICCALL and CALLCODE never move value
+				// between accounts.
+				if value, err := hexToBigInt(f.Value); err == nil && value.Sign() > 0 && f.To != "" {
+					out = append(out, internalTransfer{From: f.From, To: f.To, Value: value, Kind: kind})
+				}
+			}
+			walk(f.Calls)
+		}
+	}
+	walk(root.Calls)
+	return out
+}
+
+// parityTransfers groups trace_block output by transaction, dropping the
+// top-level trace of each transaction and every trace under a failed one.
+func parityTransfers(traces []parityTrace) map[string][]internalTransfer {
+	out := map[string][]internalTransfer{}
+	failed := map[string][][]int{}
+	for _, t := range traces {
+		if t.TransactionHash == "" {
+			// block and uncle rewards have no transaction
+			continue
+		}
+		hash := strings.ToLower(t.TransactionHash)
+		if t.Error != "" {
+			failed[hash] = append(failed[hash], t.TraceAddress)
+			continue
+		}
+		if len(t.TraceAddress) == 0 || underFailed(t.TraceAddress, failed[hash]) {
+			continue
+		}
+
+		var transfer internalTransfer
+		switch t.Type {
+		case "call":
+			if t.Action.CallType != "call" {
+				continue
+			}
+			transfer = internalTransfer{From: t.Action.From, To: t.Action.To, Kind: "CALL"}
+			transfer.Value, _ = hexToBigInt(t.Action.Value)
+		case "create":
+			if t.Result == nil {
+				continue
+			}
+			transfer = internalTransfer{From: t.Action.From, To: t.Result.Address, Kind: "CREATE"}
+			transfer.Value, _ = hexToBigInt(t.Action.Value)
+		case "suicide":
+			transfer = internalTransfer{From: t.Action.Address, To: t.Action.RefundAddress, Kind: "SELFDESTRUCT"}
+			transfer.Value, _ = hexToBigInt(t.Action.Balance)
+		default:
+			continue
+		}
+		if transfer.Value.Sign() > 0 && transfer.To != "" {
+			out[hash] = append(out[hash], transfer)
+		}
+	}
+	return out
+}
+
+// underFailed reports whether addr is a descendant of any failed trace.
+// Parents precede children in trace output, so failures are always known
+// before their subtraces are visited.
+func underFailed(addr []int, failed [][]int) bool {
+	for _, f := range failed {
+		if len(f) >= len(addr) {
+			continue
+		}
+		match := true
+		for i := range f {
+			if f[i] != addr[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summarize(transfers []internalTransfer) []string {
+	out := []string{}
+	for _, t := range transfers {
+		out = append(out, t.Kind+" "+t.From+">"+t.To+" "+t.Value.String())
+	}
+	return out
+}
+
+func TestFrameTransfers(t *testing.T) {
+	var root callFrame
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "CALL", "from": "0xeoa", "to": "0xa", "value": "0x10",
+		"calls": [
+			{"type": "CALL", "from": "0xa", "to": "0xb", "value": "0x5",
+			 "calls": [{"type": "SELFDESTRUCT", "from": "0xb", "to": "0xc", "value": "0x7"}]},
+			{"type": "DELEGATECALL", "from": "0xa", "to": "0xd", "value": "0x5"},
+			{"type": "STATICCALL", "from": "0xa", "to": "0xd"},
+			{"type": "CALL", "from": "0xa", "to": "0xe", "value": "0x0"},
+			{"type": "CALL", "from": "0xa", "to": "0xf", "value": "0x3", "error": "execution reverted",
+			 "calls": [{"type": "CALL", "from": "0xf", "to": "0xg", "value": "0x1"}]},
+			{"type": "CREATE2", "from": "0xa", "to": "0xnew", "value": "0x2"}
+		]
+	}`), &root))
+
+	assert.Equal(t, []string{
+		"CALL 0xa>0xb 5",
+		"SELFDESTRUCT 0xb>0xc 7",
+		"CREATE2 0xa>0xnew 2",
+	}, summarize(frameTransfers(root)))
+}
+
+func TestParityTransfers(t *testing.T) {
+	var traces []parityTrace
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"type": "call", "action": {"callType": "call", "from": "0xeoa", "to": "0xa", "value": "0x10"}, "traceAddress": [], "transactionHash": "0xT"},
+		{"type": "call", "action": {"callType": "call", "from": "0xa", "to": "0xb", "value": "0x5"}, "traceAddress": [0], "transactionHash": "0xT"},
+		{"type": "call", "action": {"callType": "delegatecall", "from": "0xa", "to": "0xd", "value": "0x5"}, "traceAddress": [1], "transactionHash": "0xT"},
+		{"type": "call", "action": {"callType": "call", "from": "0xa", "to": "0xf", "value": "0x3"}, "error": "Reverted", "traceAddress": [2], "transactionHash": "0xT"},
+		{"type": "call", "action": {"callType": "call", "from": "0xf", "to": "0xg", "value": "0x1"}, "traceAddress": [2, 0], "transactionHash": "0xT"},
+		{"type": "create", "action": {"from": "0xa", "value": "0x2"}, "result": {"address": "0xnew"}, "traceAddress": [3], "transactionHash": "0xT"},
+		{"type": "suicide", "action": {"address": "0xb", "refundAddress": "0xc", "balance": "0x7"}, "traceAddress": [4], "transactionHash": "0xT"},
+		{"type": "reward", "action": {"author": "0xminer", "value": "0x1"}, "traceAddress": []}
+	]`), &traces))
+
+	assert.Equal(t, []string{
+		"CALL 0xa>0xb 5",
+		"CREATE 0xa>0xnew 2",
+		"SELFDESTRUCT 0xb>0xc 7",
+	}, summarize(parityTransfers(traces)["0xt"]))
+}
+
+func TestBlock_DebugTraceAddsInternalTransfers(t *testing.T) {
+	blk := liveBlock()
+	blk["transactions"] = blk["transactions"].([]interface{})[:1]
+	rpc := newStubRPC(t, map[string]interface{}{
+		"eth_getBlockByNumber": blk,
+		"eth_getBlockReceipts": liveReceipts()[:1],
+		"debug_traceBlockByNumber": []interface{}{
+			map[string]interface{}{"txHash": "0xok", "result": map[string]interface{}{
+				"type": "CALL", "from": testSender, "to": testRecip, "value": "0x64",
+				"calls": []interface{}{
+					map[string]interface{}{"type": "CALL", "from": testRecip, "to": testMiner, "value": "0x20"},
+				},
+			}},
+		},
+	}, nil)
+	networks := testNetworks(t, rpc)
+	n, _ := networks.Lookup(sepolia)
+	n.Trace = TraceDebug
+	s := NewBlockAPIService(networks)
+
+	idx := int64(16)
+	resp, rErr := s.Block(context.Background(), &types.BlockRequest{
+		NetworkIdentifier: sepolia,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: &idx},
+	})
+	require.Nil(t, rErr)
+	ops := resp.Block.Transactions[0].Operations
+	assert.Equal(t, [][3]string{
+		{"Transfer", "SUCCESS", testSender + " -100"},
+		{"Transfer", "SUCCESS", testRecip + " 100"},
+		{"Transfer", "SUCCESS", testRecip + " -32"},
+		{"Transfer", "SUCCESS", testMiner + " 32"},
+	}, opSummary(ops)[:4])
+	assert.Equal(t, "CALL", ops[3].Metadata["trace_type"])
+}
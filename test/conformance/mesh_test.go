@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"math/big"
 	"net/http"
 	"testing"
 	"time"
@@ -144,6 +145,91 @@ func TestAccountBalance(t *testing.T) {
 	}
 }
 
+// TestAccountCoins tests /account/coins against the model each network
+// declares in /network/options: account-based networks must reject it with
+// an error listed in their options, UTXO networks must serve coins adding up
+// to the account's balance.
+func TestAccountCoins(t *testing.T) {
+	suite := NewMeshTestSuite("")
+
+	var list struct {
+		NetworkIdentifiers []map[string]interface{} `json:"network_identifiers"`
+	}
+	suite.decode(t, "/network/list", map[string]interface{}{}, http.StatusOK, &list)
+	require.NotEmpty(t, list.NetworkIdentifiers)
+
+	for _, network := range list.NetworkIdentifiers {
+		t.Run(network["blockchain"].(string)+"/"+network["network"].(string), func(t *testing.T) {
+			var options struct {
+				Version struct {
+					Metadata map[string]interface{} `json:"metadata"`
+				} `json:"version"`
+				Allow struct {
+					Errors []map[string]interface{} `json:"errors"`
+				} `json:"allow"`
+			}
+			suite.decode(t, "/network/options", map[string]interface{}{"network_identifier": network}, http.StatusOK, &options)
+			model, _ := options.Version.Metadata["model"].(string)
+
+			// an account from the genesis block, which funds accounts on
+			// every network
+			var genesis struct {
+				Block struct {
+					Transactions []struct {
+						Operations []struct {
+							Account map[string]interface{} `json:"account"`
+						} `json:"operations"`
+					} `json:"transactions"`
+				} `json:"block"`
+			}
+			suite.decode(t, "/block", map[string]interface{}{"network_identifier": network, "block_identifier": map[string]interface{}{"index": 0}}, http.StatusOK, &genesis)
+			account := map[string]interface{}{"address": "0x1234567890abcdef1234567890abcdef12345678"}
+			if len(genesis.Block.Transactions) > 0 && len(genesis.Block.Transactions[0].Operations) > 0 {
+				account = genesis.Block.Transactions[0].Operations[0].Account
+			}
+			payload := map[string]interface{}{"network_identifier": network, "account_identifier": account}
+
+			switch model {
+			case "utxo":
+				var coins struct {
+					Coins []struct {
+						CoinIdentifier map[string]interface{} `json:"coin_identifier"`
+						Amount         struct {
+							Value string `json:"value"`
+						} `json:"amount"`
+					} `json:"coins"`
+				}
+				suite.decode(t, "/account/coins", payload, http.StatusOK, &coins)
+				sum := new(big.Int)
+				for _, coin := range coins.Coins {
+					assert.NotEmpty(t, coin.CoinIdentifier["identifier"])
+					v, ok := new(big.Int).SetString(coin.Amount.Value, 10)
+					require.True(t, ok)
+					sum.Add(sum, v)
+				}
+				var balance struct {
+					Balances []struct {
+						Value string `json:"value"`
+					} `json:"balances"`
+				}
+				suite.decode(t, "/account/balance", payload, http.StatusOK, &balance)
+				require.NotEmpty(t, balance.Balances)
+				assert.Equal(t, balance.Balances[0].Value, sum.String(), "coins add up to the balance")
+			default:
+				assert.Equal(t, "account", model, "networks declare their model")
+				var rosettaErr map[string]interface{}
+				suite.decode(t, "/account/coins", payload, http.StatusInternalServerError, &rosettaErr)
+				listed := false
+				for _, e := range options.Allow.Errors {
+					listed = listed || (e["code"] == rosettaErr["code"] && e["message"] == rosettaErr["message"])
+				}
+				assert.True(t, listed, "error %v must be listed in /network/options", rosettaErr)
+				assert.Equal(t, false, rosettaErr["retriable"])
+			}
+		})
+	}
+}
+
 // TestBlock tests the /block endpoint
 func TestBlock(t *testing.T) {
 	suite := NewMeshTestSuite("")
@@ -242,6 +328,17 @@ func (s *MeshTestSuite) post(endpoint string, payload interface{}) (*http.Respon
 	return s.httpClient.Do(req)
 }
 
+// decode posts payload to endpoint, checks the status code and decodes the
+// response into out
+func (s *MeshTestSuite) decode(t *testing.T, endpoint string, payload interface{}, status int, out interface{}) {
+	t.Helper()
+	resp, err := s.post(endpoint, payload)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, status, resp.StatusCode, "unexpected status from %s", endpoint)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+}
+
 // TestMeshAPIConformance runs all conformance tests
 func TestMeshAPIConformance(t *testing.T) {
 	// Skip if not in integration test mode
@@ -253,6 +350,7 @@ func TestMeshAPIConformance(t *testing.T) {
 	t.Run("NetworkStatus", TestNetworkStatus)
 	t.Run("NetworkOptions", TestNetworkOptions)
 	t.Run("AccountBalance", TestAccountBalance)
+	t.Run("AccountCoins", TestAccountCoins)
 	t.Run("Block", TestBlock)
 	t.Run("MalformedRequests", TestMalformedRequests)
 }
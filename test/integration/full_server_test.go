@@ -99,7 +99,7 @@ func startFullServerWithSDKFlag(t *testing.T, meshBaseURL string, useMeshSDK boo
     cfg.APIKey = "" // No API key for tests
 
     // Create mesh client based on flag
-    var meshClient clients.MeshAPI
+    var meshClient clients.MeshAPIV2
     if cfg.MeshUseSDK {
         meshClient = clients.NewMeshSDKClient(meshBaseURL)
     } else {
@@ -111,7 +111,7 @@ func startFullServerWithSDKFlag(t *testing.T, meshBaseURL string, useMeshSDK boo
 
     // Create service and router (coinbase and overledger adapters are nil for this test)
     service := connector.NewService(nil, meshAdapter, nil)
-    router := api.SetupRouter(service, cfg)
+    router := api.SetupRouter(service, cfg, loadMeshNetworks(t))
 
     return httptest.NewServer(router)
 }
@@ -0,0 +1,188 @@
+package integration
+
+import (
+    "bytes"
+    "encoding/hex"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    meshadapter "github.com/rutishh0/testingquant/internal/adapters/mesh"
+    "github.com/rutishh0/testingquant/internal/api"
+    "github.com/rutishh0/testingquant/internal/clients"
+    "github.com/rutishh0/testingquant/internal/config"
+    "github.com/rutishh0/testingquant/internal/connector"
+    "github.com/rutishh0/mesh-server/services"
+)
+
+const gatewayAPIKey = "gateway-secret"
+
+var sepoliaNetwork = map[string]any{"blockchain": "Ethereum", "network": "Sepolia"}
+
+// startGatewayServer runs the full router with an API key, its /v1/mesh gateway
+// pointed at the router's own embedded /mesh services in mock mode.
+func startGatewayServer(t *testing.T) *httptest.Server {
+    t.Helper()
+    for _, env := range []string{"MAINNET_RPC_URL", "SEPOLIA_RPC_URL", "HOLESKY_RPC_URL", "AMOY_RPC_URL", "MESH_NETWORKS_FILE"} {
+        t.Setenv(env, "")
+    }
+    t.Setenv("MESH_MODE", "mock")
+    gin.SetMode(gin.TestMode)
+
+    meshClient := clients.NewMeshClient("")
+    meshClient.APIKey = gatewayAPIKey
+    svc := connector.NewService(nil, meshadapter.NewAdapter(meshClient), nil)
+    srv := httptest.NewServer(api.SetupRouter(svc, &config.Config{APIKey: gatewayAPIKey, Environment: "test"}, loadMeshNetworks(t)))
+    meshClient.BaseURL = srv.URL + "/mesh"
+    return srv
+}
+
+// loadMeshNetworks loads the networks the router serves at /mesh from the
+// environment, as cmd/main.go does.
+func loadMeshNetworks(t *testing.T) *services.Networks {
+    t.Helper()
+    networks, err := services.LoadNetworksFromEnv()
+    require.NoError(t, err)
+    return networks
+}
+
+func gatewayRequest(t *testing.T, method, url, apiKey string, payload any) (int, map[string]interface{}) {
+    t.Helper()
+    var body bytes.Buffer
+    if payload != nil {
+        require.NoError(t, json.NewEncoder(&body).Encode(payload))
+    }
+    req, err := http.NewRequest(method, url, &body)
+    require.NoError(t, err)
+    req.Header.Set("Content-Type", "application/json")
+    if apiKey != "" {
+        req.Header.Set("X-API-Key", apiKey)
+    }
+
+    resp, err := httpClient().Do(req)
+    require.NoError(t, err)
+    defer resp.Body.Close()
+
+    var out map[string]interface{}
+    _ = json.NewDecoder(resp.Body).Decode(&out)
+    return resp.StatusCode, out
+}
+
+func TestMeshGateway_RequiresAPIKey(t *testing.T) {
+    srv := startGatewayServer(t)
+    defer srv.Close()
+
+    for _, path := range []string{"/v1/mesh/network/status", "/v1/mesh/mempool", "/v1/mesh/construction/metadata"} {
+        status, body := gatewayRequest(t, http.MethodPost, srv.URL+path, "", map[string]any{"network_identifier": sepoliaNetwork})
+        assert.Equal(t, http.StatusUnauthorized, status, path)
+        assert.Equal(t, "unauthorized", body["error"], path)
+    }
+    status, _ := gatewayRequest(t, http.MethodGet, srv.URL+"/v1/mesh/networks/Ethereum/Sepolia/block/latest", "wrong", nil)
+    assert.Equal(t, http.StatusUnauthorized, status)
+
+    // The raw Rosetta data endpoints stay public; construction, mempool and
+    // call need the key
+    status, _ = gatewayRequest(t, http.MethodPost, srv.URL+"/mesh/network/status", "", map[string]any{"network_identifier": sepoliaNetwork})
+    assert.Equal(t, http.StatusOK, status)
+    for _, path := range []string{"/mesh/construction/submit", "/mesh/construction/metadata", "/mesh/mempool", "/mesh/call", "/mesh//construction/submit"} {
+        status, _ := gatewayRequest(t, http.MethodPost, srv.URL+path, "", map[string]any{"network_identifier": sepoliaNetwork})
+        assert.Equal(t, http.StatusUnauthorized, status, path)
+    }
+    status, _ = gatewayRequest(t, http.MethodPost, srv.URL+"/mesh/mempool", gatewayAPIKey, map[string]any{"network_identifier": sepoliaNetwork})
+    assert.Equal(t, http.StatusOK, status)
+}
+
+func TestMeshGateway_Endpoints(t *testing.T) {
+    srv := startGatewayServer(t)
+    defer srv.Close()
+    post := func(path string, payload any) (int, map[string]interface{}) {
+        return gatewayRequest(t, http.MethodPost, srv.URL+"/v1/mesh"+path, gatewayAPIKey, payload)
+    }
+    network := map[string]any{"network_identifier": sepoliaNetwork}
+
+    t.Run("network status and options", func(t *testing.T) {
+        status, body := post("/network/status", network)
+        require.Equal(t, http.StatusOK, status, body)
+        assert.NotNil(t, body["current_block_identifier"])
+
+        status, body = post("/network/options", network)
+        require.Equal(t, http.StatusOK, status, body)
+        assert.NotNil(t, body["allow"])
+    })
+
+    t.Run("mempool", func(t *testing.T) {
+        status, body := post("/mempool", network)
+        require.Equal(t, http.StatusOK, status, body)
+        assert.Contains(t, body, "transaction_identifiers")
+    })
+
+    t.Run("latest block", func(t *testing.T) {
+        status, body := gatewayRequest(t, http.MethodGet, srv.URL+"/v1/mesh/networks/Ethereum/Sepolia/block/latest", gatewayAPIKey, nil)
+        require.Equal(t, http.StatusOK, status, body)
+        block, ok := body["block"].(map[string]interface{})
+        require.True(t, ok, "block missing: %v", body)
+        assert.NotNil(t, block["block_identifier"])
+    })
+
+    t.Run("balance by address", func(t *testing.T) {
+        status, body := gatewayRequest(t, http.MethodGet, srv.URL+"/v1/mesh/networks/Ethereum/Sepolia/accounts/0x742d35Cc6634C0532925a3b844Bc454e4438f44e/balance", gatewayAPIKey, nil)
+        require.Equal(t, http.StatusOK, status, body)
+        assert.NotEmpty(t, body["balances"])
+    })
+
+    t.Run("construction derive", func(t *testing.T) {
+        key, err := crypto.GenerateKey()
+        require.NoError(t, err)
+        status, body := post("/construction/derive", map[string]any{
+            "network_identifier": sepoliaNetwork,
+            "public_key": map[string]any{
+                "hex_bytes":  hex.EncodeToString(crypto.CompressPubkey(&key.PublicKey)),
+                "curve_type": "secp256k1",
+            },
+        })
+        require.Equal(t, http.StatusOK, status, body)
+        account, _ := body["account_identifier"].(map[string]interface{})
+        assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey).Hex(), account["address"])
+    })
+
+    t.Run("account coins unsupported", func(t *testing.T) {
+        status, body := post("/account/coins", map[string]any{
+            "network_identifier": sepoliaNetwork,
+            "account_identifier": map[string]any{"address": "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"},
+            "include_mempool":    false,
+        })
+        require.Equal(t, http.StatusUnprocessableEntity, status, body)
+        assert.Equal(t, "mesh_coins_failed", body["error"])
+        assert.Equal(t, float64(http.StatusUnprocessableEntity), body["code"])
+        meshErr, ok := body["mesh_error"].(map[string]interface{})
+        require.True(t, ok, "mesh_error missing: %v", body)
+        assert.Equal(t, float64(9), meshErr["code"])
+    })
+
+    t.Run("invalid requests", func(t *testing.T) {
+        status, body := post("/construction/submit", network)
+        assert.Equal(t, http.StatusBadRequest, status)
+        assert.Equal(t, "invalid_request", body["error"])
+
+        status, _ = post("/network/status", map[string]any{})
+        assert.Equal(t, http.StatusBadRequest, status)
+    })
+}
+
+func TestMeshGateway_NotConfigured(t *testing.T) {
+    handlers := api.NewHandlers(connector.NewService(nil, nil, nil), &config.Config{})
+    r := gin.New()
+    r.POST("/v1/mesh/network/status", handlers.GetMeshNetworkStatus)
+    srv := httptest.NewServer(r)
+    defer srv.Close()
+
+    status, body := gatewayRequest(t, http.MethodPost, srv.URL+"/v1/mesh/network/status", "", map[string]any{"network_identifier": sepoliaNetwork})
+    assert.Equal(t, http.StatusServiceUnavailable, status)
+    assert.Equal(t, "mesh_network_status_failed", body["error"])
+}
@@ -3,6 +3,7 @@ package integration
 import (
     "bytes"
     "encoding/json"
+    "math/big"
     "net/http"
     "net/http/httptest"
     "testing"
@@ -43,10 +44,22 @@ func startMockRosettaServer(t *testing.T) *httptest.Server {
     )
     require.NoError(t, err)
 
-    // Underlying mock services remain initialized for Ethereum Sepolia, which is sufficient for our tests
-    networkAPIService := meshservices.NewNetworkAPIService(ethSepolia, nil)
-    blockAPIService := meshservices.NewBlockAPIService(ethSepolia, nil)
-    accountAPIService := meshservices.NewAccountAPIService(ethSepolia, nil)
+    // Both networks are served from their simulated chains (no RPC); Bitcoin Testnet as a UTXO chain
+    networks, err := meshservices.NewNetworks(&meshservices.Network{
+        Identifier: ethSepolia,
+        ChainID:    big.NewInt(11155111),
+        Currency:   &rotypes.Currency{Symbol: "ETH", Decimals: 18},
+    }, &meshservices.Network{
+        Identifier: btcTestnet,
+        ChainID:    big.NewInt(18332), // Bitcoin has no chain ID; this only seeds the simulated chain
+        Currency:   &rotypes.Currency{Symbol: "BTC", Decimals: 8},
+        Mode:       meshservices.ModeMock,
+        Model:      meshservices.ModelUTXO,
+    })
+    require.NoError(t, err)
+    networkAPIService := meshservices.NewNetworkAPIService(networks)
+    blockAPIService := meshservices.NewBlockAPIService(networks)
+    accountAPIService := meshservices.NewAccountAPIService(networks)
 
     networkAPIController := roserver.NewNetworkAPIController(networkAPIService, assr)
     blockAPIController := roserver.NewBlockAPIController(blockAPIService, assr)
@@ -58,7 +71,7 @@ func startMockRosettaServer(t *testing.T) *httptest.Server {
 }
 
 // startConnectorServer spins up a minimal connector server exposing only /v1/mesh routes, using the provided Mesh client implementation
-func startConnectorServer(t *testing.T, meshClient clients.MeshAPI) *httptest.Server {
+func startConnectorServer(t *testing.T, meshClient clients.MeshAPIV2) *httptest.Server {
     t.Helper()
 
     // Build service with our mesh adapter
@@ -0,0 +1,587 @@
+package integration
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rutishh0/testingquant/internal/api"
+	"github.com/rutishh0/testingquant/internal/config"
+	"github.com/rutishh0/testingquant/internal/connector"
+	"github.com/rutishh0/testingquant/internal/overledger"
+)
+
+// overledgerRequest is a request received by the recording stand-in.
+type overledgerRequest struct {
+	Path    string
+	Header  http.Header
+	Body    map[string]interface{}
+	RawForm string
+}
+
+// startRecordingOverledgerServer serves the token, preparation, signing and
+// execution endpoints under prefix and records every request.
+func startRecordingOverledgerServer(t *testing.T, prefix string) (*httptest.Server, func() []overledgerRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []overledgerRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := overledgerRequest{Path: r.URL.Path, Header: r.Header.Clone()}
+		if r.URL.Path == "/oauth2/token" {
+			require.NoError(t, r.ParseForm())
+			rec.RawForm = r.PostForm.Encode()
+		} else {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&rec.Body))
+		}
+		mu.Lock()
+		requests = append(requests, rec)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth2/token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "stand-in-token", "expires_in": 3600})
+		case prefix + "/preparation/transaction":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"preparationTransactionSearchResponse": map[string]interface{}{"requestId": "{0f9d4b5e-5a6c-4f0e-9b55-3c9c1a0d7e21}"},
+			})
+		case "/api/transaction-signing-sandbox":
+			json.NewEncoder(w).Encode(map[string]interface{}{"signed": "0x02f873aa36a7"})
+		case prefix + "/execution/transaction":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"executionTransactionSearchResponse": map[string]interface{}{
+					"transactionId": "0xfeed",
+					"status":        map[string]interface{}{"value": "PENDING", "code": "TXN1001", "description": "submitted"},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv, func() []overledgerRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]overledgerRequest(nil), requests...)
+	}
+}
+
+func TestOverledgerCreateTransaction_UsesConfiguredPipeline(t *testing.T) {
+	srv, recorded := startRecordingOverledgerServer(t, "/v2")
+	defer srv.Close()
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerClientID:     "client-id",
+		OverledgerClientSecret: "client-secret",
+		OverledgerAuthURL:      srv.URL + "/oauth2/token",
+		OverledgerBaseURL:      srv.URL,
+	})
+	nonce := 7
+	resp, err := client.CreateTransaction(&overledger.TransactionRequest{
+		NetworkID:    "polygon-amoy",
+		FromAddress:  "0x1234567890abcdef1234567890abcdef12345678",
+		ToAddress:    "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:               "0.25",
+		GasLimit:             "21000",
+		MaxFeePerGas:         "30000000000",
+		MaxPriorityFeePerGas: "1500000000",
+		Nonce:                &nonce,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0xfeed", resp.Hash)
+	assert.Equal(t, "PENDING", resp.Status)
+	assert.Equal(t, "0.00063", resp.Fee)
+
+	requests := recorded()
+	require.Len(t, requests, 4)
+	paths := []string{requests[0].Path, requests[1].Path, requests[2].Path, requests[3].Path}
+	assert.Equal(t, []string{"/oauth2/token", "/v2/preparation/transaction", "/api/transaction-signing-sandbox", "/v2/execution/transaction"}, paths)
+
+	token := requests[0]
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("client-id:client-secret")), token.Header.Get("Authorization"))
+	assert.Equal(t, "grant_type=client_credentials", token.RawForm)
+	for _, r := range requests[1:] {
+		assert.Equal(t, "Bearer stand-in-token", r.Header.Get("Authorization"), r.Path)
+	}
+
+	prepare := requests[1].Body
+	assert.Equal(t, map[string]interface{}{"technology": "ethereum", "network": "polygon amoy testnet"}, prepare["location"])
+	destination := prepare["requestDetails"].(map[string]interface{})["destination"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"amount": "0.25", "unit": "MATIC"}, destination["payment"])
+
+	sign := requests[2]
+	assert.Equal(t, "3.0.0", sign.Header.Get("API-Version"))
+	assert.Empty(t, requests[1].Header.Get("API-Version"))
+	assert.Equal(t, "0x1234567890abcdef1234567890abcdef12345678", sign.Body["keyId"])
+	assert.Equal(t, "0f9d4b5e-5a6c-4f0e-9b55-3c9c1a0d7e21", sign.Body["requestId"])
+	assert.Equal(t, map[string]interface{}{"amount": "0.00063", "unit": "MATIC"}, sign.Body["dltFee"])
+	native := sign.Body["nativeData"].(map[string]interface{})
+	assert.Equal(t, float64(80002), native["chainId"])
+	assert.Equal(t, "testnet", native["chain"])
+	assert.Equal(t, "250000000000000000", native["value"])
+	assert.Equal(t, "21000", native["gas"])
+	assert.Equal(t, "30000000000", native["maxFeePerGas"])
+	assert.Equal(t, float64(7), native["nonce"])
+	assert.Equal(t, "0xabcdef1234567890abcdef1234567890abcdef12", native["to"])
+
+	assert.Equal(t, map[string]interface{}{"signed": "0x02f873aa36a7", "requestId": "0f9d4b5e-5a6c-4f0e-9b55-3c9c1a0d7e21"}, requests[3].Body)
+}
+
+func TestOverledgerCreateTransaction_VersionedBaseURLAndSigningKey(t *testing.T) {
+	srv, recorded := startRecordingOverledgerServer(t, "/v2.1")
+	defer srv.Close()
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerClientID:       "client-id",
+		OverledgerClientSecret:   "client-secret",
+		OverledgerAuthURL:        srv.URL + "/oauth2/token",
+		OverledgerBaseURL:        srv.URL + "/v2.1",
+		OverledgerTxSigningKeyID: "configured-key",
+	})
+	_, err := client.CreateTransaction(&overledger.TransactionRequest{
+		NetworkID:   "ethereum-mainnet",
+		FromAddress:          "0x1234567890abcdef1234567890abcdef12345678",
+		ToAddress:            "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:               "1",
+		GasLimit:             "21000",
+		MaxFeePerGas:         "30000000000",
+		MaxPriorityFeePerGas: "1500000000",
+	})
+	require.NoError(t, err)
+
+	requests := recorded()
+	require.Len(t, requests, 4)
+	assert.Equal(t, "/v2.1/preparation/transaction", requests[1].Path)
+	assert.Equal(t, "/v2.1/execution/transaction", requests[3].Path)
+
+	prepare := requests[1].Body
+	assert.Equal(t, map[string]interface{}{"technology": "ethereum", "network": "ethereum mainnet"}, prepare["location"])
+	sign := requests[2].Body
+	assert.Equal(t, "configured-key", sign["keyId"])
+	native := sign["nativeData"].(map[string]interface{})
+	assert.Equal(t, float64(1), native["chainId"])
+	assert.Equal(t, "mainnet", native["chain"])
+	assert.Equal(t, "1000000000000000000", native["value"])
+}
+
+func TestOverledgerCreateTransaction_MapsEthereumTestnets(t *testing.T) {
+	srv, recorded := startRecordingOverledgerServer(t, "/v2")
+	defer srv.Close()
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerAuthURL: srv.URL + "/oauth2/token",
+		OverledgerBaseURL: srv.URL,
+	})
+	req := &overledger.TransactionRequest{
+		NetworkID:   "ethereum-holesky",
+		FromAddress:          "0x1234567890abcdef1234567890abcdef12345678",
+		ToAddress:            "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:               "0.5",
+		GasLimit:             "21000",
+		MaxFeePerGas:         "30000000000",
+		MaxPriorityFeePerGas: "1500000000",
+	}
+	_, err := client.CreateTransaction(req)
+	require.NoError(t, err)
+
+	requests := recorded()
+	require.Len(t, requests, 4)
+	assert.Equal(t, map[string]interface{}{"technology": "ethereum", "network": "ethereum holesky testnet"}, requests[1].Body["location"])
+	native := requests[2].Body["nativeData"].(map[string]interface{})
+	assert.Equal(t, float64(17000), native["chainId"])
+	assert.Equal(t, "testnet", native["chain"])
+
+	// Unknown Ethereum networks are never sent as mainnet
+	req.NetworkID = "ethereum-goerli"
+	_, err = client.CreateTransaction(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown ethereum network")
+	assert.Len(t, recorded(), 4)
+}
+
+func TestOverledgerCreateTransaction_NoNodeNeedsExplicitFees(t *testing.T) {
+	srv, recorded := startRecordingOverledgerServer(t, "/v2")
+	defer srv.Close()
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerAuthURL: srv.URL + "/oauth2/token",
+		OverledgerBaseURL: srv.URL,
+	})
+	_, err := client.CreateTransaction(&overledger.TransactionRequest{
+		NetworkID:    "ethereum-sepolia",
+		FromAddress:  "0x1234567890abcdef1234567890abcdef12345678",
+		ToAddress:    "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:       "0.5",
+		GasLimit:     "21000",
+		MaxFeePerGas: "30000000000",
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, overledger.ErrNoRPC)
+
+	// Nothing is signed or executed with made-up fees
+	for _, r := range recorded() {
+		assert.NotEqual(t, "/api/transaction-signing-sandbox", r.Path)
+		assert.NotEqual(t, "/v2/execution/transaction", r.Path)
+	}
+}
+
+func TestOverledgerCreateTransaction_HoleskyUsesItsOwnNode(t *testing.T) {
+	holesky, holeskyMethods := startFeeNode(t)
+	defer holesky.Close()
+	mainnet, mainnetMethods := startFeeNode(t)
+	defer mainnet.Close()
+	srv, _ := startRecordingOverledgerServer(t, "/v2")
+	defer srv.Close()
+
+	t.Setenv("HOLESKY_RPC_URL", holesky.URL)
+	t.Setenv("MAINNET_RPC_URL", mainnet.URL)
+	t.Setenv("OVERLEDGER_NONCE_FILE", filepath.Join(t.TempDir(), "nonces.json"))
+	cfg := config.LoadConfig()
+	cfg.OverledgerAuthURL = srv.URL + "/oauth2/token"
+	cfg.OverledgerBaseURL = srv.URL
+	cfg.OverledgerSigner = ""
+
+	_, err := overledger.NewClient(cfg).CreateTransaction(&overledger.TransactionRequest{
+		NetworkID:   "ethereum-holesky",
+		FromAddress: "0x1234567890abcdef1234567890abcdef12345678",
+		ToAddress:   "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:      "0.5",
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"eth_feeHistory", "eth_estimateGas", "eth_getTransactionCount"}, holeskyMethods())
+	assert.Empty(t, mainnetMethods())
+}
+
+func TestOverledgerCreateTransaction_PrepareFailureStops(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "t", "expires_in": 3600})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"code": "400", "message": "unknown location"}})
+	}))
+	defer srv.Close()
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerAuthURL: srv.URL + "/oauth2/token",
+		OverledgerBaseURL: srv.URL,
+	})
+	_, err := client.CreateTransaction(&overledger.TransactionRequest{NetworkID: "ethereum-sepolia", Amount: "1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown location")
+}
+
+func TestOverledgerCreateTransaction_ManagesNonces(t *testing.T) {
+	const from = "0x1234567890abcdef1234567890abcdef12345678"
+	var mu sync.Mutex
+	pending := uint64(3)
+	var signedNonces []float64
+	var executeErrors []string // served in order, then executions succeed
+
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := feeNodeResults[req.Method]
+		if req.Method == "eth_getTransactionCount" {
+			result = fmt.Sprintf("0x%x", pending)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+	defer rpc.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body map[string]interface{}
+		if r.URL.Path != "/oauth2/token" {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+		switch r.URL.Path {
+		case "/oauth2/token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "t", "expires_in": 3600})
+		case "/v2/preparation/transaction":
+			json.NewEncoder(w).Encode(map[string]interface{}{"requestId": "req-1"})
+		case "/api/transaction-signing-sandbox":
+			signedNonces = append(signedNonces, body["nativeData"].(map[string]interface{})["nonce"].(float64))
+			json.NewEncoder(w).Encode(map[string]interface{}{"signed": "0x02"})
+		case "/v2/execution/transaction":
+			if len(executeErrors) > 0 {
+				msg := executeErrors[0]
+				executeErrors = executeErrors[1:]
+				if msg == "nonce too low" {
+					// The sender's transactions landed elsewhere meanwhile
+					pending = 8
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"code": "400", "message": msg}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"executionTransactionSearchResponse": map[string]interface{}{"transactionId": "0xabc", "status": map[string]interface{}{"value": "PENDING"}},
+			})
+		}
+	}))
+	defer srv.Close()
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerAuthURL:   srv.URL + "/oauth2/token",
+		OverledgerBaseURL:   srv.URL,
+		OverledgerNonceFile: filepath.Join(t.TempDir(), "nonces.json"),
+		OverledgerRPCURLs:   map[string]string{"ethereum sepolia testnet": rpc.URL},
+	})
+	create := func() error {
+		_, err := client.CreateTransaction(&overledger.TransactionRequest{
+			NetworkID:   "ethereum-sepolia",
+			FromAddress: from,
+			ToAddress:   "0xabcdef1234567890abcdef1234567890abcdef12",
+			Amount:      "0.001",
+		})
+		return err
+	}
+	setErrors := func(errs ...string) {
+		mu.Lock()
+		defer mu.Unlock()
+		executeErrors = errs
+	}
+
+	// Seeded from the node, then counted up
+	require.NoError(t, create())
+	require.NoError(t, create())
+
+	// A failed execution releases its nonce for the next transaction
+	setErrors("insufficient funds")
+	require.Error(t, create())
+	require.NoError(t, create())
+
+	// "nonce too low" resyncs from the node and retries once
+	setErrors("nonce too low")
+	require.NoError(t, create())
+	setErrors("nonce too low", "nonce too low")
+	require.Error(t, create())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []float64{3, 4, 5, 5, 6, 8, 9, 8}, signedNonces)
+}
+
+// feeNodeResults are the fee market of a stand-in node: a next base fee of
+// 1 gwei, tips of 1, 2 and 3 gwei at the 10th, 50th and 90th percentiles and
+// a gas estimate of 21656
+var feeNodeResults = map[string]interface{}{
+	"eth_feeHistory": map[string]interface{}{
+		"baseFeePerGas": []string{"0x3b9aca00", "0x3b9aca00"},
+		"reward":        [][]string{{"0x3b9aca00", "0x77359400", "0xb2d05e00"}},
+	},
+	"eth_estimateGas": "0x5498",
+}
+
+// startFeeNode serves feeNodeResults and records the methods called
+func startFeeNode(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		methods = append(methods, req.Method)
+		mu.Unlock()
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": feeNodeResults[req.Method]}
+		if req.Method == "eth_getTransactionCount" {
+			resp["result"] = "0x0"
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), methods...)
+	}
+}
+
+func TestOverledgerCreateTransaction_SignsEstimatedFees(t *testing.T) {
+	node, _ := startFeeNode(t)
+	defer node.Close()
+	srv, recorded := startRecordingOverledgerServer(t, "/v2")
+	defer srv.Close()
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerAuthURL: srv.URL + "/oauth2/token",
+		OverledgerBaseURL: srv.URL,
+		OverledgerRPCURLs: map[string]string{"ethereum sepolia testnet": node.URL},
+	})
+	req := &overledger.TransactionRequest{
+		NetworkID:   "ethereum-sepolia",
+		FromAddress: "0x1234567890abcdef1234567890abcdef12345678",
+		ToAddress:   "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:      "0.001",
+		Urgency:     "fast",
+	}
+	resp, err := client.CreateTransaction(req)
+	require.NoError(t, err)
+	// 21656 gas at 3 * 1 gwei base fee + 3 gwei tip
+	assert.Equal(t, "0.000129936", resp.Fee)
+
+	var prepare, sign map[string]interface{}
+	for _, r := range recorded() {
+		switch r.Path {
+		case "/v2/preparation/transaction":
+			prepare = r.Body
+		case "/api/transaction-signing-sandbox":
+			sign = r.Body
+		}
+	}
+	require.NotNil(t, prepare)
+	require.NotNil(t, sign)
+	assert.Equal(t, "fast", prepare["urgency"])
+	native := sign["nativeData"].(map[string]interface{})
+	assert.Equal(t, "21656", native["gas"])
+	assert.Equal(t, "3000000000", native["maxPriorityFeePerGas"])
+	assert.Equal(t, "6000000000", native["maxFeePerGas"])
+	assert.Equal(t, map[string]interface{}{"amount": "0.000129936", "unit": "ETH"}, sign["dltFee"])
+
+	// Caller overrides win over the estimate
+	req.Urgency = ""
+	req.MaxFeePerGas = "7000000000"
+	_, err = client.CreateTransaction(req)
+	require.NoError(t, err)
+	requests := recorded()
+	native = requests[len(requests)-2].Body["nativeData"].(map[string]interface{})
+	assert.Equal(t, "2000000000", native["maxPriorityFeePerGas"])
+	assert.Equal(t, "7000000000", native["maxFeePerGas"])
+
+	req.Urgency = "asap"
+	_, err = client.CreateTransaction(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid urgency")
+}
+
+func TestOverledgerFeeEstimateEndpoint(t *testing.T) {
+	node, methods := startFeeNode(t)
+	defer node.Close()
+	gin.SetMode(gin.TestMode)
+	client := overledger.NewClient(&config.Config{
+		OverledgerRPCURLs: map[string]string{"ethereum sepolia testnet": node.URL},
+	})
+	srv := httptest.NewServer(api.SetupRouter(connector.NewService(nil, nil, client), &config.Config{Environment: "test"}, nil))
+	defer srv.Close()
+
+	status, body := postJSON(t, srv.URL, "/v1/overledger/fees/estimate", map[string]string{
+		"networkId":   "ethereum-sepolia",
+		"fromAddress": "0x1234567890abcdef1234567890abcdef12345678",
+		"toAddress":   "0xabcdef1234567890abcdef1234567890abcdef12",
+		"amount":      "0.001",
+	})
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "ETH", body["unit"])
+	assert.Equal(t, "1000000000", body["baseFeePerGas"])
+	assert.Equal(t, map[string]interface{}{"technology": "ethereum", "network": "ethereum sepolia testnet"}, body["location"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"urgency": "slow", "gasLimit": "21656", "maxPriorityFeePerGas": "1000000000", "maxFeePerGas": "2250000000", "maxFee": "0.000048726"},
+		map[string]interface{}{"urgency": "normal", "gasLimit": "21656", "maxPriorityFeePerGas": "2000000000", "maxFeePerGas": "4000000000", "maxFee": "0.000086624"},
+		map[string]interface{}{"urgency": "fast", "gasLimit": "21656", "maxPriorityFeePerGas": "3000000000", "maxFeePerGas": "6000000000", "maxFee": "0.000129936"},
+	}, body["estimates"])
+	assert.Equal(t, []string{"eth_feeHistory", "eth_estimateGas"}, methods())
+
+	// Networks without a node have nothing to estimate from
+	status, body = postJSON(t, srv.URL, "/v1/overledger/fees/estimate", map[string]string{
+		"networkId": "polygon-amoy",
+		"toAddress": "0xabcdef1234567890abcdef1234567890abcdef12",
+	})
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "overledger_fee_estimate_failed", body["error"])
+
+	status, _ = postJSON(t, srv.URL, "/v1/overledger/fees/estimate", map[string]string{"networkId": "ethereum-sepolia"})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestOverledgerCreateTransaction_LocalSigner(t *testing.T) {
+	node, _ := startFeeNode(t)
+	defer node.Close()
+	srv, recorded := startRecordingOverledgerServer(t, "/v2")
+	defer srv.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	id, err := uuid.NewRandom()
+	require.NoError(t, err)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Id: id, Address: from, PrivateKey: key}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "keystore.json")
+	require.NoError(t, os.WriteFile(keyFile, keyJSON, 0o600))
+
+	client := overledger.NewClient(&config.Config{
+		OverledgerAuthURL:          srv.URL + "/oauth2/token",
+		OverledgerBaseURL:          srv.URL,
+		OverledgerRPCURLs:          map[string]string{"ethereum sepolia testnet": node.URL},
+		OverledgerSigner:           "local",
+		OverledgerSignerKeyFile:    keyFile,
+		OverledgerSignerPassphrase: "secret",
+	})
+	resp, err := client.CreateTransaction(&overledger.TransactionRequest{
+		NetworkID:   "ethereum-sepolia",
+		FromAddress: from.Hex(),
+		ToAddress:   "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:      "0.001",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0xfeed", resp.TransactionID)
+
+	var paths []string
+	var signed string
+	for _, r := range recorded() {
+		paths = append(paths, r.Path)
+		if r.Path == "/v2/execution/transaction" {
+			signed, _ = r.Body["signed"].(string)
+			assert.Equal(t, "0f9d4b5e-5a6c-4f0e-9b55-3c9c1a0d7e21", r.Body["requestId"])
+		}
+	}
+	assert.Equal(t, []string{"/oauth2/token", "/v2/preparation/transaction", "/v2/execution/transaction"}, paths, "nothing goes to the signing sandbox")
+
+	// Overledger executes the EIP-1559 transaction signed by the sender's key
+	raw, err := hexutil.Decode(signed)
+	require.NoError(t, err)
+	tx := new(ethtypes.Transaction)
+	require.NoError(t, tx.UnmarshalBinary(raw))
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(big.NewInt(11155111)), tx)
+	require.NoError(t, err)
+	assert.Equal(t, from, sender)
+	assert.Equal(t, uint64(0), tx.Nonce())
+	assert.Equal(t, uint64(21656), tx.Gas())
+	assert.Equal(t, big.NewInt(2_000_000_000), tx.GasTipCap())
+	assert.Equal(t, big.NewInt(4_000_000_000), tx.GasFeeCap())
+	assert.Equal(t, big.NewInt(1_000_000_000_000_000), tx.Value())
+
+	// The local key only signs for its own address
+	_, err = client.CreateTransaction(&overledger.TransactionRequest{
+		NetworkID:   "ethereum-sepolia",
+		FromAddress: "0x1234567890abcdef1234567890abcdef12345678",
+		ToAddress:   "0xabcdef1234567890abcdef1234567890abcdef12",
+		Amount:      "0.001",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot sign for")
+}
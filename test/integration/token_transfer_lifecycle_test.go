@@ -1,12 +1,14 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	rotypes "github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -57,7 +59,7 @@ func startMockOverledgerServer(t *testing.T) *httptest.Server {
 	})
 
 	// Mock balance endpoint
-	router.GET("/v2.1/networks/:networkId/addresses/:address/balances", func(c *gin.Context) {
+	router.GET("/v2.1/networks/:networkId/accounts/:address/balance", func(c *gin.Context) {
 		networkID := c.Param("networkId")
 		address := c.Param("address")
 
@@ -90,27 +92,42 @@ func startMockOverledgerServer(t *testing.T) *httptest.Server {
 		})
 	})
 
-	// Mock transaction creation endpoint
-	router.POST("/v2.1/transactions", func(c *gin.Context) {
-		var req overledger.TransactionRequest
+	// Mock transaction preparation, signing and execution endpoints
+	router.POST("/v2.1/preparation/transaction", func(c *gin.Context) {
+		var req overledger.TransactionPrepareRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(400, gin.H{"error": "invalid request"})
 			return
 		}
+		c.JSON(200, overledger.TransactionPrepareResponse{
+			PreparationTransactionSearchResponse: overledger.PreparationTransactionSearchResponse{
+				RequestID: fmt.Sprintf("req_%d", time.Now().UnixNano()),
+			},
+		})
+	})
+	router.POST("/api/transaction-signing-sandbox", func(c *gin.Context) {
+		var req overledger.SandboxSigningRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		c.JSON(200, overledger.SandboxSigningResponse{SignedTransaction: "0x02f8"})
+	})
+	router.POST("/v2.1/execution/transaction", func(c *gin.Context) {
+		var req overledger.TransactionExecuteRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.Signed == "" || req.RequestID == "" {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
 
 		// Generate mock transaction hash
 		txHash := fmt.Sprintf("0x%064x", time.Now().UnixNano())
 
-		c.JSON(200, overledger.TransactionResponse{
-			TransactionID: fmt.Sprintf("tx_%d", time.Now().UnixNano()),
-			Hash:          txHash,
-			Status:        "pending",
-			NetworkID:     req.NetworkID,
-			FromAddress:   req.FromAddress,
-			ToAddress:     req.ToAddress,
-			Amount:        req.Amount,
-			Fee:           "21000",
-			Timestamp:     time.Now(),
+		c.JSON(200, overledger.TransactionExecuteResponse{
+			ExecutionTransactionSearchResponse: overledger.ExecutionTransactionSearchResponse{
+				TransactionID: txHash,
+				Status:        overledger.ExecutionStatus{Value: "pending", Code: "TXN1001", Description: "Transaction submitted"},
+			},
 		})
 	})
 
@@ -157,12 +174,22 @@ func setupTokenTransferSuite(t *testing.T) (*httptest.Server, *httptest.Server,
 	// Start mock Rosetta server for Mesh endpoints
 	rosettaServer := startMockRosettaServer(t)
 
+	// Fees are estimated from a node
+	feeNode, _ := startFeeNode(t)
+	t.Cleanup(feeNode.Close)
+
 	// Create Overledger client with mock server URLs
 	cfg := &config.Config{
 		OverledgerClientID:     "test_client_id",
 		OverledgerClientSecret: "test_client_secret",
 		OverledgerAuthURL:      overledgerServer.URL + "/oauth2/token",
 		OverledgerBaseURL:      overledgerServer.URL + "/v2.1",
+		OverledgerRPCURLs: map[string]string{
+			"ethereum mainnet":         feeNode.URL,
+			"ethereum sepolia testnet": feeNode.URL,
+			"ethereum holesky testnet": feeNode.URL,
+			"polygon amoy testnet":     feeNode.URL,
+		},
 	}
 
 	overledgerClient := overledger.NewClient(cfg)
@@ -185,15 +212,10 @@ func TestTokenTransferLifecycle(t *testing.T) {
 
 	t.Run("Complete Transaction Lifecycle", func(t *testing.T) {
 		// Step 1: Get initial balance via Mesh
-		networkID := map[string]interface{}{
-			"blockchain": "Ethereum",
-			"network":    "Sepolia",
-		}
-		accountID := map[string]interface{}{
-			"address": "0x1234567890abcdef1234567890abcdef12345678",
-		}
-
-		initialBalance, err := service.GetMeshNetworkBalance(networkID, accountID)
+		initialBalance, err := service.GetMeshNetworkBalance(context.Background(), &rotypes.AccountBalanceRequest{
+			NetworkIdentifier: &rotypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"},
+			AccountIdentifier: &rotypes.AccountIdentifier{Address: "0x1234567890abcdef1234567890abcdef12345678"},
+		})
 		require.NoError(t, err, "should get initial balance")
 		require.NotNil(t, initialBalance, "balance response should not be nil")
 		require.NotEmpty(t, initialBalance.Balances, "should have at least one balance")
@@ -232,7 +254,7 @@ func TestTokenTransferLifecycle(t *testing.T) {
 		require.NoError(t, err, "should get Overledger networks")
 		require.NotEmpty(t, overledgerNetworks.Networks, "should have networks")
 
-		meshNetworks, err := service.GetMeshNetworks()
+		meshNetworks, err := service.GetMeshNetworks(context.Background())
 		require.NoError(t, err, "should get Mesh networks")
 		require.NotEmpty(t, meshNetworks.Networks, "should have mesh networks")
 
@@ -296,27 +318,21 @@ func TestCrossChainTransactionMapping(t *testing.T) {
 	networks := []struct {
 		name         string
 		overledgerID string
-		meshNetwork  map[string]interface{}
+		meshNetwork  *rotypes.NetworkIdentifier
 		amount       string
 		tokenSymbol  string
 	}{
 		{
 			name:         "Ethereum Sepolia",
 			overledgerID: "ethereum-sepolia",
-			meshNetwork: map[string]interface{}{
-				"blockchain": "Ethereum",
-				"network":    "Sepolia",
-			},
+			meshNetwork:  &rotypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Sepolia"},
 			amount:      "1000000000000000000", // 1 ETH
 			tokenSymbol: "ETH",
 		},
 		{
 			name:         "Bitcoin Testnet",
 			overledgerID: "bitcoin-testnet",
-			meshNetwork: map[string]interface{}{
-				"blockchain": "Bitcoin",
-				"network":    "Testnet",
-			},
+			meshNetwork:  &rotypes.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Testnet"},
 			amount:      "100000000", // 1 BTC in satoshis
 			tokenSymbol: "BTC",
 		},
@@ -335,19 +351,26 @@ func TestCrossChainTransactionMapping(t *testing.T) {
 			require.NotEmpty(t, overledgerBalance.Balances, "should have balances")
 
 			// Get Mesh balance (this will use mock Rosetta server which returns ETH balances)
-			meshBalance, err := service.GetMeshNetworkBalance(network.meshNetwork, accountID)
+			meshBalance, err := service.GetMeshNetworkBalance(context.Background(), &rotypes.AccountBalanceRequest{
+				NetworkIdentifier: network.meshNetwork,
+				AccountIdentifier: &rotypes.AccountIdentifier{Address: accountID["address"].(string)},
+			})
 			require.NoError(t, err, "should get Mesh balance for "+network.name)
 			require.NotEmpty(t, meshBalance.Balances, "should have mesh balances")
 
 			t.Logf("Overledger %s balance: %+v", network.name, overledgerBalance.Balances[0])
 			t.Logf("Mesh %s balance: %+v", network.name, meshBalance.Balances[0])
 
-			// Create and verify transaction
+			// Create and verify transaction; Bitcoin has no fee node, so
+			// the request carries its own fees
 			txReq := &overledger.TransactionRequest{
-				NetworkID:   network.overledgerID,
-				FromAddress: "sender_" + network.tokenSymbol,
-				ToAddress:   "receiver_" + network.tokenSymbol,
-				Amount:      network.amount,
+				NetworkID:            network.overledgerID,
+				FromAddress:          "sender_" + network.tokenSymbol,
+				ToAddress:            "receiver_" + network.tokenSymbol,
+				Amount:               network.amount,
+				GasLimit:             "21000",
+				MaxFeePerGas:         "30000000000",
+				MaxPriorityFeePerGas: "1500000000",
 			}
 
 			txResp, err := service.CreateOverledgerTransaction(txReq)
@@ -445,13 +468,16 @@ func TestTokenTransferValidation(t *testing.T) {
 		{
 			name: "Empty network ID",
 			request: &overledger.TransactionRequest{
-				NetworkID:   "",
-				FromAddress: "0x1234567890abcdef1234567890abcdef12345678",
-				ToAddress:   "0xabcdef1234567890abcdef1234567890abcdef12",
-				Amount:      "1000000000000000000",
+				NetworkID:            "",
+				FromAddress:          "0x1234567890abcdef1234567890abcdef12345678",
+				ToAddress:            "0xabcdef1234567890abcdef1234567890abcdef12",
+				Amount:               "1000000000000000000",
+				GasLimit:             "21000",
+				MaxFeePerGas:         "30000000000",
+				MaxPriorityFeePerGas: "1500000000",
 			},
 			expectError: false, // Mock server will handle this
-			description: "Empty network ID handled by mock server",
+			description: "Empty network ID with explicit fees handled by mock server",
 		},
 		{
 			name: "Zero amount",
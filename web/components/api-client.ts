@@ -50,6 +50,22 @@ export interface OverledgerNetwork {
   status: string;
 }
 
+export type OverledgerUrgency = 'slow' | 'normal' | 'fast';
+
+export interface OverledgerFeeEstimate {
+  networkId: string;
+  location: { technology: string; network: string };
+  unit: string;
+  baseFeePerGas: string;
+  estimates: {
+    urgency: OverledgerUrgency;
+    gasLimit: string;
+    maxPriorityFeePerGas: string;
+    maxFeePerGas: string;
+    maxFee: string;
+  }[];
+}
+
 export interface CoinbaseExchangeRatesResponse {
   data: {
     currency: string;
@@ -177,12 +193,24 @@ const overledger = {
     tokenId?: string;
     gasLimit?: string;
     gasPrice?: string;
+    urgency?: OverledgerUrgency;
   }) =>
     apiCall('/v1/overledger/transactions', {
       method: 'POST',
       body: JSON.stringify(data),
     }),
 
+  estimateFees: (data: {
+    networkId: string;
+    fromAddress?: string;
+    toAddress: string;
+    amount?: string;
+  }) =>
+    apiCall<OverledgerFeeEstimate>('/v1/overledger/fees/estimate', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+
   getTransactionStatus: (networkId: string, txHash: string) =>
     apiCall(`/v1/overledger/networks/${networkId}/transactions/${txHash}/status`),
 